@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/kernel-labs-ai/awt/internal/commands"
+	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -16,11 +17,21 @@ var (
 )
 
 func main() {
+	// started is set once flags and arguments have been validated and the command runs
+	started := false
+
 	rootCmd := &cobra.Command{
 		Use:   "awt",
 		Short: "AWT - Agent WorkTrees",
 		Long:  "A CLI tool that enables multiple AI agents to safely create, use, and hand off Git worktrees.",
+		// Errors are printed by errors.Handle, which also picks the exit code
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			started = true
+		},
 	}
+	rootCmd.PersistentFlags().Bool("json", false, "print errors as JSON ({error, code, hint}) on stderr")
 
 	versionCmd := &cobra.Command{
 		Use:   "version",
@@ -36,12 +47,33 @@ func main() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(commands.NewInitCmd())
 	rootCmd.AddCommand(commands.NewTaskCmd())
+	rootCmd.AddCommand(commands.NewGroupCmd())
+	rootCmd.AddCommand(commands.NewCompareCmd())
 	rootCmd.AddCommand(commands.NewListCmd())
 	rootCmd.AddCommand(commands.NewPruneCmd())
+	rootCmd.AddCommand(commands.NewLockCmd())
+	rootCmd.AddCommand(commands.NewStoreCmd())
+	rootCmd.AddCommand(commands.NewDoctorCmd())
+	rootCmd.AddCommand(commands.NewUndoCmd())
 	rootCmd.AddCommand(commands.NewConfigCmd())
 	rootCmd.AddCommand(commands.NewAddDocsCmd())
 
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
+		// Commands with their own --json flag shadow the root flag, so ask the command that ran
+		useJSON, _ := cmd.Flags().GetBool("json")
+		if !started {
+			// Flags may not have been parsed yet, so look for --json directly
+			for _, arg := range os.Args[1:] {
+				if arg == "--json" || arg == "--json=true" {
+					useJSON = true
+				}
+			}
+			// Flag, argument and unknown command errors happen before the command runs
+			if errors.ExitCodeOf(err) == errors.ExitGeneric {
+				err = errors.Usage(err, cmd.CommandPath())
+			}
+		}
+		errors.Handle(err, useJSON)
 	}
 }
@@ -0,0 +1,292 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/lock"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// AbandonOptions contains options for the abandon command
+type AbandonOptions struct {
+	RepoPath     string
+	TaskID       string
+	Branch       string
+	Reason       string
+	DeleteBranch bool
+	DeleteRemote bool
+	Force        bool
+	ForceRemove  bool
+	OutputJSON   bool
+}
+
+// AbandonResult represents the output of the abandon command
+type AbandonResult struct {
+	TaskID              string `json:"task_id"`
+	Branch              string `json:"branch"`
+	Reason              string `json:"reason,omitempty"`
+	WorktreeRemoved     bool   `json:"worktree_removed"`
+	BranchDeleted       bool   `json:"branch_deleted"`
+	RemoteBranchDeleted bool   `json:"remote_branch_deleted"`
+}
+
+// NewTaskAbandonCmd creates the task abandon command
+func NewTaskAbandonCmd() *cobra.Command {
+	opts := &AbandonOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "abandon [task-id]",
+		Short: "Abandon a task without merging",
+		Long: `Abandon a task, removing its worktree but keeping its metadata.
+
+The task can be specified by:
+  1. Providing the task ID as an argument
+  2. Using --branch flag
+  3. Inferring from current worktree (if in a worktree)
+
+This command performs the following steps:
+  1. Detaches HEAD in the task's worktree
+  2. Removes the worktree (refuses if it has uncommitted changes, unless --force)
+  3. Deletes the local branch (only with --delete-branch)
+  4. Deletes the remote branch (only with --delete-remote)
+  5. Updates task state to ABANDONED, recording the reason
+
+The branch is kept by default so the task can be restored with 'awt task reopen'.
+
+Example:
+  awt task abandon 20250110-120000-abc123 --reason="wrong approach"
+  awt task abandon 20250110-120000-abc123 --delete-branch --delete-remote`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.TaskID = args[0]
+			}
+			return runTaskAbandon(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.Branch, "branch", "", "branch name")
+	cmd.Flags().StringVar(&opts.Reason, "reason", "", "why the task is being abandoned")
+	cmd.Flags().BoolVar(&opts.DeleteBranch, "delete-branch", false, "delete the local task branch")
+	cmd.Flags().BoolVar(&opts.DeleteRemote, "delete-remote", false, "delete the task branch on the remote")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "discard uncommitted changes in the worktree")
+	cmd.Flags().BoolVar(&opts.ForceRemove, "force-remove", false, "force remove worktree even if CWD is inside")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runTaskAbandon(opts *AbandonOptions) error {
+	result, err := cliClient(opts.OutputJSON).Abandon(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("\nTask abandoned.\n")
+		fmt.Printf("  Task: %s\n", result.TaskID)
+		fmt.Printf("  Branch: %s", result.Branch)
+		if result.BranchDeleted {
+			fmt.Printf(" (deleted)")
+		}
+		fmt.Println()
+		if result.Reason != "" {
+			fmt.Printf("  Reason: %s\n", result.Reason)
+		}
+		if result.WorktreeRemoved {
+			fmt.Printf("  Worktree: removed\n")
+		}
+		if result.RemoteBranchDeleted {
+			fmt.Printf("  Remote branch: deleted\n")
+		}
+		if !result.BranchDeleted {
+			fmt.Printf("\nUse 'awt task reopen %s' to resume this task.\n", result.TaskID)
+		}
+	}
+
+	return nil
+}
+
+// Abandon removes the task's worktree, optionally deletes its branches, and moves it to ABANDONED
+func (c *Client) Abandon(ctx context.Context, opts *AbandonOptions) (*AbandonResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	// Determine task ID
+	taskID, err := resolveTaskID(ctx, r, store, opts.TaskID, opts.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load task
+	t, err := store.Load(taskID)
+	if err != nil {
+		return nil, errors.InvalidTaskID(taskID)
+	}
+
+	// Hold the task lock so concurrent commands cannot mutate the task
+	taskLock, err := acquireTaskLock(ctx, r, cfg, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
+
+	// Reload the task now that it is locked, in case it changed while waiting
+	if fresh, err := store.Load(t.ID); err == nil {
+		*t = *fresh
+	}
+
+	// Acquire global lock for worktree removal
+	lm := newLockManager(r, cfg)
+	globalLock, err := lm.AcquireGlobal(ctx)
+	if err != nil {
+		return nil, errors.LockTimeout("global")
+	}
+	defer func() {
+		_ = globalLock.Release()
+	}()
+
+	return c.abandonTask(ctx, r, cfg, store, taskLock, t, opts, "task abandon")
+}
+
+// abandonTask detaches and removes the task's worktree, optionally deletes its branches,
+// and moves the task to ABANDONED, recording command in its history. The caller must hold
+// taskLock, the task's lock, and then the global lock.
+func (c *Client) abandonTask(ctx context.Context, r *repo.Repo, cfg *config.Config, store task.Store, taskLock *lock.Lock, t *task.Task, opts *AbandonOptions, command string) (*AbandonResult, error) {
+	// Check the transition up front so nothing is removed for a task that cannot be abandoned
+	if !task.CanTransition(t.State, task.StateAbandoned) {
+		return nil, errors.InvalidTransition(t.ID, string(t.State), string(task.StateAbandoned))
+	}
+
+	// Record the pre-operation state so the command can be undone
+	if err := recordOperation(ctx, r, command, t, false); err != nil {
+		return nil, err
+	}
+
+	result := &AbandonResult{
+		TaskID: t.ID,
+		Branch: t.Branch,
+		Reason: opts.Reason,
+	}
+
+	branchName := strings.TrimPrefix(t.Branch, "refs/heads/")
+	repoGit := git.New(r.WorkTreeRoot, cfg.VerboseGit).WithTimeouts(gitTimeouts(cfg))
+
+	// Detach and remove the worktree if it still exists
+	if t.WorktreePath != "" {
+		if _, err := os.Stat(t.WorktreePath); err == nil {
+			wtGit := git.New(t.WorktreePath, cfg.VerboseGit)
+
+			dirty, err := wtGit.IsDirty(ctx)
+			if err == nil && dirty && !opts.Force {
+				return nil, fmt.Errorf("worktree has uncommitted changes: %s\nCommit them first, or use --force to discard them", t.WorktreePath)
+			}
+
+			// Refuse to remove the worktree from under the current directory
+			if cwd, err := os.Getwd(); err == nil {
+				wtPathAbs, _ := filepath.Abs(t.WorktreePath)
+				cwdAbs, _ := filepath.Abs(cwd)
+				rel, err := filepath.Rel(wtPathAbs, cwdAbs)
+				isInside := err == nil && !filepath.IsAbs(rel) && rel != ".." && !hasParentDir(rel)
+
+				if isInside && !opts.ForceRemove {
+					return nil, fmt.Errorf("current directory is inside the worktree: %s\nUse --force-remove to remove anyway, or cd out of the worktree", t.WorktreePath)
+				} else if isInside {
+					if err := os.Chdir(r.WorkTreeRoot); err != nil {
+						return nil, fmt.Errorf("failed to change directory: %w", err)
+					}
+				}
+			}
+
+			c.progressf("Detaching HEAD in worktree...\n")
+			detachResult, err := wtGit.Switch(ctx, "HEAD", true)
+			if err != nil || detachResult.ExitCode != 0 {
+				return nil, errors.DetachFailed(t.WorktreePath, err)
+			}
+
+			c.progressf("Removing worktree...\n")
+			removeResult, err := repoGit.WorktreeRemove(ctx, t.WorktreePath, true)
+			if err != nil || removeResult.ExitCode != 0 {
+				return nil, errors.RemoveFailed(t.WorktreePath, err)
+			}
+			result.WorktreeRemoved = true
+		}
+	}
+
+	// Delete the local branch if requested
+	if opts.DeleteBranch {
+		exists, err := repoGit.BranchExists(ctx, branchName)
+		if err == nil && exists {
+			deleteResult, err := repoGit.DeleteBranch(ctx, branchName, true)
+			if err != nil || deleteResult.ExitCode != 0 {
+				return nil, fmt.Errorf("failed to delete branch %s: %s", branchName, deleteResult.Stderr)
+			}
+			result.BranchDeleted = true
+		}
+	}
+
+	// Delete the remote branch if requested (failures are not fatal - might be offline or never pushed)
+	if opts.DeleteRemote {
+		deleteResult, err := repoGit.DeleteRemoteBranch(ctx, cfg.RemoteName, branchName)
+		if err != nil || deleteResult.ExitCode != 0 {
+			stderr := ""
+			if deleteResult != nil {
+				stderr = deleteResult.Stderr
+			}
+			c.progressf("Warning: failed to delete remote branch: %s\n", stderr)
+		} else {
+			result.RemoteBranchDeleted = true
+		}
+	}
+
+	// Update task state; the worktree path is cleared so prune keeps the metadata
+	updated, err := task.Update(store, taskLock, t.ID, func(t *task.Task) error {
+		if err := t.TransitionWithReason(task.StateAbandoned, currentActor(), command, opts.Reason); err != nil {
+			return err
+		}
+		t.WorktreePath = ""
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task metadata: %w", err)
+	}
+	*t = *updated
+	recordEvent(r, t.ID, task.Event{Type: task.EventAbandon, Message: opts.Reason})
+
+	return result, nil
+}
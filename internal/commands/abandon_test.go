@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestRunTaskAbandonAndReopen(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Task to abandon",
+		Base:         "HEAD",
+		ID:           "abandon-me",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	started, err := store.Load("abandon-me")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	worktreePath := started.WorktreePath
+
+	// Abandon keeps the branch and metadata but removes the worktree
+	abandonOpts := &AbandonOptions{RepoPath: repoPath, TaskID: "abandon-me", Reason: "wrong approach", OutputJSON: true}
+	if err := runTaskAbandon(abandonOpts); err != nil {
+		t.Fatalf("runTaskAbandon() failed: %v", err)
+	}
+
+	abandoned, err := store.Load("abandon-me")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if abandoned.State != task.StateAbandoned {
+		t.Errorf("state = %s, want %s", abandoned.State, task.StateAbandoned)
+	}
+	if last := abandoned.History[len(abandoned.History)-1]; last.Reason != "wrong approach" {
+		t.Errorf("reason = %q, want %q", last.Reason, "wrong approach")
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Error("worktree was not removed")
+	}
+	if err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", started.Branch).Run(); err != nil {
+		t.Error("branch was deleted but should have been kept")
+	}
+
+	// Reopen recreates the worktree from the kept branch
+	if err := runTaskReopen(&ReopenOptions{RepoPath: repoPath, TaskID: "abandon-me", OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskReopen() failed: %v", err)
+	}
+
+	reopened, err := store.Load("abandon-me")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if reopened.State != task.StateActive {
+		t.Errorf("state = %s, want %s", reopened.State, task.StateActive)
+	}
+	if _, err := os.Stat(reopened.WorktreePath); err != nil {
+		t.Errorf("worktree was not recreated at %s", reopened.WorktreePath)
+	}
+}
+
+func TestRunTaskAbandonRefusesDirtyWorktree(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Dirty task",
+		Base:         "HEAD",
+		ID:           "dirty-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tk, err := store.Load("dirty-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	_ = os.WriteFile(filepath.Join(tk.WorktreePath, "wip.txt"), []byte("wip\n"), 0644)
+
+	if err := runTaskAbandon(&AbandonOptions{RepoPath: repoPath, TaskID: "dirty-task", OutputJSON: true}); err == nil {
+		t.Error("expected error abandoning a dirty worktree without --force")
+	}
+
+	if err := runTaskAbandon(&AbandonOptions{RepoPath: repoPath, TaskID: "dirty-task", Force: true, OutputJSON: true}); err != nil {
+		t.Errorf("runTaskAbandon() with --force failed: %v", err)
+	}
+}
+
+func TestRunTaskReopenWorktreeAddFailed(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Task to reopen",
+		Base:         "HEAD",
+		ID:           "reopen-me",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	started, err := store.Load("reopen-me")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if err := runTaskAbandon(&AbandonOptions{RepoPath: repoPath, TaskID: "reopen-me", OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskAbandon() failed: %v", err)
+	}
+
+	// Something else now occupies the worktree path
+	if err := os.MkdirAll(started.WorktreePath, 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(started.WorktreePath, "file.txt"), []byte("in the way\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	err = runTaskReopen(&ReopenOptions{RepoPath: repoPath, TaskID: "reopen-me", OutputJSON: true})
+	if errors.ExitCodeOf(err) != errors.ExitWorktreeAddFailed {
+		t.Errorf("runTaskReopen() error = %v, want exit code %d", err, errors.ExitWorktreeAddFailed)
+	}
+	reopened, _ := store.Load("reopen-me")
+	if reopened == nil || reopened.State != task.StateAbandoned {
+		t.Errorf("task after failed reopen = %+v, want ABANDONED", reopened)
+	}
+}
+
+func TestRunTaskReopenSwitchesKeptWorktreeToBranch(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Task to hand off",
+		Base:         "HEAD",
+		ID:           "kept-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	started, err := store.Load("kept-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	_ = os.WriteFile(filepath.Join(started.WorktreePath, "work.txt"), []byte("work\n"), 0644)
+	gitOutput(t, started.WorktreePath, "add", ".")
+	gitOutput(t, started.WorktreePath, "commit", "-m", "work")
+
+	// Handoff with --keep-worktree leaves the worktree on a detached HEAD
+	handoffOpts := &HandoffOptions{RepoPath: repoPath, TaskID: "kept-task", NoPush: true, NoPR: true, KeepWorktree: true, OutputJSON: true}
+	if err := runTaskHandoff(handoffOpts); err != nil {
+		t.Fatalf("runTaskHandoff() failed: %v", err)
+	}
+
+	if err := runTaskReopen(&ReopenOptions{RepoPath: repoPath, TaskID: "kept-task", OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskReopen() failed: %v", err)
+	}
+
+	reopened, err := store.Load("kept-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if reopened.State != task.StateActive {
+		t.Errorf("state = %s, want %s", reopened.State, task.StateActive)
+	}
+	if got := gitOutput(t, reopened.WorktreePath, "branch", "--show-current"); got != reopened.Branch {
+		t.Errorf("worktree is on %q, want %q", got, reopened.Branch)
+	}
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -69,13 +70,43 @@ Example:
 }
 
 func runTaskAdopt(opts *AdoptOptions) error {
+	result, err := cliClient(opts.OutputJSON).Adopt(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Adopted branch successfully!\n")
+		fmt.Printf("  Task ID: %s\n", result.TaskID)
+		fmt.Printf("  Branch: %s\n", result.Branch)
+		fmt.Printf("  Base: %s\n", result.Base)
+		fmt.Printf("  Agent: %s\n", result.Agent)
+		fmt.Printf("  Title: %s\n", result.Title)
+		fmt.Printf("\nUse 'awt task checkout %s' to create a worktree for this task.\n", result.TaskID)
+	}
+
+	return nil
+}
+
+// Adopt creates an ACTIVE task for an existing branch
+func (c *Client) Adopt(ctx context.Context, opts *AdoptOptions) (*AdoptResult, error) {
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
 	}
 
-	store := task.NewTaskStore(r.GitCommonDir)
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
 
 	// Create Git wrapper
 	g := git.New(r.WorkTreeRoot, false)
@@ -86,12 +117,12 @@ func runTaskAdopt(opts *AdoptOptions) error {
 		branch = "refs/heads/" + branch
 	}
 
-	exists, err := g.BranchExists(strings.TrimPrefix(branch, "refs/heads/"))
+	exists, err := g.BranchExists(ctx, strings.TrimPrefix(branch, "refs/heads/"))
 	if err != nil {
-		return fmt.Errorf("failed to check branch existence: %w", err)
+		return nil, fmt.Errorf("failed to check branch existence: %w", err)
 	}
 	if !exists {
-		return fmt.Errorf("branch does not exist: %s", opts.Branch)
+		return nil, fmt.Errorf("branch does not exist: %s", opts.Branch)
 	}
 
 	// Detect base branch if not provided
@@ -101,14 +132,14 @@ func runTaskAdopt(opts *AdoptOptions) error {
 		// Common base branches to try
 		baseCandidates := []string{"origin/main", "origin/master", "main", "master", "origin/develop", "develop"}
 		for _, candidate := range baseCandidates {
-			candidateExists, err := g.BranchExists(strings.TrimPrefix(candidate, "origin/"))
+			candidateExists, err := g.BranchExists(ctx, strings.TrimPrefix(candidate, "origin/"))
 			if err == nil && candidateExists {
 				base = candidate
 				break
 			}
 		}
 		if base == "" {
-			return fmt.Errorf("could not detect base branch, please specify with --base")
+			return nil, fmt.Errorf("could not detect base branch, please specify with --base")
 		}
 	}
 
@@ -117,10 +148,10 @@ func runTaskAdopt(opts *AdoptOptions) error {
 	if taskID == "" {
 		taskID, err = idgen.GenerateTaskID()
 		if err != nil {
-			return fmt.Errorf("failed to generate task ID: %w", err)
+			return nil, fmt.Errorf("failed to generate task ID: %w", err)
 		}
 	} else if !idgen.ValidateTaskID(taskID) {
-		return errors.InvalidTaskID(taskID)
+		return nil, errors.InvalidTaskID(taskID)
 	}
 
 	// Use branch name as title if not provided
@@ -146,41 +177,32 @@ func runTaskAdopt(opts *AdoptOptions) error {
 		Branch:       branch,
 		Base:         base,
 		CreatedAt:    time.Now(),
-		State:        task.StateActive,
+		State:        task.StateNew,
 		WorktreePath: "", // Empty until checkout
 	}
+	if err := t.TransitionTo(task.StateActive, currentActor(), "task adopt"); err != nil {
+		return nil, err
+	}
 
 	// Get last commit if branch exists
-	commitSHA, err := g.RevParse(strings.TrimPrefix(branch, "refs/heads/"))
+	commitSHA, err := g.RevParse(ctx, strings.TrimPrefix(branch, "refs/heads/"))
 	if err == nil && commitSHA != "" {
 		t.LastCommit = commitSHA
 	}
 
 	// Save task
 	if err := store.Save(t); err != nil {
-		return fmt.Errorf("failed to save task: %w", err)
+		return nil, fmt.Errorf("failed to save task: %w", err)
 	}
-
-	// Output result
-	if opts.OutputJSON {
-		output := AdoptResult{
-			TaskID: taskID,
-			Branch: branch,
-			Base:   base,
-			Agent:  opts.Agent,
-			Title:  title,
-		}
-		data, _ := json.MarshalIndent(output, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		fmt.Printf("Adopted branch successfully!\n")
-		fmt.Printf("  Task ID: %s\n", taskID)
-		fmt.Printf("  Branch: %s\n", branch)
-		fmt.Printf("  Base: %s\n", base)
-		fmt.Printf("  Agent: %s\n", opts.Agent)
-		fmt.Printf("  Title: %s\n", title)
-		fmt.Printf("\nUse 'awt task checkout %s' to create a worktree for this task.\n", taskID)
+	recordEvent(r, taskID, task.Event{Type: task.EventStart, SHA: t.LastCommit, Message: "adopted " + branch})
+
+	result := AdoptResult{
+		TaskID: taskID,
+		Branch: branch,
+		Base:   base,
+		Agent:  opts.Agent,
+		Title:  title,
 	}
 
-	return nil
+	return &result, nil
 }
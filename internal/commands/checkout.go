@@ -6,11 +6,10 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/kernel-labs-ai/awt/internal/git"
-	"github.com/kernel-labs-ai/awt/internal/lock"
 	"github.com/kernel-labs-ai/awt/internal/repo"
-	"github.com/kernel-labs-ai/awt/internal/task"
 	"github.com/spf13/cobra"
 )
 
@@ -70,33 +69,70 @@ Example:
 }
 
 func runTaskCheckout(opts *CheckoutOptions) error {
+	result, err := cliClient(opts.OutputJSON).Checkout(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Checked out task successfully!\n")
+		fmt.Printf("  Task: %s\n", result.TaskID)
+		fmt.Printf("  Branch: %s\n", result.Branch)
+		fmt.Printf("  Worktree: %s\n", result.WorktreePath)
+		if opts.Submodules {
+			fmt.Printf("  Submodules: initialized\n")
+		}
+	}
+
+	return nil
+}
+
+// Checkout creates a worktree for an existing task's branch
+func (c *Client) Checkout(ctx context.Context, opts *CheckoutOptions) (*CheckoutResult, error) {
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
 	}
 
-	store := task.NewTaskStore(r.GitCommonDir)
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
 
 	// Determine task ID
 	taskID := opts.TaskID
 
 	if taskID == "" && opts.Branch != "" {
-		// Extract task ID from branch name
-		taskID = extractTaskIDFromBranch(opts.Branch)
+		// Look up the task that owns the branch
+		taskID = taskIDForBranch(store, opts.Branch)
 		if taskID == "" {
-			return fmt.Errorf("could not extract task ID from branch: %s", opts.Branch)
+			return nil, errors.TaskNotFoundForBranch(opts.Branch)
 		}
 	}
 
 	if taskID == "" {
-		return fmt.Errorf("task ID is required\nProvide task ID as argument or use --branch flag")
+		return nil, errors.TaskIDRequired(nil)
 	}
 
 	// Load task
 	t, err := store.Load(taskID)
 	if err != nil {
-		return errors.InvalidTaskID(taskID)
+		return nil, errors.InvalidTaskID(taskID)
 	}
 
 	// Determine worktree path
@@ -109,11 +145,10 @@ func runTaskCheckout(opts *CheckoutOptions) error {
 	}
 
 	// Acquire global lock for worktree creation
-	lm := lock.NewLockManager(r.GitCommonDir)
-	ctx := context.Background()
+	lm := newLockManager(r, cfg)
 	globalLock, err := lm.AcquireGlobal(ctx)
 	if err != nil {
-		return errors.LockTimeout("global")
+		return nil, errors.LockTimeout("global")
 	}
 	defer func() {
 		_ = globalLock.Release()
@@ -123,16 +158,16 @@ func runTaskCheckout(opts *CheckoutOptions) error {
 	g := git.New(r.WorkTreeRoot, false)
 
 	// Check if worktree already exists at path
-	worktrees, err := g.WorktreeList()
+	worktrees, err := g.WorktreeList(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list worktrees: %w", err)
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
 	for _, wt := range worktrees {
 		wtAbs, _ := filepath.Abs(wt.Path)
 		pathAbs, _ := filepath.Abs(worktreePath)
 		if wtAbs == pathAbs {
-			return errors.WorktreeExists(worktreePath)
+			return nil, errors.WorktreeExists(worktreePath)
 		}
 	}
 
@@ -142,38 +177,25 @@ func runTaskCheckout(opts *CheckoutOptions) error {
 		branchName = branchName[11:]
 	}
 
-	result, err := g.WorktreeAddExisting(worktreePath, branchName)
-	if err != nil || result.ExitCode != 0 {
-		return fmt.Errorf("failed to create worktree: %s", result.Stderr)
+	addResult, err := g.WorktreeAddExisting(ctx, worktreePath, branchName)
+	if err != nil || addResult.ExitCode != 0 {
+		return nil, errors.WorktreeAddFailed(worktreePath, addResult.Stderr)
 	}
 
 	// Initialize/update submodules if requested
 	if opts.Submodules {
 		wtGit := git.New(worktreePath, false)
-		subResult, err := wtGit.SubmoduleUpdate()
+		subResult, err := wtGit.SubmoduleUpdate(ctx)
 		if err != nil || subResult.ExitCode != 0 {
-			return fmt.Errorf("failed to update submodules: %s", subResult.Stderr)
+			return nil, errors.SubmoduleFailed(worktreePath, subResult.Stderr)
 		}
 	}
 
-	// Output result
-	if opts.OutputJSON {
-		output := CheckoutResult{
-			TaskID:       taskID,
-			Branch:       t.Branch,
-			WorktreePath: worktreePath,
-		}
-		data, _ := json.MarshalIndent(output, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		fmt.Printf("Checked out task successfully!\n")
-		fmt.Printf("  Task: %s\n", taskID)
-		fmt.Printf("  Branch: %s\n", t.Branch)
-		fmt.Printf("  Worktree: %s\n", worktreePath)
-		if opts.Submodules {
-			fmt.Printf("  Submodules: initialized\n")
-		}
+	result := CheckoutResult{
+		TaskID:       taskID,
+		Branch:       t.Branch,
+		WorktreePath: worktreePath,
 	}
 
-	return nil
+	return &result, nil
 }
@@ -0,0 +1,501 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// checkpointRefPrefix is where checkpoints are stored: refs/awt/checkpoints/<task-id>/<n>
+const checkpointRefPrefix = "refs/awt/checkpoints/"
+
+// checkpointSaveRetries is how many times a checkpoint number is retried if another process took it
+const checkpointSaveRetries = 5
+
+// CheckpointOptions contains options for the checkpoint command
+type CheckpointOptions struct {
+	RepoPath   string
+	TaskID     string
+	Branch     string
+	Message    string
+	OutputJSON bool
+}
+
+// Checkpoint describes a saved worktree snapshot
+type Checkpoint struct {
+	TaskID    string `json:"task_id"`
+	Number    int    `json:"number"`
+	Ref       string `json:"ref"`
+	Commit    string `json:"commit"`
+	Message   string `json:"message"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// CheckpointsOptions contains options for the checkpoints command
+type CheckpointsOptions struct {
+	RepoPath   string
+	TaskID     string
+	Branch     string
+	OutputJSON bool
+}
+
+// CheckpointsResult represents the output of the checkpoints command
+type CheckpointsResult struct {
+	TaskID      string        `json:"task_id"`
+	Checkpoints []*Checkpoint `json:"checkpoints"`
+}
+
+// RestoreOptions contains options for the restore command
+type RestoreOptions struct {
+	RepoPath   string
+	TaskID     string
+	Number     int
+	OutputJSON bool
+}
+
+// RestoreResult represents the output of the restore command
+type RestoreResult struct {
+	TaskID   string      `json:"task_id"`
+	Restored *Checkpoint `json:"restored"`
+	// Saved is the checkpoint of the worktree as it was before the restore
+	Saved *Checkpoint `json:"saved"`
+}
+
+// NewTaskCheckpointCmd creates the task checkpoint command
+func NewTaskCheckpointCmd() *cobra.Command {
+	opts := &CheckpointOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "checkpoint [task-id]",
+		Short: "Save a snapshot of the worktree without committing",
+		Long: `Save the full worktree, including untracked files, as a checkpoint.
+
+The snapshot is stored as a commit under refs/awt/checkpoints/<task-id>/<n>.
+The task branch, HEAD and index are not touched. Ignored files are not saved.
+Use 'awt task restore' to roll the worktree back to a checkpoint.
+
+Example:
+  awt task checkpoint 20250110-120000-abc123 -m "before refactor"
+  awt task checkpoint  # infer from current directory`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.TaskID = args[0]
+			}
+			return runTaskCheckpoint(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.Branch, "branch", "", "branch name")
+	cmd.Flags().StringVarP(&opts.Message, "message", "m", "", "checkpoint message")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+// NewTaskCheckpointsCmd creates the task checkpoints command
+func NewTaskCheckpointsCmd() *cobra.Command {
+	opts := &CheckpointsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "checkpoints [task-id]",
+		Short: "List a task's checkpoints",
+		Long: `List the worktree checkpoints saved for a task, oldest first.
+
+Example:
+  awt task checkpoints 20250110-120000-abc123
+  awt task checkpoints --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.TaskID = args[0]
+			}
+			return runTaskCheckpoints(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.Branch, "branch", "", "branch name")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+// NewTaskRestoreCmd creates the task restore command
+func NewTaskRestoreCmd() *cobra.Command {
+	opts := &RestoreOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "restore <task-id> <n>",
+		Short: "Roll the worktree back to a checkpoint",
+		Long: `Make the task's worktree match checkpoint <n>.
+
+Changed files are overwritten and files that are not in the checkpoint are
+removed (ignored files are left alone). HEAD and the branch do not move, and
+the index is reset to HEAD, so the restored changes show up as unstaged.
+The current worktree is saved as a new checkpoint first, so a restore can
+itself be rolled back.
+
+Example:
+  awt task restore 20250110-120000-abc123 2`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.TaskID = args[0]
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return errors.Usage(fmt.Errorf("invalid checkpoint number: %s", args[1]), cmd.CommandPath())
+			}
+			opts.Number = n
+			return runTaskRestore(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runTaskCheckpoint(opts *CheckpointOptions) error {
+	cp, err := cliClient(opts.OutputJSON).Checkpoint(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(cp, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Saved checkpoint %d for task %s\n", cp.Number, cp.TaskID)
+		fmt.Printf("  Ref: %s\n", cp.Ref)
+		fmt.Printf("  Commit: %s\n", cp.Commit)
+	}
+
+	return nil
+}
+
+// Checkpoint saves a snapshot of the task's worktree, including untracked files
+func (c *Client) Checkpoint(ctx context.Context, opts *CheckpointOptions) (*Checkpoint, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	taskID, err := resolveTaskID(ctx, r, store, opts.TaskID, opts.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Hold the task lock so the worktree does not change underneath the snapshot
+	taskLock, err := acquireTaskLock(ctx, r, cfg, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
+
+	t, err := loadCheckpointTask(store, taskID, "checkpoint")
+	if err != nil {
+		return nil, err
+	}
+
+	return saveCheckpoint(ctx, r, cfg, t, opts.Message)
+}
+
+func runTaskCheckpoints(opts *CheckpointsOptions) error {
+	result, err := cliClient(opts.OutputJSON).Checkpoints(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(result.Checkpoints) == 0 {
+		fmt.Printf("No checkpoints for task %s\n", result.TaskID)
+		return nil
+	}
+
+	fmt.Printf("%-4s %-20s %-12s %s\n", "N", "CREATED", "COMMIT", "MESSAGE")
+	fmt.Println(strings.Repeat("-", 80))
+	for _, cp := range result.Checkpoints {
+		fmt.Printf("%-4d %-20s %-12s %s\n", cp.Number, cp.CreatedAt, cp.Commit[:min(12, len(cp.Commit))], cp.Message)
+	}
+
+	return nil
+}
+
+// Checkpoints lists the task's checkpoints, oldest first
+func (c *Client) Checkpoints(ctx context.Context, opts *CheckpointsOptions) (*CheckpointsResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	taskID, err := resolveTaskID(ctx, r, store, opts.TaskID, opts.Branch)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.Load(taskID); err != nil {
+		return nil, errors.InvalidTaskID(taskID)
+	}
+
+	checkpoints, err := listCheckpoints(ctx, git.New(r.WorkTreeRoot, false), taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CheckpointsResult{TaskID: taskID, Checkpoints: checkpoints}, nil
+}
+
+func runTaskRestore(opts *RestoreOptions) error {
+	result, err := cliClient(opts.OutputJSON).Restore(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Restored checkpoint %d for task %s\n", result.Restored.Number, result.TaskID)
+		fmt.Printf("  Previous worktree saved as checkpoint %d\n", result.Saved.Number)
+	}
+
+	return nil
+}
+
+// Restore rolls the task's worktree back to a checkpoint, saving the current state first
+func (c *Client) Restore(ctx context.Context, opts *RestoreOptions) (*RestoreResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	// Validate the task before locking, so no lock is taken for an unknown task
+	t, err := loadCheckpointTask(store, opts.TaskID, "restore")
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold the task lock while the worktree is rewritten
+	taskLock, err := acquireTaskLock(ctx, r, cfg, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
+
+	// Reload the task now that it is locked, in case it changed while waiting
+	t, err = loadCheckpointTask(store, t.ID, "restore")
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints, err := listCheckpoints(ctx, git.New(r.WorkTreeRoot, false), t.ID)
+	if err != nil {
+		return nil, err
+	}
+	var target *Checkpoint
+	for _, cp := range checkpoints {
+		if cp.Number == opts.Number {
+			target = cp
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("checkpoint %d not found for task %s\nUse 'awt task checkpoints %s' to list them", opts.Number, t.ID, t.ID)
+	}
+
+	// Save the current worktree first so the restore can be rolled back
+	saved, err := saveCheckpoint(ctx, r, cfg, t, fmt.Sprintf("before restoring checkpoint %d", target.Number))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := git.New(t.WorktreePath, cfg.VerboseGit).RestoreWorktree(ctx, target.Commit); err != nil {
+		return nil, err
+	}
+	recordEvent(r, t.ID, task.Event{Type: task.EventRestore, SHA: target.Commit, Message: fmt.Sprintf("checkpoint %d (saved current as %d)", target.Number, saved.Number)})
+
+	return &RestoreResult{TaskID: t.ID, Restored: target, Saved: saved}, nil
+}
+
+// loadCheckpointTask loads a task whose worktree is checkpointed or restored
+func loadCheckpointTask(store task.Store, taskID, operation string) (*task.Task, error) {
+	t, err := store.Load(taskID)
+	if err != nil {
+		return nil, errors.InvalidTaskID(taskID)
+	}
+	if err := t.RequireState(operation, task.StateActive); err != nil {
+		return nil, err
+	}
+	if err := requireWorktree(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// saveCheckpoint snapshots the task's worktree under the next free checkpoint number.
+// The caller must hold the task lock.
+func saveCheckpoint(ctx context.Context, r *repo.Repo, cfg *config.Config, t *task.Task, message string) (*Checkpoint, error) {
+	if message == "" {
+		message = "checkpoint"
+	}
+
+	commit, err := git.New(t.WorktreePath, cfg.VerboseGit).SnapshotWorktree(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+
+	// Number the checkpoint; the ref is created only if it does not exist yet
+	g := git.New(r.WorkTreeRoot, cfg.VerboseGit)
+	for attempt := 0; attempt < checkpointSaveRetries; attempt++ {
+		existing, err := listCheckpoints(ctx, g, t.ID)
+		if err != nil {
+			return nil, err
+		}
+		n := 1
+		if len(existing) > 0 {
+			n = existing[len(existing)-1].Number + 1
+		}
+		ref := fmt.Sprintf("%s%s/%d", checkpointRefPrefix, t.ID, n)
+		err = g.UpdateRef(ctx, ref, commit, "")
+		if err == nil {
+			recordEvent(r, t.ID, task.Event{Type: task.EventCheckpoint, SHA: commit, Message: fmt.Sprintf("%d: %s", n, message)})
+			return &Checkpoint{TaskID: t.ID, Number: n, Ref: ref, Commit: commit, Message: message}, nil
+		}
+		// Only a lost race for the number is worth another attempt
+		if !stderrors.Is(err, git.ErrRefChanged) {
+			return nil, fmt.Errorf("failed to save checkpoint for task %s: %w", t.ID, err)
+		}
+	}
+
+	return nil, fmt.Errorf("failed to save checkpoint for task %s: checkpoint numbers kept changing", t.ID)
+}
+
+// listCheckpoints returns the task's checkpoints ordered by number
+func listCheckpoints(ctx context.Context, g *git.Git, taskID string) ([]*Checkpoint, error) {
+	prefix := checkpointRefPrefix + taskID + "/"
+	refs, err := g.ListRefCommits(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	checkpoints := []*Checkpoint{}
+	for _, ref := range refs {
+		n, err := strconv.Atoi(strings.TrimPrefix(ref.Ref, prefix))
+		if err != nil {
+			continue
+		}
+		cp := &Checkpoint{TaskID: taskID, Number: n, Ref: ref.Ref, Commit: ref.Commit, Message: ref.Subject}
+		if !ref.Created.IsZero() {
+			cp.CreatedAt = ref.Created.Format("2006-01-02 15:04:05")
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+
+	// Ref names sort as strings (10 before 2), so order by number
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Number < checkpoints[j].Number
+	})
+
+	return checkpoints, nil
+}
+
+// deleteCheckpoints removes all of the task's checkpoint refs
+func deleteCheckpoints(ctx context.Context, g *git.Git, taskID string) error {
+	checkpoints, err := listCheckpoints(ctx, g, taskID)
+	if err != nil {
+		return err
+	}
+	for _, cp := range checkpoints {
+		if err := g.DeleteRef(ctx, cp.Ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveTaskID returns the task named by ID, by --branch, or by the current worktree, in that order
+func resolveTaskID(ctx context.Context, r *repo.Repo, store task.Store, taskID, branch string) (string, error) {
+	if taskID != "" {
+		return taskID, nil
+	}
+
+	if branch != "" {
+		// Look up the task that owns the branch
+		taskID = taskIDForBranch(store, branch)
+		if taskID == "" {
+			return "", errors.TaskNotFoundForBranch(branch)
+		}
+		return taskID, nil
+	}
+
+	// Try to infer from current worktree
+	taskID, err := inferTaskIDFromCurrentDirectory(ctx, r)
+	if err != nil {
+		return "", errors.TaskIDRequired(err)
+	}
+	return taskID, nil
+}
@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/lock"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestCheckpointAndRestore(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Checkpointed task",
+		Base:         "HEAD",
+		ID:           "cp-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tk, err := store.Load("cp-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	wt := tk.WorktreePath
+	wtGit := git.New(wt, false)
+	headBefore, _ := wtGit.RevParse(context.Background(), "HEAD")
+
+	// A tracked change and an untracked file
+	if err := os.WriteFile(filepath.Join(wt, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "new.txt"), []byte("untracked\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := runTaskCheckpoint(&CheckpointOptions{RepoPath: repoPath, TaskID: "cp-task", Message: "first", OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskCheckpoint() failed: %v", err)
+	}
+
+	// The checkpoint leaves HEAD and the index alone
+	if head, _ := wtGit.RevParse(context.Background(), "HEAD"); head != headBefore {
+		t.Errorf("HEAD moved from %s to %s", headBefore, head)
+	}
+	if dirty, _ := wtGit.IsDirty(context.Background()); !dirty {
+		t.Error("expected the worktree to stay dirty after a checkpoint")
+	}
+	if staged, _ := exec.Command("git", "-C", wt, "diff", "--cached", "--name-only").Output(); len(staged) != 0 {
+		t.Errorf("checkpoint staged files: %s", staged)
+	}
+
+	// Diverge from the checkpoint
+	if err := os.WriteFile(filepath.Join(wt, "README.md"), []byte("later\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Remove(filepath.Join(wt, "new.txt")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "stray.txt"), []byte("stray\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := runTaskRestore(&RestoreOptions{RepoPath: repoPath, TaskID: "cp-task", Number: 1, OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskRestore() failed: %v", err)
+	}
+
+	for name, want := range map[string]string{"README.md": "changed\n", "new.txt": "untracked\n"} {
+		data, err := os.ReadFile(filepath.Join(wt, name))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(wt, "stray.txt")); !os.IsNotExist(err) {
+		t.Error("expected stray.txt to be removed by restore")
+	}
+	if head, _ := wtGit.RevParse(context.Background(), "HEAD"); head != headBefore {
+		t.Errorf("HEAD moved from %s to %s", headBefore, head)
+	}
+
+	// The pre-restore state was saved as checkpoint 2
+	checkpoints, err := listCheckpoints(context.Background(), git.New(repoPath, false), "cp-task")
+	if err != nil {
+		t.Fatalf("listCheckpoints() failed: %v", err)
+	}
+	if len(checkpoints) != 2 || checkpoints[0].Message != "first" || checkpoints[1].Number != 2 {
+		t.Fatalf("unexpected checkpoints: %+v", checkpoints)
+	}
+	if err := runTaskRestore(&RestoreOptions{RepoPath: repoPath, TaskID: "cp-task", Number: 2, OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskRestore() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wt, "stray.txt")); string(data) != "stray\n" {
+		t.Errorf("stray.txt = %q, want it back after restoring checkpoint 2", data)
+	}
+
+	if err := runTaskCheckpoints(&CheckpointsOptions{RepoPath: repoPath, TaskID: "cp-task", OutputJSON: true}); err != nil {
+		t.Errorf("runTaskCheckpoints() failed: %v", err)
+	}
+	if err := runTaskRestore(&RestoreOptions{RepoPath: repoPath, TaskID: "cp-task", Number: 99}); err == nil {
+		t.Error("expected error for a missing checkpoint")
+	}
+}
+
+func TestRestoreChecksTaskBeforeLocking(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+	t.Setenv("AWT_LOCK_TIMEOUT", "1")
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Abandoned task",
+		Base:         "HEAD",
+		ID:           "abandoned-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+	if err := runTaskAbandon(&AbandonOptions{RepoPath: repoPath, TaskID: "abandoned-task", OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskAbandon() failed: %v", err)
+	}
+
+	// Another command holds the lock, but the task cannot be restored anyway
+	lm := lock.NewLockManager(filepath.Join(repoPath, ".git"))
+	held, err := lm.AcquireTask(context.Background(), "abandoned-task")
+	if err != nil {
+		t.Fatalf("failed to acquire task lock: %v", err)
+	}
+	defer func() {
+		_ = held.Release()
+	}()
+
+	err = runTaskRestore(&RestoreOptions{RepoPath: repoPath, TaskID: "abandoned-task", Number: 1})
+	if errors.ExitCodeOf(err) != errors.ExitInvalidTaskState {
+		t.Errorf("runTaskRestore() error = %v, want exit code %d", err, errors.ExitInvalidTaskState)
+	}
+
+	err = runTaskRestore(&RestoreOptions{RepoPath: repoPath, TaskID: "no-such-task", Number: 1})
+	if errors.ExitCodeOf(err) != errors.ExitInvalidTaskID {
+		t.Errorf("runTaskRestore() error = %v, want exit code %d", err, errors.ExitInvalidTaskID)
+	}
+}
+
+func TestCheckpointReportsRefErrors(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Checkpointed task",
+		Base:         "HEAD",
+		ID:           "cp-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	// A ref nested under the next checkpoint name makes creating it fail for good
+	head := gitOutput(t, repoPath, "rev-parse", "HEAD")
+	gitOutput(t, repoPath, "update-ref", checkpointRefPrefix+"cp-task/1/nested", head)
+
+	_, err := (&Client{}).Checkpoint(context.Background(), &CheckpointOptions{RepoPath: repoPath, TaskID: "cp-task"})
+	if err == nil {
+		t.Fatal("expected an error creating the checkpoint ref")
+	}
+	if strings.Contains(err.Error(), "kept changing") || !strings.Contains(err.Error(), "update-ref") {
+		t.Errorf("error = %v, want the update-ref failure", err)
+	}
+}
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Client runs AWT operations and returns their results instead of printing them.
+// The cobra commands are thin wrappers that print what it returns, and pkg/awt
+// exposes it to Go programs.
+type Client struct {
+	// Progress receives human-readable progress messages and warnings (nil discards them)
+	Progress io.Writer
+}
+
+// cliClient returns the client used by the cobra commands: progress messages go to
+// stdout unless the command prints JSON
+func cliClient(outputJSON bool) *Client {
+	if outputJSON {
+		return &Client{}
+	}
+	return &Client{Progress: os.Stdout}
+}
+
+// progressf writes a progress message if the client has a progress writer
+func (c *Client) progressf(format string, args ...any) {
+	if c.Progress != nil {
+		_, _ = fmt.Fprintf(c.Progress, format, args...)
+	}
+}
@@ -1,10 +1,12 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/kernel-labs-ai/awt/internal/git"
 	"github.com/kernel-labs-ai/awt/internal/repo"
@@ -31,6 +33,8 @@ type CommitResult struct {
 	CommitSHA  string `json:"commit_sha"`
 	Message    string `json:"message"`
 	FilesCount int    `json:"files_count,omitempty"`
+	// GitOutput is git's summary of the commit, shown by the CLI
+	GitOutput string `json:"-"`
 }
 
 // NewTaskCommitCmd creates the task commit command
@@ -77,47 +81,101 @@ Example:
 }
 
 func runTaskCommit(opts *CommitOptions) error {
+	result, err := cliClient(opts.OutputJSON).Commit(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Committed successfully!\n")
+		fmt.Printf("  Task: %s\n", result.TaskID)
+		fmt.Printf("  Commit: %s\n", result.CommitSHA)
+		// Show abbreviated commit output
+		fmt.Println()
+		fmt.Println(result.GitOutput)
+	}
+
+	return nil
+}
+
+// Commit commits changes in the task's worktree and records the commit on the task
+func (c *Client) Commit(ctx context.Context, opts *CommitOptions) (*CommitResult, error) {
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
 	}
 
-	store := task.NewTaskStore(r.GitCommonDir)
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
 
 	// Determine task ID
-	taskID := opts.TaskID
+	taskID, err := resolveTaskID(ctx, r, store, opts.TaskID, opts.Branch)
+	if err != nil {
+		return nil, err
+	}
 
-	if taskID == "" && opts.Branch != "" {
-		// Extract task ID from branch name
-		taskID = extractTaskIDFromBranch(opts.Branch)
-		if taskID == "" {
-			return fmt.Errorf("could not extract task ID from branch: %s", opts.Branch)
-		}
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if taskID == "" {
-		// Try to infer from current worktree
-		taskID, err = inferTaskIDFromCurrentDirectory(r)
-		if err != nil {
-			return fmt.Errorf("could not infer task ID: %w\nProvide task ID as argument or use --branch flag", err)
-		}
+	// Hold the task lock so concurrent commands cannot mutate the task
+	taskLock, err := acquireTaskLock(ctx, r, cfg, taskID)
+	if err != nil {
+		return nil, err
 	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
 
 	// Load task
 	t, err := store.Load(taskID)
 	if err != nil {
-		return errors.InvalidTaskID(taskID)
+		return nil, errors.InvalidTaskID(taskID)
+	}
+
+	// Only active tasks own their branch
+	if err := t.RequireState("commit", task.StateActive); err != nil {
+		return nil, err
+	}
+	if err := requireWorktree(t); err != nil {
+		return nil, err
+	}
+
+	// Record the pre-operation state so the command can be undone
+	if err := recordOperation(ctx, r, "task commit", t, false); err != nil {
+		return nil, err
 	}
 
 	// Create Git wrapper for the worktree
 	g := git.New(t.WorktreePath, false)
 
-	// Stage files if --all flag is set
+	// Commits must land on the task branch, not on a detached HEAD or another branch
+	branchName := strings.TrimPrefix(t.Branch, "refs/heads/")
+	if current, _ := g.CurrentBranch(ctx); current != branchName {
+		if current == "" {
+			current = "a detached HEAD"
+		}
+		return nil, errors.CommitFailed("commit", fmt.Sprintf("worktree is on %s instead of %s; run 'git switch %s' in the worktree", current, branchName, branchName))
+	}
+
+	// Stage files if --all flag is set, leaving out the files AWT exchanges with the agent
 	if opts.All {
-		result, err := g.Add(".")
+		result, err := g.Add(ctx, allPathspecs()...)
 		if err != nil || result.ExitCode != 0 {
-			return fmt.Errorf("failed to stage files: %s", result.Stderr)
+			return nil, errors.CommitFailed("stage files", result.Stderr)
 		}
 	}
 
@@ -130,58 +188,48 @@ func runTaskCommit(opts *CommitOptions) error {
 	// Validate commit message
 	validator := safety.NewValidator()
 	if err := validator.ValidateCommitMessage(message); err != nil {
-		return fmt.Errorf("invalid commit message: %w", err)
+		return nil, errors.InvalidCommitMessage(err)
 	}
 
 	// Determine GPG signing
 	gpgSign := opts.GPGSign != ""
 
 	// Execute commit
-	result, err := g.Commit(message, false, opts.Signoff, gpgSign)
+	result, err := g.Commit(ctx, message, false, opts.Signoff, gpgSign)
 	if err != nil || result.ExitCode != 0 {
-		// Check for common error cases
-		if strings.Contains(result.Stderr, "nothing to commit") {
-			return fmt.Errorf("nothing to commit, working tree clean")
+		// Check for common error cases (git reports these on stdout)
+		output := result.Stderr + "\n" + result.Stdout
+		if strings.Contains(output, "no changes added to commit") {
+			return nil, errors.NothingToCommit(true)
 		}
-		if strings.Contains(result.Stderr, "no changes added to commit") {
-			return fmt.Errorf("no changes added to commit\nUse --all to stage all modified files, or stage files manually")
+		if strings.Contains(output, "nothing to commit") {
+			return nil, errors.NothingToCommit(false)
 		}
-		return fmt.Errorf("failed to commit: %s", result.Stderr)
+		return nil, errors.CommitFailed("commit", strings.TrimSpace(output))
 	}
 
 	// Get the commit SHA from the output
-	commitSHA, err := g.RevParse("HEAD")
+	commitSHA, err := g.RevParse(ctx, "HEAD")
 	if err != nil || commitSHA == "" {
-		return fmt.Errorf("failed to get commit SHA")
+		return nil, errors.CommitFailed("read the new commit", "HEAD did not resolve")
 	}
 
 	// Update task metadata with last commit
-	t.LastCommit = commitSHA
-	if err := store.Save(t); err != nil {
-		return fmt.Errorf("failed to update task metadata: %w", err)
-	}
-
-	// Output result
-	if opts.OutputJSON {
-		output := CommitResult{
-			TaskID:    taskID,
-			CommitSHA: commitSHA,
-			Message:   message,
-		}
-		data, _ := json.MarshalIndent(output, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		fmt.Printf("Committed successfully!\n")
-		fmt.Printf("  Task: %s\n", taskID)
-		fmt.Printf("  Commit: %s\n", commitSHA)
-		if !opts.OutputJSON {
-			// Show abbreviated commit output
-			fmt.Println()
-			fmt.Println(result.Stdout)
-		}
+	t, err = task.Update(store, taskLock, taskID, func(t *task.Task) error {
+		t.LastCommit = commitSHA
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task metadata: %w", err)
 	}
-
-	return nil
+	recordEvent(r, taskID, task.Event{Type: task.EventCommit, SHA: commitSHA, Message: strings.SplitN(message, "\n", 2)[0]})
+
+	return &CommitResult{
+		TaskID:    taskID,
+		CommitSHA: commitSHA,
+		Message:   message,
+		GitOutput: result.Stdout,
+	}, nil
 }
 
 // generateDefaultCommitMessage generates a default commit message for a task
@@ -199,3 +247,18 @@ func generateDefaultCommitMessage(t *task.Task) string {
 
 	return sb.String()
 }
+
+// worktreeOnlyFiles are files AWT exchanges with the agent in the root of a
+// worktree; they are not part of the task's work, so 'awt task commit --all'
+// does not stage them
+var worktreeOnlyFiles = []string{PRSummaryFile, FeedbackMarkdownFile, FeedbackJSONFile}
+
+// allPathspecs returns the pathspecs 'awt task commit --all' stages: the whole
+// worktree except worktreeOnlyFiles
+func allPathspecs() []string {
+	pathspecs := []string{"."}
+	for _, name := range worktreeOnlyFiles {
+		pathspecs = append(pathspecs, ":(exclude)"+name)
+	}
+	return pathspecs
+}
@@ -0,0 +1,248 @@
+package commands
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/lock"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestRunTaskCommitRejectsAbandonedTask(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tk := &task.Task{
+		ID:           "abandoned-task",
+		Agent:        "test-agent",
+		Title:        "Abandoned task",
+		Branch:       "awt/test-agent/abandoned-task",
+		Base:         "HEAD",
+		CreatedAt:    time.Now(),
+		State:        task.StateAbandoned,
+		WorktreePath: repoPath,
+	}
+	if err := store.Save(tk); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+
+	err := runTaskCommit(&CommitOptions{RepoPath: repoPath, TaskID: tk.ID, Message: "should fail"})
+	if err == nil {
+		t.Fatal("expected error committing to an abandoned task")
+	}
+
+	var awtErr *errors.AWTError
+	if !stderrors.As(err, &awtErr) || awtErr.Code != errors.ExitInvalidTaskState {
+		t.Errorf("expected INVALID_TASK_STATE error, got %v", err)
+	}
+}
+
+func TestRunTaskCommitFailsWhenTaskLocked(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+	t.Setenv("AWT_LOCK_TIMEOUT", "1")
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Locked task",
+		Base:         "HEAD",
+		ID:           "locked-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	// Simulate another command holding the task lock
+	lm := lock.NewLockManager(filepath.Join(repoPath, ".git"))
+	held, err := lm.AcquireTask(context.Background(), "locked-task")
+	if err != nil {
+		t.Fatalf("failed to acquire task lock: %v", err)
+	}
+	defer func() {
+		_ = held.Release()
+	}()
+
+	err = runTaskCommit(&CommitOptions{RepoPath: repoPath, TaskID: "locked-task", Message: "should wait", All: true})
+
+	var awtErr *errors.AWTError
+	if !stderrors.As(err, &awtErr) || awtErr.Code != errors.ExitLockHeld {
+		t.Fatalf("expected LOCK_HELD error, got %v", err)
+	}
+	if !strings.Contains(awtErr.Message, fmt.Sprintf("pid %d", os.Getpid())) {
+		t.Errorf("error does not name the holder: %s", awtErr.Message)
+	}
+}
+
+func TestRunTaskCommitNothingToCommit(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Clean task",
+		Base:         "HEAD",
+		ID:           "clean-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	err := runTaskCommit(&CommitOptions{RepoPath: repoPath, TaskID: "clean-task", Message: "empty", All: true})
+	if errors.ExitCodeOf(err) != errors.ExitNothingToCommit {
+		t.Errorf("expected NOTHING_TO_COMMIT error, got %v", err)
+	}
+
+	err = runTaskCommit(&CommitOptions{RepoPath: repoPath, Branch: "no/such/branch", Message: "empty"})
+	if errors.ExitCodeOf(err) != errors.ExitInvalidTaskID {
+		t.Errorf("expected INVALID_TASK_ID error for an unknown branch, got %v", err)
+	}
+}
+
+func TestRunTaskCommitAllLeavesOutSummary(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Summary task",
+		Base:         "HEAD",
+		ID:           "summary-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+	tk, err := task.NewTaskStore(filepath.Join(repoPath, ".git")).Load("summary-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+
+	excludePath := filepath.Join(repoPath, ".git", "info", "exclude")
+	excludeBefore, _ := os.ReadFile(excludePath)
+
+	for name, content := range map[string]string{"feature.txt": "feature\n", PRSummaryFile: "Summary\n"} {
+		if err := os.WriteFile(filepath.Join(tk.WorktreePath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := runTaskCommit(&CommitOptions{RepoPath: repoPath, TaskID: tk.ID, Message: "Add feature", All: true, OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskCommit() failed: %v", err)
+	}
+
+	if files := gitOutput(t, tk.WorktreePath, "show", "--name-only", "--format=", "HEAD"); files != "feature.txt" {
+		t.Errorf("committed files = %q, want only feature.txt", files)
+	}
+	if status := gitOutput(t, tk.WorktreePath, "status", "--porcelain"); status != "?? "+PRSummaryFile {
+		t.Errorf("expected %s to be left untracked, got status %q", PRSummaryFile, status)
+	}
+
+	// The repository's own exclude file is left alone
+	if excludeAfter, _ := os.ReadFile(excludePath); string(excludeAfter) != string(excludeBefore) {
+		t.Errorf("info/exclude changed from %q to %q", excludeBefore, excludeAfter)
+	}
+}
+
+func TestCommandsRefuseTaskWithoutWorktree(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tk := &task.Task{
+		ID:        "no-worktree",
+		Agent:     "test-agent",
+		Title:     "Task without worktree",
+		Branch:    "awt/test-agent/no-worktree",
+		Base:      "HEAD",
+		CreatedAt: time.Now(),
+		State:     task.StateActive,
+	}
+	if err := store.Save(tk); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+
+	// Run from the main checkout, where git with an empty worktree path would act
+	t.Chdir(repoPath)
+	if err := os.WriteFile(filepath.Join(repoPath, "stray.txt"), []byte("stray\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	head := gitOutput(t, repoPath, "rev-parse", "HEAD")
+
+	ctx := context.Background()
+	_, err := (&Client{}).Commit(ctx, &CommitOptions{RepoPath: repoPath, TaskID: tk.ID, Message: "Stray", All: true})
+	if errors.ExitCodeOf(err) != errors.ExitWorktreeNotFound {
+		t.Errorf("Commit() error = %v, want exit code %d", err, errors.ExitWorktreeNotFound)
+	}
+	_, err = (&Client{}).Checkpoint(ctx, &CheckpointOptions{RepoPath: repoPath, TaskID: tk.ID})
+	if errors.ExitCodeOf(err) != errors.ExitWorktreeNotFound {
+		t.Errorf("Checkpoint() error = %v, want exit code %d", err, errors.ExitWorktreeNotFound)
+	}
+	_, err = (&Client{}).Exec(ctx, &ExecOptions{RepoPath: repoPath, TaskID: tk.ID, Command: []string{"true"}})
+	if errors.ExitCodeOf(err) != errors.ExitWorktreeNotFound {
+		t.Errorf("Exec() error = %v, want exit code %d", err, errors.ExitWorktreeNotFound)
+	}
+
+	if got := gitOutput(t, repoPath, "rev-parse", "HEAD"); got != head {
+		t.Errorf("main checkout HEAD moved to %s, want %s", got, head)
+	}
+	if status := gitOutput(t, repoPath, "status", "--porcelain"); status != "?? stray.txt" {
+		t.Errorf("main checkout status = %q, want only the untracked stray.txt", status)
+	}
+}
+
+func TestRunTaskCommitRefusesDetachedHead(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Detached task",
+		Base:         "HEAD",
+		ID:           "detached-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tk, err := store.Load("detached-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	gitOutput(t, tk.WorktreePath, "switch", "--detach")
+	_ = os.WriteFile(filepath.Join(tk.WorktreePath, "work.txt"), []byte("work\n"), 0644)
+
+	err = runTaskCommit(&CommitOptions{RepoPath: repoPath, TaskID: "detached-task", Message: "Work", All: true})
+	if errors.ExitCodeOf(err) != errors.ExitCommitFailed {
+		t.Errorf("runTaskCommit() error = %v, want exit code %d", err, errors.ExitCommitFailed)
+	}
+	if got := gitOutput(t, repoPath, "rev-parse", tk.Branch); got != gitOutput(t, tk.WorktreePath, "rev-parse", "HEAD") {
+		t.Errorf("branch moved to %s", got)
+	}
+}
@@ -0,0 +1,357 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// Test statuses reported by compare
+const (
+	// TestStatusPass means the test command exited with status 0
+	TestStatusPass = "pass"
+	// TestStatusFail means the test command exited with a non-zero status
+	TestStatusFail = "fail"
+	// TestStatusSkipped means the attempt has no worktree to run the test command in
+	TestStatusSkipped = "skipped"
+)
+
+// CompareOptions contains options for the compare command
+type CompareOptions struct {
+	RepoPath    string
+	TaskIDs     []string
+	Group       string
+	TestCommand string
+	NoRangeDiff bool
+	OutputJSON  bool
+}
+
+// CompareAttempt summarizes one task branch in a comparison
+type CompareAttempt struct {
+	TaskID       string `json:"task_id"`
+	Agent        string `json:"agent"`
+	Branch       string `json:"branch"`
+	Tip          string `json:"tip"`
+	Commits      int    `json:"commits"`
+	FilesChanged int    `json:"files_changed"`
+	Insertions   int    `json:"insertions"`
+	Deletions    int    `json:"deletions"`
+	TestStatus   string `json:"test_status,omitempty"`
+}
+
+// CompareFileChange is the change one attempt made to a file
+type CompareFileChange struct {
+	Added   int  `json:"added"`
+	Deleted int  `json:"deleted"`
+	Binary  bool `json:"binary,omitempty"`
+}
+
+// CompareFile is a file touched by at least one attempt
+type CompareFile struct {
+	Path string `json:"path"`
+	// Partial is true when only some of the attempts touched the file
+	Partial bool `json:"partial"`
+	// Changes maps task ID to that attempt's change to the file
+	Changes map[string]CompareFileChange `json:"changes"`
+}
+
+// CompareRangeDiff is the git range-diff between two attempts
+type CompareRangeDiff struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Output string `json:"output"`
+}
+
+// CompareResult represents the output of the compare command
+type CompareResult struct {
+	MergeBase  string             `json:"merge_base"`
+	Attempts   []CompareAttempt   `json:"attempts"`
+	Files      []CompareFile      `json:"files"`
+	RangeDiffs []CompareRangeDiff `json:"range_diffs,omitempty"`
+}
+
+// NewCompareCmd creates the compare command
+func NewCompareCmd() *cobra.Command {
+	opts := &CompareOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "compare [task-id...]",
+		Short: "Compare competing task branches side by side",
+		Long: `Compare the branches of several tasks against their shared merge-base.
+
+Shows:
+  1. A summary table of commits, lines changed and test status per attempt
+  2. A per-file diffstat, marking files that only some attempts touched
+  3. git range-diff between each pair of attempts (unless --no-range-diff)
+
+Tasks are given as arguments, or all tasks of a group with --group.
+With --test, the command is run through 'sh -c' in each attempt's worktree
+and its exit status is reported as pass/fail.
+
+Example:
+  awt compare 20250110-120000-abc123-claude 20250110-120000-abc123-codex
+  awt compare --group=20250110-120000-abc123 --test="go test ./..."
+  awt compare --group=20250110-120000-abc123 --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.TaskIDs = args
+			return runCompare(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.Group, "group", "", "compare all tasks of a task group")
+	cmd.Flags().StringVar(&opts.TestCommand, "test", "", "command to run in each worktree to determine test status")
+	cmd.Flags().BoolVar(&opts.NoRangeDiff, "no-range-diff", false, "skip git range-diff between attempts")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runCompare(opts *CompareOptions) error {
+	result, err := cliClient(opts.OutputJSON).Compare(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printCompareResult(result)
+	}
+
+	return nil
+}
+
+// Compare compares the branches of several tasks against their shared merge-base
+func (c *Client) Compare(ctx context.Context, opts *CompareOptions) (*CompareResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	// Collect the tasks to compare
+	var tasks []*task.Task
+	if opts.Group != "" {
+		tasks, err = loadGroupTasks(store, opts.Group)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range opts.TaskIDs {
+		t, err := store.Load(id)
+		if err != nil {
+			return nil, errors.InvalidTaskID(id)
+		}
+		tasks = append(tasks, t)
+	}
+	if len(tasks) < 2 {
+		return nil, fmt.Errorf("at least two tasks are needed to compare\nProvide task IDs as arguments or use --group")
+	}
+
+	g := git.New(r.WorkTreeRoot, cfg.VerboseGit)
+
+	result, err := compareTasks(ctx, g, tasks, !opts.NoRangeDiff)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.TestCommand != "" {
+		for i, t := range tasks {
+			result.Attempts[i].TestStatus = runTestCommand(ctx, t.WorktreePath, opts.TestCommand)
+		}
+	}
+
+	return result, nil
+}
+
+// compareTasks computes the diffstats of each task branch against the merge-base shared
+// by all of them, and optionally the range-diff between each pair of branches
+func compareTasks(ctx context.Context, g *git.Git, tasks []*task.Task, rangeDiff bool) (*CompareResult, error) {
+	tips := make([]string, len(tasks))
+	for i, t := range tasks {
+		tip, err := g.RevParse(ctx, "refs/heads/"+strings.TrimPrefix(t.Branch, "refs/heads/"))
+		if err != nil {
+			return nil, fmt.Errorf("branch of task %s not found: %s", t.ID, t.Branch)
+		}
+		tips[i] = tip
+	}
+
+	mergeBase, err := g.MergeBaseOctopus(ctx, tips...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a shared merge-base: %w", err)
+	}
+
+	result := &CompareResult{
+		MergeBase: mergeBase,
+		Attempts:  []CompareAttempt{},
+		Files:     []CompareFile{},
+	}
+	files := make(map[string]*CompareFile)
+
+	for i, t := range tasks {
+		attempt := CompareAttempt{
+			TaskID: t.ID,
+			Agent:  t.Agent,
+			Branch: t.Branch,
+			Tip:    tips[i],
+		}
+
+		commits, err := g.CountCommits(ctx, mergeBase+".."+tips[i])
+		if err != nil {
+			return nil, err
+		}
+		attempt.Commits = commits
+
+		stats, err := g.DiffNumStat(ctx, mergeBase, tips[i])
+		if err != nil {
+			return nil, err
+		}
+		attempt.FilesChanged = len(stats)
+		for _, s := range stats {
+			attempt.Insertions += s.Added
+			attempt.Deletions += s.Deleted
+
+			f, ok := files[s.Path]
+			if !ok {
+				f = &CompareFile{Path: s.Path, Changes: make(map[string]CompareFileChange)}
+				files[s.Path] = f
+			}
+			f.Changes[t.ID] = CompareFileChange{Added: s.Added, Deleted: s.Deleted, Binary: s.Binary}
+		}
+
+		result.Attempts = append(result.Attempts, attempt)
+	}
+
+	for _, f := range files {
+		f.Partial = len(f.Changes) < len(tasks)
+		result.Files = append(result.Files, *f)
+	}
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].Path < result.Files[j].Path
+	})
+
+	if rangeDiff {
+		for i := 0; i < len(tasks); i++ {
+			for j := i + 1; j < len(tasks); j++ {
+				output, err := g.RangeDiff(ctx, mergeBase+".."+tips[i], mergeBase+".."+tips[j])
+				if err != nil {
+					return nil, err
+				}
+				result.RangeDiffs = append(result.RangeDiffs, CompareRangeDiff{
+					From:   tasks[i].ID,
+					To:     tasks[j].ID,
+					Output: output,
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// runTestCommand runs a shell command in a worktree and reports pass/fail
+func runTestCommand(ctx context.Context, worktreePath, command string) string {
+	if worktreePath == "" {
+		return TestStatusSkipped
+	}
+	if _, err := os.Stat(worktreePath); err != nil {
+		return TestStatusSkipped
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = worktreePath
+	if err := cmd.Run(); err != nil {
+		return TestStatusFail
+	}
+	return TestStatusPass
+}
+
+// printCompareResult prints a human-readable comparison
+func printCompareResult(result *CompareResult) {
+	mergeBase := result.MergeBase
+	if len(mergeBase) > 12 {
+		mergeBase = mergeBase[:12]
+	}
+	fmt.Printf("Merge base: %s\n\n", mergeBase)
+
+	// Summary table
+	fmt.Printf("%-30s %-12s %8s %6s %8s %8s %-8s\n", "TASK", "AGENT", "COMMITS", "FILES", "+", "-", "TESTS")
+	fmt.Println(strings.Repeat("-", 86))
+	for _, a := range result.Attempts {
+		tests := a.TestStatus
+		if tests == "" {
+			tests = "-"
+		}
+		fmt.Printf("%-30s %-12s %8d %6d %8d %8d %-8s\n",
+			a.TaskID,
+			a.Agent,
+			a.Commits,
+			a.FilesChanged,
+			a.Insertions,
+			a.Deletions,
+			tests,
+		)
+	}
+
+	// Per-file diffstat, one column per attempt
+	if len(result.Files) > 0 {
+		fmt.Printf("\nFiles (* = touched by only some attempts):\n")
+		for _, f := range result.Files {
+			marker := " "
+			if f.Partial {
+				marker = "*"
+			}
+			fmt.Printf("%s %-40s", marker, f.Path)
+			for _, a := range result.Attempts {
+				change, ok := f.Changes[a.TaskID]
+				switch {
+				case !ok:
+					fmt.Printf(" %14s", "-")
+				case change.Binary:
+					fmt.Printf(" %14s", "bin")
+				default:
+					fmt.Printf(" %14s", fmt.Sprintf("+%d/-%d", change.Added, change.Deleted))
+				}
+			}
+			fmt.Println()
+		}
+	}
+
+	for _, rd := range result.RangeDiffs {
+		fmt.Printf("\nRange diff %s .. %s:\n", rd.From, rd.To)
+		if rd.Output == "" {
+			fmt.Println("  (no commits)")
+			continue
+		}
+		fmt.Println(rd.Output)
+	}
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestCompareTasks(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	opts := &StartOptions{
+		RepoPath:     repoPath,
+		Agents:       []string{"claude", "codex"},
+		Title:        "Race task",
+		Base:         "HEAD",
+		ID:           "cmp",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(opts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tasks, err := loadGroupTasks(store, "cmp")
+	if err != nil {
+		t.Fatalf("loadGroupTasks() failed: %v", err)
+	}
+
+	// Both attempts touch shared.txt, only claude touches extra.txt
+	for _, tk := range tasks {
+		_ = os.WriteFile(filepath.Join(tk.WorktreePath, "shared.txt"), []byte(tk.Agent+"\n"), 0644)
+		if tk.Agent == "claude" {
+			_ = os.WriteFile(filepath.Join(tk.WorktreePath, "extra.txt"), []byte("a\nb\nc\n"), 0644)
+		}
+		gitOutput(t, tk.WorktreePath, "add", ".")
+		gitOutput(t, tk.WorktreePath, "commit", "-m", tk.Agent+" attempt")
+	}
+
+	result, err := compareTasks(context.Background(), git.New(repoPath, false), tasks, true)
+	if err != nil {
+		t.Fatalf("compareTasks() failed: %v", err)
+	}
+
+	if len(result.Attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(result.Attempts))
+	}
+	claude := result.Attempts[0]
+	if claude.Commits != 1 || claude.FilesChanged != 2 || claude.Insertions != 4 {
+		t.Errorf("unexpected claude stats: %+v", claude)
+	}
+
+	partial := map[string]bool{}
+	for _, f := range result.Files {
+		partial[f.Path] = f.Partial
+	}
+	if partial["shared.txt"] {
+		t.Error("shared.txt should not be partial")
+	}
+	if !partial["extra.txt"] {
+		t.Error("extra.txt should be partial")
+	}
+
+	if len(result.RangeDiffs) != 1 || result.RangeDiffs[0].Output == "" {
+		t.Errorf("expected one range diff, got %+v", result.RangeDiffs)
+	}
+
+	if status := runTestCommand(context.Background(), tasks[0].WorktreePath, "test -f extra.txt"); status != TestStatusPass {
+		t.Errorf("claude test status = %s, want %s", status, TestStatusPass)
+	}
+	if status := runTestCommand(context.Background(), tasks[1].WorktreePath, "test -f extra.txt"); status != TestStatusFail {
+		t.Errorf("codex test status = %s, want %s", status, TestStatusFail)
+	}
+	if status := runTestCommand(context.Background(), "", "true"); status != TestStatusSkipped {
+		t.Errorf("status without worktree = %s, want %s", status, TestStatusSkipped)
+	}
+
+	if err := runCompare(&CompareOptions{RepoPath: repoPath, Group: "cmp", TestCommand: "true", OutputJSON: true}); err != nil {
+		t.Fatalf("runCompare() failed: %v", err)
+	}
+}
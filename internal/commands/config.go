@@ -4,12 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/forge"
+	"github.com/kernel-labs-ai/awt/internal/idgen"
 	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
 	"github.com/spf13/cobra"
 )
 
@@ -87,13 +91,21 @@ func NewConfigGetCmd() *cobra.Command {
 Available keys:
   - default_agent: Default agent name
   - branch_prefix: Prefix for AWT branches (default: awt)
+  - branch_template: Branch name template (default: {prefix}/{agent}/{id})
   - worktree_dir: Default directory for worktrees (default: ./wt)
   - rebase_default: Use rebase instead of merge for sync (default: true)
   - auto_push: Automatically push on handoff (default: true)
   - auto_pr: Automatically create PR on handoff (default: true)
   - remote_name: Default remote name (default: origin)
   - lock_timeout: Lock acquisition timeout in seconds (default: 30)
+  - fetch_timeout: Timeout for git fetch in seconds, 0 for no limit (default: 300)
+  - push_timeout: Timeout for git push in seconds, 0 for no limit (default: 300)
+  - rebase_timeout: Timeout for the rebase or merge run by sync and handoff in seconds, 0 for no limit (default: 600)
   - verbose_git: Enable verbose git output (default: false)
+  - task_store: Task metadata backend, json, sqlite or git (default: json)
+  - forges.<host>.kind: Forge of the remote host: github, gitlab, gitea, forgejo or bitbucket-server
+  - forges.<host>.token: Forge API token for the remote host
+  - forges.<host>.api_url: Forge API base URL for the remote host
 
 Example:
   awt config get default_agent
@@ -129,7 +141,9 @@ The scope determines where the setting is stored:
 Example:
   awt config set default_agent claude
   awt config set auto_push false --scope=repo
-  awt config set lock_timeout 60 --scope=user`,
+  awt config set lock_timeout 60 --scope=user
+  awt config set forges.git.example.com.kind forgejo
+  awt config set forges.git.example.com.token <token>`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key := args[0]
@@ -216,6 +230,17 @@ func runConfigList(opts *ConfigOptions) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Never print forge tokens
+	hosts := make([]string, 0, len(cfg.Forges))
+	for host, forgeCfg := range cfg.Forges {
+		if forgeCfg.Token != "" {
+			forgeCfg.Token = "<set>"
+			cfg.Forges[host] = forgeCfg
+		}
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
 	if opts.OutputJSON {
 		data, _ := json.MarshalIndent(cfg, "", "  ")
 		fmt.Println(string(data))
@@ -223,13 +248,30 @@ func runConfigList(opts *ConfigOptions) error {
 		fmt.Println("Configuration settings:")
 		fmt.Printf("  default_agent:   %s\n", cfg.DefaultAgent)
 		fmt.Printf("  branch_prefix:   %s\n", cfg.BranchPrefix)
+		fmt.Printf("  branch_template: %s\n", cfg.BranchTemplate)
 		fmt.Printf("  worktree_dir:    %s\n", cfg.WorktreeDir)
 		fmt.Printf("  rebase_default:  %t\n", cfg.RebaseDefault)
 		fmt.Printf("  auto_push:       %t\n", cfg.AutoPush)
 		fmt.Printf("  auto_pr:         %t\n", cfg.AutoPR)
 		fmt.Printf("  remote_name:     %s\n", cfg.RemoteName)
 		fmt.Printf("  lock_timeout:    %d\n", cfg.LockTimeout)
+		fmt.Printf("  fetch_timeout:   %d\n", cfg.FetchTimeout)
+		fmt.Printf("  push_timeout:    %d\n", cfg.PushTimeout)
+		fmt.Printf("  rebase_timeout:  %d\n", cfg.RebaseTimeout)
 		fmt.Printf("  verbose_git:     %t\n", cfg.VerboseGit)
+		fmt.Printf("  task_store:      %s\n", cfg.TaskStore)
+		for _, host := range hosts {
+			forgeCfg := cfg.Forges[host]
+			if forgeCfg.Kind != "" {
+				fmt.Printf("  forges.%s.kind: %s\n", host, forgeCfg.Kind)
+			}
+			if forgeCfg.Token != "" {
+				fmt.Printf("  forges.%s.token: %s\n", host, forgeCfg.Token)
+			}
+			if forgeCfg.APIURL != "" {
+				fmt.Printf("  forges.%s.api_url: %s\n", host, forgeCfg.APIURL)
+			}
+		}
 	}
 
 	return nil
@@ -357,6 +399,18 @@ func runConfigPath(opts *ConfigOptions) error {
 }
 
 func getConfigValue(cfg *config.Config, key string) (string, error) {
+	if host, field, ok := splitForgeKey(key); ok {
+		forgeCfg := cfg.Forge(host)
+		switch field {
+		case "kind":
+			return forgeCfg.Kind, nil
+		case "token":
+			return forgeCfg.Token, nil
+		case "api_url":
+			return forgeCfg.APIURL, nil
+		}
+	}
+
 	key = strings.ReplaceAll(key, "-", "_")
 
 	switch key {
@@ -364,6 +418,8 @@ func getConfigValue(cfg *config.Config, key string) (string, error) {
 		return cfg.DefaultAgent, nil
 	case "branch_prefix":
 		return cfg.BranchPrefix, nil
+	case "branch_template":
+		return cfg.BranchTemplate, nil
 	case "worktree_dir":
 		return cfg.WorktreeDir, nil
 	case "rebase_default":
@@ -376,14 +432,45 @@ func getConfigValue(cfg *config.Config, key string) (string, error) {
 		return cfg.RemoteName, nil
 	case "lock_timeout":
 		return strconv.Itoa(cfg.LockTimeout), nil
+	case "fetch_timeout":
+		return strconv.Itoa(cfg.FetchTimeout), nil
+	case "push_timeout":
+		return strconv.Itoa(cfg.PushTimeout), nil
+	case "rebase_timeout":
+		return strconv.Itoa(cfg.RebaseTimeout), nil
 	case "verbose_git":
 		return strconv.FormatBool(cfg.VerboseGit), nil
+	case "task_store":
+		return cfg.TaskStore, nil
 	default:
 		return "", fmt.Errorf("unknown configuration key: %s", key)
 	}
 }
 
 func setConfigValue(cfg *config.Config, key, value string) error {
+	if host, field, ok := splitForgeKey(key); ok {
+		forgeCfg := cfg.Forge(host)
+		switch field {
+		case "kind":
+			kind, err := forge.ParseKind(value)
+			if err != nil {
+				return err
+			}
+			forgeCfg.Kind = string(kind)
+		case "token":
+			forgeCfg.Token = value
+		case "api_url":
+			forgeCfg.APIURL = value
+		default:
+			return fmt.Errorf("unknown configuration key: %s", key)
+		}
+		if cfg.Forges == nil {
+			cfg.Forges = make(map[string]config.ForgeConfig)
+		}
+		cfg.Forges[host] = forgeCfg
+		return nil
+	}
+
 	key = strings.ReplaceAll(key, "-", "_")
 
 	switch key {
@@ -391,6 +478,11 @@ func setConfigValue(cfg *config.Config, key, value string) error {
 		cfg.DefaultAgent = value
 	case "branch_prefix":
 		cfg.BranchPrefix = value
+	case "branch_template":
+		if err := idgen.ValidateBranchTemplate(value); err != nil {
+			return err
+		}
+		cfg.BranchTemplate = value
 	case "worktree_dir":
 		cfg.WorktreeDir = value
 	case "rebase_default":
@@ -407,8 +499,31 @@ func setConfigValue(cfg *config.Config, key, value string) error {
 			return fmt.Errorf("lock_timeout must be a positive integer")
 		}
 		cfg.LockTimeout = timeout
+	case "fetch_timeout":
+		timeout, err := strconv.Atoi(value)
+		if err != nil || timeout < 0 {
+			return fmt.Errorf("fetch_timeout must be a non-negative integer (0 means no limit)")
+		}
+		cfg.FetchTimeout = timeout
+	case "push_timeout":
+		timeout, err := strconv.Atoi(value)
+		if err != nil || timeout < 0 {
+			return fmt.Errorf("push_timeout must be a non-negative integer (0 means no limit)")
+		}
+		cfg.PushTimeout = timeout
+	case "rebase_timeout":
+		timeout, err := strconv.Atoi(value)
+		if err != nil || timeout < 0 {
+			return fmt.Errorf("rebase_timeout must be a non-negative integer (0 means no limit)")
+		}
+		cfg.RebaseTimeout = timeout
 	case "verbose_git":
 		cfg.VerboseGit = parseBool(value)
+	case "task_store":
+		if err := task.ValidateBackend(value); err != nil {
+			return err
+		}
+		cfg.TaskStore = value
 	default:
 		return fmt.Errorf("unknown configuration key: %s", key)
 	}
@@ -417,6 +532,26 @@ func setConfigValue(cfg *config.Config, key, value string) error {
 }
 
 func unsetConfigValue(cfg *config.Config, key string) error {
+	if host, field, ok := splitForgeKey(key); ok {
+		forgeCfg := cfg.Forge(host)
+		switch field {
+		case "kind":
+			forgeCfg.Kind = ""
+		case "token":
+			forgeCfg.Token = ""
+		case "api_url":
+			forgeCfg.APIURL = ""
+		default:
+			return fmt.Errorf("unknown configuration key: %s", key)
+		}
+		if forgeCfg == (config.ForgeConfig{}) {
+			delete(cfg.Forges, host)
+		} else {
+			cfg.Forges[host] = forgeCfg
+		}
+		return nil
+	}
+
 	key = strings.ReplaceAll(key, "-", "_")
 
 	// Set to default values
@@ -427,6 +562,8 @@ func unsetConfigValue(cfg *config.Config, key string) error {
 		cfg.DefaultAgent = defaults.DefaultAgent
 	case "branch_prefix":
 		cfg.BranchPrefix = defaults.BranchPrefix
+	case "branch_template":
+		cfg.BranchTemplate = defaults.BranchTemplate
 	case "worktree_dir":
 		cfg.WorktreeDir = defaults.WorktreeDir
 	case "rebase_default":
@@ -439,8 +576,16 @@ func unsetConfigValue(cfg *config.Config, key string) error {
 		cfg.RemoteName = defaults.RemoteName
 	case "lock_timeout":
 		cfg.LockTimeout = defaults.LockTimeout
+	case "fetch_timeout":
+		cfg.FetchTimeout = defaults.FetchTimeout
+	case "push_timeout":
+		cfg.PushTimeout = defaults.PushTimeout
+	case "rebase_timeout":
+		cfg.RebaseTimeout = defaults.RebaseTimeout
 	case "verbose_git":
 		cfg.VerboseGit = defaults.VerboseGit
+	case "task_store":
+		cfg.TaskStore = defaults.TaskStore
 	default:
 		return fmt.Errorf("unknown configuration key: %s", key)
 	}
@@ -448,6 +593,20 @@ func unsetConfigValue(cfg *config.Config, key string) error {
 	return nil
 }
 
+// splitForgeKey splits a per-host forge key, forges.<host>.<field>, into its
+// lowercased host and field
+func splitForgeKey(key string) (host, field string, ok bool) {
+	rest, ok := strings.CutPrefix(key, "forges.")
+	if !ok {
+		return "", "", false
+	}
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 {
+		return "", "", false
+	}
+	return strings.ToLower(rest[:i]), strings.ReplaceAll(rest[i+1:], "-", "_"), true
+}
+
 func parseBool(s string) bool {
 	s = strings.ToLower(strings.TrimSpace(s))
 	switch s {
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,10 +9,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/kernel-labs-ai/awt/internal/logger"
 	"github.com/kernel-labs-ai/awt/internal/repo"
-	"github.com/kernel-labs-ai/awt/internal/task"
 	"github.com/spf13/cobra"
 )
 
@@ -80,7 +81,29 @@ func runTaskCopy(opts *CopyOptions) error {
 		return errors.RepoNotFound(opts.RepoPath)
 	}
 
-	store := task.NewTaskStore(r.GitCommonDir)
+	store, err := openTaskStore(r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Hold the task lock so concurrent commands cannot mutate the task
+	taskLock, err := acquireTaskLock(context.Background(), r, cfg, opts.TaskID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
 
 	// Load task
 	t, err := store.Load(opts.TaskID)
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// DoctorTasksOptions contains options for the doctor tasks command
+type DoctorTasksOptions struct {
+	RepoPath   string
+	OutputJSON bool
+}
+
+// DoctorTasksResult represents the output of the doctor tasks command
+type DoctorTasksResult struct {
+	Store         string              `json:"store"`
+	SchemaVersion int                 `json:"schema_version"`
+	Total         int                 `json:"total"`
+	Migrated      int                 `json:"migrated"`
+	Failed        int                 `json:"failed"`
+	Records       []task.RecordStatus `json:"records"`
+}
+
+// NewDoctorCmd creates the doctor command
+func NewDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose AWT metadata",
+		Long:  `Commands for diagnosing problems with AWT metadata.`,
+	}
+
+	cmd.AddCommand(NewDoctorTasksCmd())
+
+	return cmd
+}
+
+// NewDoctorTasksCmd creates the doctor tasks command
+func NewDoctorTasksCmd() *cobra.Command {
+	opts := &DoctorTasksOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "Report task records that are outdated or cannot be loaded",
+		Long: `Check every task record in the task store.
+
+Records written by older AWT versions are migrated to the current schema when
+loaded, and rewritten at the current version the next time they are saved.
+Records that cannot be migrated or fail validation are skipped by 'awt list';
+this command lists them with the reason.
+
+Exits with an error if any record cannot be loaded.
+
+Example:
+  awt doctor tasks
+  awt doctor tasks --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctorTasks(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runDoctorTasks(opts *DoctorTasksOptions) error {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	records, err := store.Inspect()
+	if err != nil {
+		return err
+	}
+
+	result := DoctorTasksResult{
+		Store:         cfg.TaskStore,
+		SchemaVersion: task.CurrentSchemaVersion,
+		Total:         len(records),
+		Records:       records,
+	}
+	for _, rec := range records {
+		if rec.Error != "" {
+			result.Failed++
+		} else if rec.Migrated {
+			result.Migrated++
+		}
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printDoctorTasksResult(&result)
+	}
+
+	if result.Failed > 0 {
+		return fmt.Errorf("%d task record(s) cannot be loaded", result.Failed)
+	}
+
+	return nil
+}
+
+// printDoctorTasksResult prints a human-readable task store report
+func printDoctorTasksResult(result *DoctorTasksResult) {
+	fmt.Printf("Task store: %s (schema version %d)\n", result.Store, result.SchemaVersion)
+	fmt.Printf("Records: %d (%d ok, %d migrated from an older schema, %d unreadable)\n",
+		result.Total,
+		result.Total-result.Migrated-result.Failed,
+		result.Migrated,
+		result.Failed,
+	)
+
+	if result.Failed > 0 {
+		fmt.Printf("\nUnreadable records (hidden from 'awt list'):\n")
+		for _, rec := range result.Records {
+			if rec.Error == "" {
+				continue
+			}
+			fmt.Printf("  %s (schema version %d)\n", rec.ID, rec.SchemaVersion)
+			fmt.Printf("    source: %s\n", rec.Source)
+			fmt.Printf("    error:  %s\n", rec.Error)
+		}
+	}
+
+	if result.Migrated > 0 {
+		fmt.Printf("\nMigrated records (rewritten at the current version on next save):\n")
+		for _, rec := range result.Records {
+			if rec.Error == "" && rec.Migrated {
+				fmt.Printf("  %s (schema version %d)\n", rec.ID, rec.SchemaVersion)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunDoctorTasksReportsUnreadableRecords(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	tasksDir := filepath.Join(repoPath, ".git", "awt", "tasks")
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		t.Fatalf("failed to create tasks dir: %v", err)
+	}
+
+	// Healthy stores pass
+	if err := runDoctorTasks(&DoctorTasksOptions{RepoPath: repoPath, OutputJSON: true}); err != nil {
+		t.Fatalf("runDoctorTasks() on an empty store failed: %v", err)
+	}
+
+	// A record missing required fields is reported instead of hidden
+	broken := `{"id": "broken", "agent": "a", "title": "t", "state": "ACTIVE"}`
+	if err := os.WriteFile(filepath.Join(tasksDir, "broken.json"), []byte(broken), 0644); err != nil {
+		t.Fatalf("failed to write task file: %v", err)
+	}
+
+	if err := runDoctorTasks(&DoctorTasksOptions{RepoPath: repoPath, OutputJSON: true}); err == nil {
+		t.Error("expected an error reporting the unreadable record")
+	}
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -8,7 +9,6 @@ import (
 
 	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/kernel-labs-ai/awt/internal/repo"
-	"github.com/kernel-labs-ai/awt/internal/task"
 	"github.com/spf13/cobra"
 )
 
@@ -59,28 +59,36 @@ Example:
 }
 
 func runTaskEditor(opts *EditorOptions) error {
+	ctx := context.Background()
+
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
 		return errors.RepoNotFound(opts.RepoPath)
 	}
 
-	store := task.NewTaskStore(r.GitCommonDir)
+	store, err := openTaskStore(r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
 
 	// Determine task ID
 	taskID := opts.TaskID
 
 	if taskID == "" && opts.Branch != "" {
-		// Extract task ID from branch name
-		taskID = extractTaskIDFromBranch(opts.Branch)
+		// Look up the task that owns the branch
+		taskID = taskIDForBranch(store, opts.Branch)
 		if taskID == "" {
-			return fmt.Errorf("could not extract task ID from branch: %s", opts.Branch)
+			return fmt.Errorf("no task found for branch: %s", opts.Branch)
 		}
 	}
 
 	if taskID == "" {
 		// Try to infer from current worktree
-		taskID, err = inferTaskIDFromCurrentDirectory(r)
+		taskID, err = inferTaskIDFromCurrentDirectory(ctx, r)
 		if err != nil {
 			return fmt.Errorf("could not infer task ID: %w\nProvide task ID as argument or use --branch flag", err)
 		}
@@ -3,13 +3,17 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
+	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/lock"
 	"github.com/kernel-labs-ai/awt/internal/repo"
 	"github.com/kernel-labs-ai/awt/internal/task"
 	"github.com/spf13/cobra"
@@ -17,10 +21,22 @@ import (
 
 // ExecOptions contains options for the exec command
 type ExecOptions struct {
-	RepoPath string
-	TaskID   string
-	Branch   string
-	Command  []string
+	RepoPath  string
+	TaskID    string
+	Branch    string
+	Exclusive bool
+	Command   []string
+	// Stdin, Stdout and Stderr are connected to the command (nil means the null device)
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// ExecResult represents the outcome of a command run in a task's worktree
+type ExecResult struct {
+	TaskID       string `json:"task_id"`
+	WorktreePath string `json:"worktree_path"`
+	ExitCode     int    `json:"exit_code"`
 }
 
 // NewTaskExecCmd creates the task exec command
@@ -43,16 +59,21 @@ Commands are executed with:
   - Signals (SIGINT, SIGTERM) propagated to the child process
   - Exit code returned from the child process
 
+With --exclusive, the task lock is held while the command runs, so other
+mutating AWT commands on the task (commit, sync, handoff, ...) wait for it.
+
 Example:
   awt task exec 20250110-120000-abc123 -- make test
   awt task exec --branch=awt/claude/20250110-120000-abc123 -- git status
-  awt task exec -- ls -la  # infer from current directory`,
+  awt task exec -- ls -la  # infer from current directory
+  awt task exec 20250110-120000-abc123 --exclusive -- git rebase -i HEAD~3`,
 		DisableFlagParsing: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Parse flags manually since we disabled flag parsing
 			var taskID string
 			var branch string
 			var repoPath string
+			var exclusive bool
 			var cmdArgs []string
 
 			i := 0
@@ -78,6 +99,9 @@ Example:
 					}
 					repoPath = args[i+1]
 					i += 2
+				} else if arg == "--exclusive" {
+					exclusive = true
+					i++
 				} else if arg == "-h" || arg == "--help" {
 					_ = cmd.Help()
 					return nil
@@ -95,6 +119,7 @@ Example:
 			opts.TaskID = taskID
 			opts.Branch = branch
 			opts.RepoPath = repoPath
+			opts.Exclusive = exclusive
 			opts.Command = cmdArgs
 
 			return runTaskExec(opts)
@@ -103,77 +128,126 @@ Example:
 
 	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
 	cmd.Flags().StringVar(&opts.Branch, "branch", "", "branch name")
+	cmd.Flags().BoolVar(&opts.Exclusive, "exclusive", false, "hold the task lock while the command runs")
 
 	return cmd
 }
 
 func runTaskExec(opts *ExecOptions) error {
+	opts.Stdin = os.Stdin
+	opts.Stdout = os.Stdout
+	opts.Stderr = os.Stderr
+
+	result, err := cliClient(false).Exec(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Exit with child process exit code
+	if result.ExitCode != 0 {
+		os.Exit(result.ExitCode)
+	}
+
+	return nil
+}
+
+// Exec runs a command in a task's worktree and reports its exit code. A command that
+// exits non-zero is not an error; the exit code is returned in the result.
+func (c *Client) Exec(ctx context.Context, opts *ExecOptions) (*ExecResult, error) {
+	if len(opts.Command) == 0 {
+		return nil, fmt.Errorf("no command specified")
+	}
+
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
 	}
 
-	store := task.NewTaskStore(r.GitCommonDir)
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
 
 	// Determine task ID
 	taskID := opts.TaskID
 
 	if taskID == "" && opts.Branch != "" {
-		// Extract task ID from branch name
-		taskID = extractTaskIDFromBranch(opts.Branch)
+		// Look up the task that owns the branch
+		taskID = taskIDForBranch(store, opts.Branch)
 		if taskID == "" {
-			return fmt.Errorf("could not extract task ID from branch: %s", opts.Branch)
+			return nil, fmt.Errorf("no task found for branch: %s", opts.Branch)
 		}
 	}
 
 	if taskID == "" {
 		// Try to infer from current worktree
-		taskID, err = inferTaskIDFromCurrentDirectory(r)
+		taskID, err = inferTaskIDFromCurrentDirectory(ctx, r)
 		if err != nil {
-			return fmt.Errorf("could not infer task ID: %w\nProvide task ID as argument or use --branch flag", err)
+			return nil, fmt.Errorf("could not infer task ID: %w\nProvide task ID as argument or use --branch flag", err)
 		}
 	}
 
+	// Hold the task lock for the duration of the command if requested
+	var taskLock *lock.Lock
+	if opts.Exclusive {
+		configLoader := config.NewConfigLoader(r.GitCommonDir)
+		cfg, err := configLoader.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+
+		taskLock, err = acquireTaskLock(ctx, r, cfg, taskID)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = taskLock.Release()
+		}()
+	}
+
 	// Load task
 	t, err := store.Load(taskID)
 	if err != nil {
-		return errors.InvalidTaskID(taskID)
+		return nil, errors.InvalidTaskID(taskID)
 	}
 
 	// Verify worktree exists
-	if _, err := os.Stat(t.WorktreePath); os.IsNotExist(err) {
-		return errors.WorktreeNotFound(t.WorktreePath)
+	if err := requireWorktree(t); err != nil {
+		return nil, err
 	}
 
 	// Resolve worktree path to absolute path
 	worktreePathAbs, err := filepath.Abs(t.WorktreePath)
 	if err != nil {
-		return fmt.Errorf("failed to resolve worktree path: %w", err)
+		return nil, fmt.Errorf("failed to resolve worktree path: %w", err)
 	}
 
 	// Execute command in worktree
-	exitCode, err := executeCommand(worktreePathAbs, opts.Command)
+	exitCode, err := executeCommand(ctx, worktreePathAbs, opts.Command, opts.Stdin, opts.Stdout, opts.Stderr)
 	if err != nil {
-		return fmt.Errorf("failed to execute command: %w", err)
+		return nil, fmt.Errorf("failed to execute command: %w", err)
 	}
+	recordEvent(r, taskID, task.Event{Type: task.EventExec, Command: strings.Join(opts.Command, " "), ExitCode: &exitCode})
 
-	// Exit with child process exit code
-	if exitCode != 0 {
-		os.Exit(exitCode)
-	}
-
-	return nil
+	return &ExecResult{
+		TaskID:       taskID,
+		WorktreePath: worktreePathAbs,
+		ExitCode:     exitCode,
+	}, nil
 }
 
 // executeCommand executes a command in the specified directory with signal handling
-func executeCommand(workDir string, cmdArgs []string) (int, error) {
+func executeCommand(ctx context.Context, workDir string, cmdArgs []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
 	// Create command
-	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd := exec.CommandContext(ctx, cmdArgs[0], cmdArgs[1:]...)
 	cmd.Dir = workDir
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	// Setup signal handling
 	sigChan := make(chan os.Signal, 1)
@@ -185,7 +259,7 @@ func executeCommand(workDir string, cmdArgs []string) (int, error) {
 	}
 
 	// Context for cleanup
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// Handle signals in goroutine
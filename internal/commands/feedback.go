@@ -0,0 +1,507 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/forge"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// Review feedback files written into the root of the task worktree
+const (
+	FeedbackMarkdownFile = "REVIEW_FEEDBACK.md"
+	FeedbackJSONFile     = "REVIEW_FEEDBACK.json"
+)
+
+// FeedbackOptions contains options for the task feedback command
+type FeedbackOptions struct {
+	RepoPath        string
+	TaskID          string
+	Branch          string
+	IncludeResolved bool
+	OutputJSON      bool
+}
+
+// FeedbackResult represents the output of the task feedback command
+type FeedbackResult struct {
+	TaskID         string            `json:"task_id"`
+	PRNumber       int               `json:"pr_number"`
+	PRURL          string            `json:"pr_url"`
+	ReviewDecision forge.ReviewState `json:"review_decision,omitempty"`
+	Threads        int               `json:"threads"`
+	Comments       int               `json:"comments"`
+	State          string            `json:"state"`
+	WorktreePath   string            `json:"worktree_path"`
+	// Reopened is set when the task was moved from HANDOFF_READY back to ACTIVE
+	Reopened     bool   `json:"reopened"`
+	MarkdownPath string `json:"markdown_path"`
+	JSONPath     string `json:"json_path"`
+}
+
+// ReviewFeedback is the content of FeedbackJSONFile
+type ReviewFeedback struct {
+	TaskID         string            `json:"task_id"`
+	PRNumber       int               `json:"pr_number"`
+	PRURL          string            `json:"pr_url"`
+	Title          string            `json:"title"`
+	ReviewDecision forge.ReviewState `json:"review_decision,omitempty"`
+	FetchedAt      time.Time         `json:"fetched_at"`
+	// Reviews are the reviews with a verdict or a message
+	Reviews []forge.Review `json:"reviews"`
+	// Threads are the comment threads, those on files first by path and line
+	Threads []ReviewThread `json:"threads"`
+}
+
+// ReviewThread is a comment thread on the pull request
+type ReviewThread struct {
+	ID string `json:"id"`
+	// Path and Line locate threads on the diff; Line is in the new version of the file
+	Path string `json:"path,omitempty"`
+	Line int    `json:"line,omitempty"`
+	// Anchor is path:line (or just the path for a comment on the whole file), empty
+	// for general comments
+	Anchor   string          `json:"anchor,omitempty"`
+	Resolved bool            `json:"resolved,omitempty"`
+	Comments []forge.Comment `json:"comments"`
+}
+
+// NewTaskFeedbackCmd creates the task feedback command
+func NewTaskFeedbackCmd() *cobra.Command {
+	opts := &FeedbackOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "feedback [task-id]",
+		Short: "Write PR review feedback into the task worktree",
+		Long: `Fetch the reviews and comment threads of a task's pull request through the
+forge API and write them into the root of the task worktree:
+
+  REVIEW_FEEDBACK.md    the feedback for the agent to read
+  REVIEW_FEEDBACK.json  the same threads with path:line anchors, for tools
+
+'awt task commit --all' leaves both files out of the commit.
+A task in HANDOFF_READY is moved back to ACTIVE so the agent can address the
+feedback; its worktree is recreated from the task branch if handoff removed it.
+Resolved threads are left out unless --include-resolved is given.
+
+The task can be specified by:
+  1. Providing the task ID as an argument
+  2. Using --branch flag
+  3. Inferring from current worktree (if in a worktree)
+
+Example:
+  awt task feedback 20250110-120000-abc123
+  awt task feedback --include-resolved --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.TaskID = args[0]
+			}
+			return runTaskFeedback(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.Branch, "branch", "", "branch name")
+	cmd.Flags().BoolVar(&opts.IncludeResolved, "include-resolved", false, "include resolved threads")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runTaskFeedback(opts *FeedbackOptions) error {
+	result, err := cliClient(opts.OutputJSON).Feedback(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Review feedback written for PR #%d\n", result.PRNumber)
+		fmt.Printf("  Task: %s\n", result.TaskID)
+		if result.ReviewDecision != "" {
+			fmt.Printf("  Review: %s\n", result.ReviewDecision)
+		}
+		fmt.Printf("  Threads: %d (%d comments)\n", result.Threads, result.Comments)
+		fmt.Printf("  Feedback: %s\n", result.MarkdownPath)
+		fmt.Printf("  Worktree: %s\n", result.WorktreePath)
+		if result.Reopened {
+			fmt.Printf("  State: %s (reopened)\n", result.State)
+		} else {
+			fmt.Printf("  State: %s\n", result.State)
+		}
+	}
+
+	return nil
+}
+
+// Feedback writes the review feedback of the task's pull request into its worktree,
+// moving a handed-off task back to ACTIVE and recreating its worktree if needed
+func (c *Client) Feedback(ctx context.Context, opts *FeedbackOptions) (*FeedbackResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	// Determine task ID
+	taskID, err := resolveTaskID(ctx, r, store, opts.TaskID, opts.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load task
+	t, err := store.Load(taskID)
+	if err != nil {
+		return nil, errors.InvalidTaskID(taskID)
+	}
+	if t.State != task.StateHandoffReady && t.State != task.StateActive {
+		return nil, errors.InvalidTaskState(t.ID, string(t.State), "fetch review feedback for")
+	}
+	number := taskPRNumber(t)
+	if number == 0 {
+		return nil, errors.NoPullRequest(t.ID)
+	}
+
+	// Fetch everything before touching the task, so a forge error changes nothing
+	c.progressf("Fetching review feedback for PR #%d...\n", number)
+	g := git.New(r.WorkTreeRoot, cfg.VerboseGit).WithTimeouts(gitTimeouts(cfg))
+	f, err := openForge(ctx, g, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query the forge: %w", err)
+	}
+	feedback, err := fetchReviewFeedback(ctx, f, number, opts.IncludeResolved)
+	if err != nil {
+		return nil, err
+	}
+	feedback.TaskID = t.ID
+
+	// Hold the task lock so concurrent commands cannot mutate the task
+	taskLock, err := acquireTaskLock(ctx, r, cfg, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
+
+	// Reload the task now that it is locked, in case it changed while waiting
+	if fresh, err := store.Load(t.ID); err == nil {
+		*t = *fresh
+	}
+
+	reopened := false
+	if t.State == task.StateHandoffReady || !worktreeExists(t) {
+		if t.State == task.StateHandoffReady {
+			c.progressf("Reopening task %s...\n", t.ID)
+			if !task.CanTransition(t.State, task.StateActive) {
+				return nil, errors.InvalidTransition(t.ID, string(t.State), string(task.StateActive))
+			}
+			reopened = true
+		}
+
+		// Record the pre-operation state so the command can be undone
+		if err := recordOperation(ctx, r, "task feedback", t, false); err != nil {
+			return nil, err
+		}
+		if err := c.reactivateWorktree(ctx, r, cfg, t); err != nil {
+			return nil, err
+		}
+
+		worktreePath := t.WorktreePath
+		updated, err := task.Update(store, taskLock, t.ID, func(t *task.Task) error {
+			t.WorktreePath = worktreePath
+			if t.State == task.StateActive {
+				return nil
+			}
+			return t.TransitionTo(task.StateActive, currentActor(), "task feedback")
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update task metadata: %w", err)
+		}
+		*t = *updated
+	}
+
+	// Write the feedback files; 'awt task commit --all' leaves them out
+	markdownPath := filepath.Join(t.WorktreePath, FeedbackMarkdownFile)
+	if err := os.WriteFile(markdownPath, []byte(renderReviewFeedback(feedback)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", FeedbackMarkdownFile, err)
+	}
+	data, err := json.MarshalIndent(feedback, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode review feedback: %w", err)
+	}
+	jsonPath := filepath.Join(t.WorktreePath, FeedbackJSONFile)
+	if err := os.WriteFile(jsonPath, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", FeedbackJSONFile, err)
+	}
+
+	comments := 0
+	for _, thread := range feedback.Threads {
+		comments += len(thread.Comments)
+	}
+	recordEvent(r, t.ID, task.Event{
+		Type:    task.EventFeedback,
+		URL:     feedback.PRURL,
+		Message: fmt.Sprintf("%d threads from PR #%d", len(feedback.Threads), number),
+	})
+
+	return &FeedbackResult{
+		TaskID:         t.ID,
+		PRNumber:       number,
+		PRURL:          feedback.PRURL,
+		ReviewDecision: feedback.ReviewDecision,
+		Threads:        len(feedback.Threads),
+		Comments:       comments,
+		State:          string(t.State),
+		WorktreePath:   t.WorktreePath,
+		Reopened:       reopened,
+		MarkdownPath:   markdownPath,
+		JSONPath:       jsonPath,
+	}, nil
+}
+
+// worktreeExists reports whether the task's worktree is still on disk
+func worktreeExists(t *task.Task) bool {
+	if t.WorktreePath == "" {
+		return false
+	}
+	_, err := os.Stat(t.WorktreePath)
+	return err == nil
+}
+
+// requireWorktree returns a WORKTREE_NOT_FOUND error unless the task's worktree is
+// on disk. Call it before running git in the worktree: git with an empty path runs
+// in the current directory instead.
+func requireWorktree(t *task.Task) error {
+	if worktreeExists(t) {
+		return nil
+	}
+	if t.WorktreePath == "" {
+		return errors.WorktreeNotFound(fmt.Sprintf("(task %s has no worktree)", t.ID))
+	}
+	return errors.WorktreeNotFound(t.WorktreePath)
+}
+
+// reactivateWorktree makes the task's worktree usable again: it is recreated if
+// handoff removed it, and switched back to the task branch if handoff left it
+// detached. The task's WorktreePath is updated but not saved.
+func (c *Client) reactivateWorktree(ctx context.Context, r *repo.Repo, cfg *config.Config, t *task.Task) error {
+	if !worktreeExists(t) {
+		c.progressf("Recreating worktree...\n")
+
+		// Acquire global lock for worktree creation
+		lm := newLockManager(r, cfg)
+		globalLock, err := lm.AcquireGlobal(ctx)
+		if err != nil {
+			return errors.LockTimeout("global")
+		}
+		defer func() {
+			_ = globalLock.Release()
+		}()
+
+		return recreateTaskWorktree(ctx, r, cfg, t)
+	}
+
+	wtGit := git.New(t.WorktreePath, cfg.VerboseGit)
+	branchName := strings.TrimPrefix(t.Branch, "refs/heads/")
+	if current, _ := wtGit.CurrentBranch(ctx); current == branchName {
+		return nil
+	}
+	switchResult, err := wtGit.Switch(ctx, branchName, false)
+	if err != nil || switchResult.ExitCode != 0 {
+		stderr := ""
+		if switchResult != nil {
+			stderr = switchResult.Stderr
+		}
+		return fmt.Errorf("failed to switch worktree to %s: %s", branchName, stderr)
+	}
+	return nil
+}
+
+// fetchReviewFeedback queries the forge for the pull request's reviews and comment threads
+func fetchReviewFeedback(ctx context.Context, f forge.Forge, number int, includeResolved bool) (*ReviewFeedback, error) {
+	pr, err := f.GetPR(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR #%d: %w", number, err)
+	}
+	reviews, err := f.ListReviews(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews of PR #%d: %w", number, err)
+	}
+	comments, err := f.ListComments(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments of PR #%d: %w", number, err)
+	}
+
+	feedback := &ReviewFeedback{
+		PRNumber:       pr.Number,
+		PRURL:          pr.URL,
+		Title:          pr.Title,
+		ReviewDecision: forge.ReviewDecision(reviews),
+		FetchedAt:      time.Now(),
+		Reviews:        []forge.Review{},
+		Threads:        buildReviewThreads(comments, includeResolved),
+	}
+	for _, review := range reviews {
+		if review.Body != "" || review.State == forge.ReviewApproved || review.State == forge.ReviewChangesRequested {
+			feedback.Reviews = append(feedback.Reviews, review)
+		}
+	}
+	return feedback, nil
+}
+
+// buildReviewThreads groups comments into threads. Threads on files come first,
+// ordered by path and line, then general comments in the order they were made.
+func buildReviewThreads(comments []forge.Comment, includeResolved bool) []ReviewThread {
+	threads := []ReviewThread{}
+	index := make(map[string]int)
+	for _, c := range comments {
+		key := c.Path + "\x00" + c.ThreadID
+		i, ok := index[key]
+		if !ok {
+			i = len(threads)
+			index[key] = i
+			thread := ReviewThread{
+				ID:       c.ThreadID,
+				Path:     c.Path,
+				Line:     c.Line,
+				Resolved: c.Resolved,
+				Comments: []forge.Comment{},
+			}
+			if c.Path != "" {
+				thread.Anchor = c.Path
+				if c.Line > 0 {
+					thread.Anchor = fmt.Sprintf("%s:%d", c.Path, c.Line)
+				}
+			}
+			threads = append(threads, thread)
+		}
+		threads[i].Comments = append(threads[i].Comments, c)
+	}
+
+	if !includeResolved {
+		open := threads[:0]
+		for _, thread := range threads {
+			if !thread.Resolved {
+				open = append(open, thread)
+			}
+		}
+		threads = open
+	}
+
+	sort.SliceStable(threads, func(i, j int) bool {
+		a, b := threads[i], threads[j]
+		if (a.Path == "") != (b.Path == "") {
+			return a.Path != ""
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Path != "" {
+			return a.Line < b.Line
+		}
+		return a.Comments[0].CreatedAt.Before(b.Comments[0].CreatedAt)
+	})
+	return threads
+}
+
+// renderReviewFeedback renders the feedback as Markdown for the agent
+func renderReviewFeedback(fb *ReviewFeedback) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Review feedback: PR #%d\n\n", fb.PRNumber)
+	if fb.Title != "" {
+		fmt.Fprintf(&b, "%s\n\n", fb.Title)
+	}
+	if fb.PRURL != "" {
+		fmt.Fprintf(&b, "PR: %s\n", fb.PRURL)
+	}
+	decision := "none yet"
+	switch fb.ReviewDecision {
+	case forge.ReviewApproved:
+		decision = "approved"
+	case forge.ReviewChangesRequested:
+		decision = "changes requested"
+	}
+	fmt.Fprintf(&b, "Review decision: %s\n", decision)
+	fmt.Fprintf(&b, "Fetched: %s\n", fb.FetchedAt.Format("2006-01-02 15:04:05"))
+
+	if len(fb.Reviews) > 0 {
+		b.WriteString("\n## Reviews\n")
+		for _, review := range fb.Reviews {
+			fmt.Fprintf(&b, "\n### %s (%s)\n", review.Author, strings.ReplaceAll(string(review.State), "_", " "))
+			if review.Body != "" {
+				fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(review.Body))
+			}
+		}
+	}
+
+	var fileThreads, generalThreads []ReviewThread
+	for _, thread := range fb.Threads {
+		if thread.Path != "" {
+			fileThreads = append(fileThreads, thread)
+		} else {
+			generalThreads = append(generalThreads, thread)
+		}
+	}
+
+	if len(fileThreads) > 0 {
+		b.WriteString("\n## Comments on files\n")
+		for _, thread := range fileThreads {
+			fmt.Fprintf(&b, "\n### `%s`", thread.Anchor)
+			if thread.Resolved {
+				b.WriteString(" (resolved)")
+			}
+			b.WriteString("\n")
+			writeFeedbackComments(&b, thread.Comments)
+		}
+	}
+	if len(generalThreads) > 0 {
+		b.WriteString("\n## General comments\n")
+		for _, thread := range generalThreads {
+			writeFeedbackComments(&b, thread.Comments)
+		}
+	}
+	if len(fb.Threads) == 0 {
+		b.WriteString("\nNo open comment threads.\n")
+	}
+
+	return b.String()
+}
+
+// writeFeedbackComments writes the comments of a thread, one paragraph each
+func writeFeedbackComments(b *strings.Builder, comments []forge.Comment) {
+	for _, c := range comments {
+		fmt.Fprintf(b, "\n**%s**:\n\n%s\n", c.Author, strings.TrimSpace(c.Body))
+	}
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/forge"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestBuildReviewThreads(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2025, 1, 10, 12, minute, 0, 0, time.UTC) }
+	comments := []forge.Comment{
+		{ID: "1", ThreadID: "1", Author: "alice", Body: "Please explain", CreatedAt: at(1)},
+		{ID: "2", ThreadID: "2", Author: "alice", Body: "Off by one", Path: "b.go", Line: 20, CreatedAt: at(2)},
+		{ID: "3", ThreadID: "3", Author: "bob", Body: "Rename this", Path: "b.go", Line: 4, CreatedAt: at(3)},
+		{ID: "4", ThreadID: "2", Author: "agent", Body: "Fixed", Path: "b.go", Line: 20, CreatedAt: at(4)},
+		{ID: "5", ThreadID: "5", Author: "bob", Body: "Done already", Path: "a.go", Line: 1, Resolved: true, CreatedAt: at(5)},
+		{ID: "6", ThreadID: "6", Author: "bob", Body: "Why a new file?", Path: "c.go", CreatedAt: at(6)},
+	}
+
+	threads := buildReviewThreads(comments, false)
+	var anchors []string
+	for _, thread := range threads {
+		anchors = append(anchors, thread.Anchor)
+	}
+	if got := strings.Join(anchors, ","); got != "b.go:4,b.go:20,c.go," {
+		t.Errorf("anchors = %q, want %q", got, "b.go:4,b.go:20,c.go,")
+	}
+	if len(threads[1].Comments) != 2 || threads[1].Comments[1].Body != "Fixed" {
+		t.Errorf("b.go:20 comments = %+v, want the comment and its reply", threads[1].Comments)
+	}
+
+	threads = buildReviewThreads(comments, true)
+	if len(threads) != 5 || threads[0].Anchor != "a.go:1" || !threads[0].Resolved {
+		t.Errorf("threads with resolved = %+v, want 5 starting with a.go:1", threads)
+	}
+}
+
+func TestRenderReviewFeedback(t *testing.T) {
+	fb := &ReviewFeedback{
+		PRNumber:       42,
+		PRURL:          "https://github.com/owner/repo/pull/42",
+		Title:          "Add feature",
+		ReviewDecision: forge.ReviewChangesRequested,
+		Reviews:        []forge.Review{{Author: "alice", State: forge.ReviewChangesRequested, Body: "A few things."}},
+		Threads: []ReviewThread{
+			{ID: "2", Path: "b.go", Line: 20, Anchor: "b.go:20", Comments: []forge.Comment{{Author: "alice", Body: "Off by one"}}},
+			{ID: "1", Comments: []forge.Comment{{Author: "bob", Body: "Looks good otherwise"}}},
+		},
+	}
+
+	out := renderReviewFeedback(fb)
+	for _, want := range []string{
+		"# Review feedback: PR #42",
+		"Review decision: changes requested",
+		"### alice (changes requested)\n\nA few things.",
+		"## Comments on files\n\n### `b.go:20`\n\n**alice**:\n\nOff by one",
+		"## General comments\n\n**bob**:\n\nLooks good otherwise",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("feedback does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestClientFeedback(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/42", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"number": 42, "html_url": "https://github.com/owner/repo/pull/42", "title": "Add feature",
+			"state": "open", "head": {"ref": "awt/test-agent/feedback-task", "sha": "abc123"}, "base": {"ref": "main"}}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/42/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 1, "user": {"login": "alice"}, "state": "CHANGES_REQUESTED", "body": "A few things."}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/42/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 10, "user": {"login": "alice"}, "body": "Off by one", "path": "main.go", "line": 3}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/42/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 11, "user": {"login": "bob"}, "body": "Also update the docs"}]`))
+	})
+	repoPath := newTestForgeRepo(t, mux)
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Add feature",
+		Base:         "HEAD",
+		ID:           "feedback-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	// Hand the task off by hand: the PR is open and the worktree was removed
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	started, err := store.Load("feedback-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if out, err := exec.Command("git", "-C", repoPath, "worktree", "remove", "--force", started.WorktreePath).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree remove failed: %v\n%s", err, out)
+	}
+	started.State = task.StateHandoffReady
+	started.PRNumber = 42
+	started.PRURL = "https://github.com/owner/repo/pull/42"
+	if err := store.Save(started); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+
+	result, err := (&Client{}).Feedback(context.Background(), &FeedbackOptions{RepoPath: repoPath, TaskID: "feedback-task"})
+	if err != nil {
+		t.Fatalf("Feedback() failed: %v", err)
+	}
+	if !result.Reopened || result.State != string(task.StateActive) {
+		t.Errorf("result = %+v, want the task reopened", result)
+	}
+	if result.Threads != 2 || result.ReviewDecision != forge.ReviewChangesRequested {
+		t.Errorf("result = %+v, want 2 threads with changes requested", result)
+	}
+
+	tk, err := store.Load("feedback-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if tk.State != task.StateActive {
+		t.Errorf("state = %s, want %s", tk.State, task.StateActive)
+	}
+	if _, err := os.Stat(tk.WorktreePath); err != nil {
+		t.Fatalf("worktree was not recreated at %s", tk.WorktreePath)
+	}
+
+	markdown, err := os.ReadFile(filepath.Join(tk.WorktreePath, FeedbackMarkdownFile))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", FeedbackMarkdownFile, err)
+	}
+	if !strings.Contains(string(markdown), "### `main.go:3`") || !strings.Contains(string(markdown), "Also update the docs") {
+		t.Errorf("unexpected feedback:\n%s", markdown)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tk.WorktreePath, FeedbackJSONFile))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", FeedbackJSONFile, err)
+	}
+	var fb ReviewFeedback
+	if err := json.Unmarshal(data, &fb); err != nil {
+		t.Fatalf("invalid %s: %v", FeedbackJSONFile, err)
+	}
+	if fb.TaskID != "feedback-task" || len(fb.Threads) != 2 || fb.Threads[0].Anchor != "main.go:3" {
+		t.Errorf("unexpected feedback JSON: %+v", fb)
+	}
+
+	// Fetching again keeps the task active and rewrites the files
+	result, err = (&Client{}).Feedback(context.Background(), &FeedbackOptions{RepoPath: repoPath, TaskID: "feedback-task"})
+	if err != nil {
+		t.Fatalf("second Feedback() failed: %v", err)
+	}
+	if result.Reopened || result.WorktreePath != tk.WorktreePath {
+		t.Errorf("second result = %+v, want the same worktree without reopening", result)
+	}
+
+	// Committing everything leaves the feedback files out
+	if err := os.WriteFile(filepath.Join(tk.WorktreePath, "docs.md"), []byte("Docs\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := runTaskCommit(&CommitOptions{RepoPath: repoPath, TaskID: "feedback-task", Message: "Update docs", All: true, OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskCommit() failed: %v", err)
+	}
+	if files := gitOutput(t, tk.WorktreePath, "show", "--name-only", "--format=", "HEAD"); files != "docs.md" {
+		t.Errorf("committed files = %q, want only docs.md", files)
+	}
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/forge"
+	"github.com/kernel-labs-ai/awt/internal/git"
+)
+
+// forgeOptions describes the forge hosting the configured remote. The kind comes
+// from the host's forge config and is only guessed from the host name if it is
+// not set there; it is empty if neither knows the host.
+func forgeOptions(ctx context.Context, g *git.Git, cfg *config.Config) (forge.Options, error) {
+	info, err := g.Remote(ctx, cfg.RemoteName)
+	if err != nil {
+		return forge.Options{}, err
+	}
+
+	hostCfg := cfg.Forge(info.Host)
+	kind := forge.DetectKind(info.Host)
+	if hostCfg.Kind != "" {
+		kind, err = forge.ParseKind(hostCfg.Kind)
+		if err != nil {
+			return forge.Options{}, fmt.Errorf("invalid forge config for %s: %w", info.Host, err)
+		}
+	}
+
+	return forge.Options{
+		Kind:   kind,
+		Host:   info.Host,
+		Scheme: info.Scheme,
+		Owner:  info.Owner,
+		Repo:   info.Repo,
+		APIURL: hostCfg.APIURL,
+	}, nil
+}
+
+// openForge returns the forge API client for the configured remote. The token
+// comes from the host's forge config, or else from the forge's environment
+// variables; forge.ErrNoToken is returned if there is none.
+func openForge(ctx context.Context, g *git.Git, cfg *config.Config) (forge.Forge, error) {
+	opts, err := forgeOptions(ctx, g, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Kind == "" {
+		return nil, fmt.Errorf("unknown forge for host %s (set forges.%s.kind)", opts.Host, opts.Host)
+	}
+
+	opts.Token = cfg.Forge(opts.Host).Token
+	if opts.Token == "" {
+		opts.Token = forge.TokenFromEnv(opts.Kind)
+	}
+	return forge.New(opts)
+}
+
+// forgeCompareURL returns the web page for opening a pull request from head into
+// base on the configured remote's forge
+func forgeCompareURL(ctx context.Context, g *git.Git, cfg *config.Config, head, base string) (string, error) {
+	opts, err := forgeOptions(ctx, g, cfg)
+	if err != nil {
+		return "", err
+	}
+	return forge.CompareURL(opts, head, base), nil
+}
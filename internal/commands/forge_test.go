@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/forge"
+	"github.com/kernel-labs-ai/awt/internal/git"
+)
+
+func TestOpenForge(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	if out, err := exec.Command("git", "-C", repoPath, "remote", "add", "origin", "git@github.com:owner/repo.git").CombinedOutput(); err != nil {
+		t.Fatalf("git remote add failed: %v\n%s", err, out)
+	}
+
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/repos/owner/repo/pulls/5" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"number": 5, "state": "open"}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	g := git.New(repoPath, false)
+	cfg := config.Default()
+
+	if _, err := openForge(ctx, g, cfg); !errors.Is(err, forge.ErrNoToken) {
+		t.Fatalf("openForge() without a token: error = %v, want ErrNoToken", err)
+	}
+
+	// The token and API URL come from the config of the remote's host
+	cfg.Forges = map[string]config.ForgeConfig{"github.com": {Token: "config-token", APIURL: server.URL}}
+	f, err := openForge(ctx, g, cfg)
+	if err != nil {
+		t.Fatalf("openForge() failed: %v", err)
+	}
+	if f.Kind() != forge.KindGitHub {
+		t.Errorf("Kind() = %q, want %q", f.Kind(), forge.KindGitHub)
+	}
+	if _, err := f.GetPR(ctx, 5); err != nil {
+		t.Fatalf("GetPR() failed: %v", err)
+	}
+	if gotAuth != "Bearer config-token" {
+		t.Errorf("Authorization = %q, want the configured token", gotAuth)
+	}
+
+	// Without a configured token the environment is used
+	cfg.Forges["github.com"] = config.ForgeConfig{APIURL: server.URL}
+	t.Setenv("GH_TOKEN", "env-token")
+	f, err = openForge(ctx, g, cfg)
+	if err != nil {
+		t.Fatalf("openForge() with GH_TOKEN failed: %v", err)
+	}
+	if _, err := f.GetPR(ctx, 5); err != nil {
+		t.Fatalf("GetPR() failed: %v", err)
+	}
+	if gotAuth != "Bearer env-token" {
+		t.Errorf("Authorization = %q, want the token from GH_TOKEN", gotAuth)
+	}
+}
+
+func TestForgeCompareURL(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	if out, err := exec.Command("git", "-C", repoPath, "remote", "add", "origin", "git@github.com:owner/repo.git").CombinedOutput(); err != nil {
+		t.Fatalf("git remote add failed: %v\n%s", err, out)
+	}
+
+	ctx := context.Background()
+	g := git.New(repoPath, false)
+	cfg := config.Default()
+	cfg.Forges = map[string]config.ForgeConfig{
+		"git.example.com":       {Kind: "forgejo"},
+		"bitbucket.example.com": {Kind: "bitbucket-server"},
+	}
+
+	tests := []struct {
+		name      string
+		remoteURL string
+		want      string
+	}{
+		{
+			name:      "GitHub",
+			remoteURL: "git@github.com:owner/repo.git",
+			want:      "https://github.com/owner/repo/compare/main...feature?expand=1",
+		},
+		{
+			name:      "GitLab",
+			remoteURL: "git@gitlab.com:group/project.git",
+			want:      "https://gitlab.com/group/project/-/merge_requests/new?merge_request[source_branch]=feature&merge_request[target_branch]=main",
+		},
+		{
+			name:      "configured Forgejo",
+			remoteURL: "https://git.example.com/owner/repo.git",
+			want:      "https://git.example.com/owner/repo/compare/main...feature",
+		},
+		{
+			name:      "configured Bitbucket Server",
+			remoteURL: "ssh://git@bitbucket.example.com:7999/proj/repo.git",
+			want:      "https://bitbucket.example.com/projects/PROJ/repos/repo/pull-requests?create&sourceBranch=refs%2Fheads%2Ffeature&targetBranch=refs%2Fheads%2Fmain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if out, err := exec.Command("git", "-C", repoPath, "remote", "set-url", "origin", tt.remoteURL).CombinedOutput(); err != nil {
+				t.Fatalf("git remote set-url failed: %v\n%s", err, out)
+			}
+
+			got, err := forgeCompareURL(ctx, g, cfg, "feature", "main")
+			if err != nil {
+				t.Fatalf("forgeCompareURL() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("forgeCompareURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// A host with no configured kind has no API client
+	_ = exec.Command("git", "-C", repoPath, "remote", "set-url", "origin", "https://code.example.org/owner/repo.git").Run()
+	if _, err := openForge(ctx, g, cfg); err == nil {
+		t.Error("openForge() for an unknown host should fail")
+	}
+}
@@ -0,0 +1,441 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/lock"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// GroupStatusOptions contains options for the group status command
+type GroupStatusOptions struct {
+	RepoPath   string
+	Group      string
+	OutputJSON bool
+}
+
+// GroupAttempt represents one agent's attempt within a task group
+type GroupAttempt struct {
+	TaskID       string `json:"task_id"`
+	Agent        string `json:"agent"`
+	State        string `json:"state"`
+	Branch       string `json:"branch"`
+	Commits      int    `json:"commits"`
+	FilesChanged int    `json:"files_changed"`
+	Insertions   int    `json:"insertions"`
+	Deletions    int    `json:"deletions"`
+	LastCommit   string `json:"last_commit,omitempty"`
+	WorktreePath string `json:"worktree_path,omitempty"`
+	PRURL        string `json:"pr_url,omitempty"`
+}
+
+// GroupStatusResult represents the output of the group status command
+type GroupStatusResult struct {
+	Group    string         `json:"group"`
+	Title    string         `json:"title"`
+	Base     string         `json:"base"`
+	Attempts []GroupAttempt `json:"attempts"`
+}
+
+// GroupPickOptions contains options for the group pick command
+type GroupPickOptions struct {
+	RepoPath     string
+	Group        string
+	TaskID       string
+	NoPush       bool
+	NoPR         bool
+	KeepWorktree bool
+	DeleteBranch bool
+	DeleteRemote bool
+	Force        bool
+	ForceRemove  bool
+	OutputJSON   bool
+}
+
+// GroupPickResult represents the output of the group pick command
+type GroupPickResult struct {
+	Group     string          `json:"group"`
+	Winner    *HandoffResult  `json:"winner"`
+	Abandoned []AbandonResult `json:"abandoned"`
+}
+
+// NewGroupCmd creates the group command
+func NewGroupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "group",
+		Short: "Manage task groups",
+		Long: `Commands for managing task groups.
+
+A task group is created by 'awt task start --agents=a,b,c': the same task is
+fanned out to several agents, each with its own branch and worktree.`,
+	}
+
+	cmd.AddCommand(NewGroupStatusCmd())
+	cmd.AddCommand(NewGroupPickCmd())
+
+	return cmd
+}
+
+// NewGroupStatusCmd creates the group status command
+func NewGroupStatusCmd() *cobra.Command {
+	opts := &GroupStatusOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "status <group>",
+		Short: "Show the attempts of a task group side by side",
+		Long: `Show each attempt in a task group side by side.
+
+For every task in the group, shows its state, the number of commits on its
+branch and a diffstat against the merge-base with the base branch.
+
+Example:
+  awt group status 20250110-120000-abc123
+  awt group status 20250110-120000-abc123 --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Group = args[0]
+			return runGroupStatus(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runGroupStatus(opts *GroupStatusOptions) error {
+	result, err := cliClient(opts.OutputJSON).GroupStatus(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Group: %s\n", result.Group)
+		fmt.Printf("  Title: %s\n", result.Title)
+		fmt.Printf("  Base: %s\n\n", result.Base)
+
+		fmt.Printf("%-12s %-30s %-15s %8s %6s %8s %8s\n", "AGENT", "TASK", "STATE", "COMMITS", "FILES", "+", "-")
+		fmt.Println(strings.Repeat("-", 93))
+		for _, a := range result.Attempts {
+			fmt.Printf("%-12s %-30s %-15s %8d %6d %8d %8d\n",
+				a.Agent,
+				a.TaskID,
+				a.State,
+				a.Commits,
+				a.FilesChanged,
+				a.Insertions,
+				a.Deletions,
+			)
+		}
+	}
+
+	return nil
+}
+
+// GroupStatus compares the attempts of a task group
+func (c *Client) GroupStatus(ctx context.Context, opts *GroupStatusOptions) (*GroupStatusResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	tasks, err := loadGroupTasks(store, opts.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	g := git.New(r.WorkTreeRoot, false)
+
+	result := GroupStatusResult{
+		Group:    opts.Group,
+		Title:    tasks[0].Title,
+		Base:     tasks[0].Base,
+		Attempts: []GroupAttempt{},
+	}
+
+	for _, t := range tasks {
+		result.Attempts = append(result.Attempts, groupAttempt(ctx, g, t))
+	}
+
+	return &result, nil
+}
+
+// NewGroupPickCmd creates the group pick command
+func NewGroupPickCmd() *cobra.Command {
+	opts := &GroupPickOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "pick <group> <task-id>",
+		Short: "Hand off the winning attempt and abandon the others",
+		Long: `Pick the winning attempt of a task group.
+
+This command performs the following steps:
+  1. Hands off the chosen task (same flow as 'awt task handoff')
+  2. Abandons every other open task in the group, recording the winner as the reason
+  3. Removes the worktrees of the abandoned tasks
+
+Abandoned attempts keep their branches unless --delete-branch is given, so they
+can still be restored with 'awt task reopen'.
+
+Example:
+  awt group pick 20250110-120000-abc123 20250110-120000-abc123-claude
+  awt group pick 20250110-120000-abc123 20250110-120000-abc123-codex --no-pr --delete-branch`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Group = args[0]
+			opts.TaskID = args[1]
+			return runGroupPick(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().BoolVar(&opts.NoPush, "no-push", false, "skip pushing the winning branch to remote")
+	cmd.Flags().BoolVar(&opts.NoPR, "no-pr", false, "skip creating pull/merge request for the winning branch")
+	cmd.Flags().BoolVar(&opts.KeepWorktree, "keep-worktree", false, "keep the winning task's worktree after handoff")
+	cmd.Flags().BoolVar(&opts.DeleteBranch, "delete-branch", false, "delete the local branches of the abandoned tasks")
+	cmd.Flags().BoolVar(&opts.DeleteRemote, "delete-remote", false, "delete the remote branches of the abandoned tasks")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "discard uncommitted changes in the abandoned worktrees")
+	cmd.Flags().BoolVar(&opts.ForceRemove, "force-remove", false, "force remove worktrees even if CWD is inside")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runGroupPick(opts *GroupPickOptions) error {
+	result, err := cliClient(opts.OutputJSON).GroupPick(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("\nPicked %s for group %s.\n", result.Winner.TaskID, result.Group)
+		fmt.Printf("  Branch: %s\n", result.Winner.Branch)
+		fmt.Printf("  State: %s\n", result.Winner.State)
+		if result.Winner.PRURL != "" {
+			fmt.Printf("  PR: %s\n", result.Winner.PRURL)
+		}
+		for _, a := range result.Abandoned {
+			fmt.Printf("  Abandoned: %s", a.TaskID)
+			if a.BranchDeleted {
+				fmt.Printf(" (branch deleted)")
+			}
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
+
+// GroupPick hands off the winning attempt of a task group and abandons the others
+func (c *Client) GroupPick(ctx context.Context, opts *GroupPickOptions) (*GroupPickResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	tasks, err := loadGroupTasks(store, opts.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the group into the winner and the open attempts that lost
+	var winner *task.Task
+	var losers []*task.Task
+	for _, t := range tasks {
+		if t.ID == opts.TaskID {
+			winner = t
+		} else if task.CanTransition(t.State, task.StateAbandoned) {
+			losers = append(losers, t)
+		}
+	}
+	if winner == nil {
+		return nil, fmt.Errorf("task %s is not part of group %s\nUse 'awt group status %s' to see its tasks", opts.TaskID, opts.Group, opts.Group)
+	}
+
+	// Refuse dirty losing worktrees up front so the winner is not handed off
+	// when the cleanup would fail halfway
+	if !opts.Force {
+		for _, t := range losers {
+			if t.WorktreePath == "" {
+				continue
+			}
+			if _, err := os.Stat(t.WorktreePath); err != nil {
+				continue
+			}
+			dirty, err := git.New(t.WorktreePath, cfg.VerboseGit).IsDirty(ctx)
+			if err == nil && dirty {
+				return nil, fmt.Errorf("worktree of %s has uncommitted changes: %s\nCommit them first, or use --force to discard them", t.ID, t.WorktreePath)
+			}
+		}
+	}
+
+	// Hand off the winner (acquires the global lock itself for worktree removal)
+	handoffResult, err := c.handoffTask(ctx, r, cfg, store, winner, &HandoffOptions{
+		RepoPath:     opts.RepoPath,
+		TaskID:       winner.ID,
+		NoPush:       opts.NoPush,
+		NoPR:         opts.NoPR,
+		KeepWorktree: opts.KeepWorktree,
+		ForceRemove:  opts.ForceRemove,
+		OutputJSON:   opts.OutputJSON,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hand off %s: %w", winner.ID, err)
+	}
+
+	// Lock the losing tasks, then the global lock for removing their worktrees
+	loserLocks := make([]*lock.Lock, len(losers))
+	defer func() {
+		for _, l := range loserLocks {
+			if l != nil {
+				_ = l.Release()
+			}
+		}
+	}()
+	for i, t := range losers {
+		taskLock, err := acquireTaskLock(ctx, r, cfg, t.ID)
+		if err != nil {
+			return nil, err
+		}
+		loserLocks[i] = taskLock
+
+		// Reload the task now that it is locked, in case it changed while waiting
+		if fresh, err := store.Load(t.ID); err == nil {
+			*t = *fresh
+		}
+	}
+	lm := newLockManager(r, cfg)
+	globalLock, err := lm.AcquireGlobal(ctx)
+	if err != nil {
+		return nil, errors.LockTimeout("global")
+	}
+	defer func() {
+		_ = globalLock.Release()
+	}()
+
+	result := GroupPickResult{
+		Group:     opts.Group,
+		Winner:    handoffResult,
+		Abandoned: []AbandonResult{},
+	}
+
+	abandonOpts := &AbandonOptions{
+		RepoPath:     opts.RepoPath,
+		Reason:       fmt.Sprintf("lost to %s", winner.ID),
+		DeleteBranch: opts.DeleteBranch,
+		DeleteRemote: opts.DeleteRemote,
+		Force:        opts.Force,
+		ForceRemove:  opts.ForceRemove,
+		OutputJSON:   opts.OutputJSON,
+	}
+	for i, t := range losers {
+		abandonResult, err := c.abandonTask(ctx, r, cfg, store, loserLocks[i], t, abandonOpts, "group pick")
+		if err != nil {
+			return nil, fmt.Errorf("failed to abandon %s: %w", t.ID, err)
+		}
+		result.Abandoned = append(result.Abandoned, *abandonResult)
+	}
+
+	return &result, nil
+}
+
+// loadGroupTasks returns the tasks belonging to a group, sorted by agent name
+func loadGroupTasks(store task.Store, group string) ([]*task.Task, error) {
+	tasks, err := store.Find(task.Filter{Group: group})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no tasks found for group: %s\nUse 'awt list' to see available tasks", group)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Agent < tasks[j].Agent
+	})
+
+	return tasks, nil
+}
+
+// groupAttempt collects commit and diff statistics for a task's branch.
+// If the branch no longer exists, only the task metadata is reported.
+func groupAttempt(ctx context.Context, g *git.Git, t *task.Task) GroupAttempt {
+	attempt := GroupAttempt{
+		TaskID:       t.ID,
+		Agent:        t.Agent,
+		State:        string(t.State),
+		Branch:       t.Branch,
+		LastCommit:   t.LastCommit,
+		WorktreePath: t.WorktreePath,
+		PRURL:        t.PRURL,
+	}
+
+	tip, err := g.RevParse(ctx, "refs/heads/"+strings.TrimPrefix(t.Branch, "refs/heads/"))
+	if err != nil {
+		return attempt
+	}
+	attempt.LastCommit = tip
+
+	mergeBase, err := g.MergeBase(ctx, tip, t.Base)
+	if err != nil {
+		return attempt
+	}
+
+	if count, err := g.CountCommits(ctx, mergeBase+".."+tip); err == nil {
+		attempt.Commits = count
+	}
+
+	if stats, err := g.DiffNumStat(ctx, mergeBase, tip); err == nil {
+		attempt.FilesChanged = len(stats)
+		for _, s := range stats {
+			attempt.Insertions += s.Added
+			attempt.Deletions += s.Deleted
+		}
+	}
+
+	return attempt
+}
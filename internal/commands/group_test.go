@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestRunTaskStartWithAgents(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	opts := &StartOptions{
+		RepoPath:     repoPath,
+		Agents:       []string{"claude", "codex", "factory"},
+		Title:        "Race task",
+		Base:         "HEAD",
+		ID:           "race",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(opts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tasks, err := loadGroupTasks(store, "race")
+	if err != nil {
+		t.Fatalf("loadGroupTasks() failed: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks in group, got %d", len(tasks))
+	}
+
+	for _, tk := range tasks {
+		if tk.ID != "race-"+tk.Agent {
+			t.Errorf("task ID = %s, want race-%s", tk.ID, tk.Agent)
+		}
+		if _, err := os.Stat(tk.WorktreePath); err != nil {
+			t.Errorf("worktree missing for %s", tk.ID)
+		}
+	}
+
+	// Commit on one attempt and check it shows up in the group status
+	claude := tasks[0]
+	_ = os.WriteFile(filepath.Join(claude.WorktreePath, "a.txt"), []byte("one\ntwo\n"), 0644)
+	gitOutput(t, claude.WorktreePath, "add", ".")
+	gitOutput(t, claude.WorktreePath, "commit", "-m", "claude attempt")
+
+	if err := runGroupStatus(&GroupStatusOptions{RepoPath: repoPath, Group: "race", OutputJSON: true}); err != nil {
+		t.Fatalf("runGroupStatus() failed: %v", err)
+	}
+
+	attempt := groupAttempt(context.Background(), git.New(repoPath, false), claude)
+	if attempt.Commits != 1 || attempt.FilesChanged != 1 || attempt.Insertions != 2 {
+		t.Errorf("unexpected attempt stats: %+v", attempt)
+	}
+}
+
+func TestRunTaskStartWithDuplicateAgents(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	opts := &StartOptions{
+		RepoPath:     repoPath,
+		Agents:       []string{"claude", "claude"},
+		Title:        "Race task",
+		Base:         "HEAD",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+	}
+	if err := runTaskStart(opts); err == nil {
+		t.Error("expected error for duplicate agents")
+	}
+}
+
+func TestRunGroupPick(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	opts := &StartOptions{
+		RepoPath:     repoPath,
+		Agents:       []string{"claude", "codex", "factory"},
+		Title:        "Race task",
+		Base:         "HEAD",
+		ID:           "pick",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(opts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	pickOpts := &GroupPickOptions{
+		RepoPath:     repoPath,
+		Group:        "pick",
+		TaskID:       "pick-codex",
+		NoPush:       true,
+		NoPR:         true,
+		DeleteBranch: true,
+		OutputJSON:   true,
+	}
+	if err := runGroupPick(pickOpts); err != nil {
+		t.Fatalf("runGroupPick() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tasks, err := loadGroupTasks(store, "pick")
+	if err != nil {
+		t.Fatalf("loadGroupTasks() failed: %v", err)
+	}
+
+	for _, tk := range tasks {
+		if tk.ID == "pick-codex" {
+			if tk.State != task.StateHandoffReady {
+				t.Errorf("winner state = %s, want %s", tk.State, task.StateHandoffReady)
+			}
+			continue
+		}
+
+		if tk.State != task.StateAbandoned {
+			t.Errorf("%s state = %s, want %s", tk.ID, tk.State, task.StateAbandoned)
+		}
+		last := tk.History[len(tk.History)-1]
+		if last.Reason != "lost to pick-codex" || last.Command != "group pick" {
+			t.Errorf("%s history = %+v, want reason pointing at winner", tk.ID, last)
+		}
+		if tk.WorktreePath != "" {
+			t.Errorf("%s worktree path was not cleared", tk.ID)
+		}
+		if exists, _ := git.New(repoPath, false).BranchExists(context.Background(), tk.Branch); exists {
+			t.Errorf("%s branch was not deleted", tk.ID)
+		}
+	}
+}
+
+func TestRunGroupPickUnknownTask(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	opts := &StartOptions{
+		RepoPath:     repoPath,
+		Agents:       []string{"claude", "codex"},
+		Title:        "Race task",
+		Base:         "HEAD",
+		ID:           "pick",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(opts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	if err := runGroupPick(&GroupPickOptions{RepoPath: repoPath, Group: "pick", TaskID: "other", NoPush: true, NoPR: true}); err == nil {
+		t.Error("expected error for task outside the group")
+	}
+}
@@ -5,14 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/forge"
 	"github.com/kernel-labs-ai/awt/internal/git"
-	"github.com/kernel-labs-ai/awt/internal/lock"
 	"github.com/kernel-labs-ai/awt/internal/repo"
 	"github.com/kernel-labs-ai/awt/internal/task"
 	"github.com/spf13/cobra"
@@ -28,6 +27,10 @@ type HandoffOptions struct {
 	KeepWorktree bool
 	ForceRemove  bool
 	OutputJSON   bool
+	// Draft, Reviewers and Labels are added to those set by the PR template
+	Draft     bool
+	Reviewers []string
+	Labels    []string
 }
 
 // HandoffResult represents the output of the handoff command
@@ -36,7 +39,11 @@ type HandoffResult struct {
 	Branch       string `json:"branch"`
 	Pushed       bool   `json:"pushed"`
 	PRURL        string `json:"pr_url,omitempty"`
+	PRNumber     int    `json:"pr_number,omitempty"`
 	WorktreeKept bool   `json:"worktree_kept"`
+	// State and WorktreePath are shown by the CLI
+	State        task.State `json:"-"`
+	WorktreePath string     `json:"-"`
 }
 
 // NewTaskHandoffCmd creates the task handoff command
@@ -57,15 +64,23 @@ This command performs the following steps:
   1. Commits any staged changes (optional)
   2. Syncs with base branch
   3. Pushes to remote (unless --no-push)
-  4. Creates PR/MR (unless --no-pr, requires gh/glab; falls back to compare URL)
+  4. Creates PR/MR through the forge API (unless --no-pr; without a token
+     for the host it prints a compare URL instead)
   5. Detaches HEAD in worktree
   6. Removes worktree (unless --keep-worktree)
   7. Updates task state to HANDOFF_READY
 
+The PR body is rendered with Go text/template from pr_template.md in the
+repo's .git/awt directory, or else in ~/.config/awt. The template sees the
+task, its commits, diffstat, event log and the AWT_SUMMARY.md file the
+agent left in the worktree root, and can call draft, reviewer "name" and
+label "name" to set the same options as --draft, --reviewer and --label.
+
 Example:
   awt task handoff 20250110-120000-abc123
   awt task handoff --no-push
-  awt task handoff --keep-worktree`,
+  awt task handoff --keep-worktree
+  awt task handoff --draft --reviewer alice --label agent`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
@@ -81,161 +96,248 @@ Example:
 	cmd.Flags().BoolVar(&opts.NoPR, "no-pr", false, "skip creating pull/merge request")
 	cmd.Flags().BoolVar(&opts.KeepWorktree, "keep-worktree", false, "keep worktree after handoff")
 	cmd.Flags().BoolVar(&opts.ForceRemove, "force-remove", false, "force remove worktree even if CWD is inside")
+	cmd.Flags().BoolVar(&opts.Draft, "draft", false, "open the PR as a draft")
+	cmd.Flags().StringSliceVar(&opts.Reviewers, "reviewer", nil, "request a review from this user (repeatable)")
+	cmd.Flags().StringSliceVar(&opts.Labels, "label", nil, "add this label to the PR (repeatable)")
 	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
 
 	return cmd
 }
 
 func runTaskHandoff(opts *HandoffOptions) error {
+	result, err := cliClient(opts.OutputJSON).Handoff(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("\nHandoff completed successfully!\n")
+		fmt.Printf("  Task: %s\n", result.TaskID)
+		fmt.Printf("  Branch: %s\n", result.Branch)
+		fmt.Printf("  State: %s\n", result.State)
+		if result.Pushed {
+			fmt.Printf("  Pushed: yes\n")
+		}
+		if result.PRURL != "" {
+			fmt.Printf("  PR: %s\n", result.PRURL)
+		}
+		if result.WorktreeKept {
+			fmt.Printf("  Worktree: kept at %s\n", result.WorktreePath)
+		} else {
+			fmt.Printf("  Worktree: removed\n")
+		}
+	}
+
+	return nil
+}
+
+// Handoff syncs, pushes and opens a PR for the task, removes its worktree and
+// moves it to HANDOFF_READY
+func (c *Client) Handoff(ctx context.Context, opts *HandoffOptions) (*HandoffResult, error) {
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
 	}
 
 	// Load config
 	configLoader := config.NewConfigLoader(r.GitCommonDir)
 	cfg, err := configLoader.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Compute effective push/PR flags from config + CLI overrides
-	shouldPush := cfg.AutoPush && !opts.NoPush
-	shouldCreatePR := cfg.AutoPR && !opts.NoPR
-
-	store := task.NewTaskStore(r.GitCommonDir)
-
-	// Determine task ID
-	taskID := opts.TaskID
-
-	if taskID == "" && opts.Branch != "" {
-		// Extract task ID from branch name
-		taskID = extractTaskIDFromBranch(opts.Branch)
-		if taskID == "" {
-			return fmt.Errorf("could not extract task ID from branch: %s", opts.Branch)
-		}
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
 	}
+	defer func() {
+		_ = store.Close()
+	}()
 
-	if taskID == "" {
-		// Try to infer from current worktree
-		taskID, err = inferTaskIDFromCurrentDirectory(r)
-		if err != nil {
-			return fmt.Errorf("could not infer task ID: %w\nProvide task ID as argument or use --branch flag", err)
-		}
+	// Determine task ID
+	taskID, err := resolveTaskID(ctx, r, store, opts.TaskID, opts.Branch)
+	if err != nil {
+		return nil, err
 	}
 
 	// Load task
 	t, err := store.Load(taskID)
 	if err != nil {
-		return errors.InvalidTaskID(taskID)
+		return nil, errors.InvalidTaskID(taskID)
+	}
+
+	return c.handoffTask(ctx, r, cfg, store, t, opts)
+}
+
+// handoffTask syncs, pushes and opens a PR for the task, removes its worktree
+// and moves it to HANDOFF_READY. The task lock is held throughout and the global lock
+// is acquired for worktree removal, so the caller must hold neither.
+func (c *Client) handoffTask(ctx context.Context, r *repo.Repo, cfg *config.Config, store task.Store, t *task.Task, opts *HandoffOptions) (*HandoffResult, error) {
+	// Hold the task lock so concurrent commands cannot mutate the task
+	taskLock, err := acquireTaskLock(ctx, r, cfg, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
+
+	// Reload the task now that it is locked, in case it changed while waiting
+	if fresh, err := store.Load(t.ID); err == nil {
+		*t = *fresh
+	}
+
+	// Compute effective push/PR flags from config + CLI overrides
+	shouldPush := cfg.AutoPush && !opts.NoPush
+	shouldCreatePR := cfg.AutoPR && !opts.NoPR
+
+	// Check the state up front so nothing is pushed for a task that cannot be handed off.
+	// A handed-off task has no worktree to run git in.
+	if err := t.RequireState("handoff", task.StateActive); err != nil {
+		return nil, err
+	}
+	if err := requireWorktree(t); err != nil {
+		return nil, err
+	}
+
+	// Record the pre-operation state so the command can be undone
+	if err := recordOperation(ctx, r, "task handoff", t, false); err != nil {
+		return nil, err
 	}
 
 	// Create Git wrapper for the worktree
-	g := git.New(t.WorktreePath, cfg.VerboseGit)
+	g := git.New(t.WorktreePath, cfg.VerboseGit).WithTimeouts(gitTimeouts(cfg))
 
 	// Step 1: Check for uncommitted changes (optional - just warn)
-	statusResult, err := g.Status()
+	statusResult, err := g.Status(ctx)
 	if err == nil && statusResult.ExitCode == 0 {
 		if !strings.Contains(statusResult.Stdout, "nothing to commit") {
-			if !opts.OutputJSON {
-				fmt.Println("Warning: uncommitted changes detected. Consider running 'awt task commit' first.")
-			}
+			c.progressf("Warning: uncommitted changes detected. Consider running 'awt task commit' first.\n")
 		}
 	}
 
 	// Step 2: Sync with base (rebase by default)
-	if !opts.OutputJSON {
-		fmt.Printf("Syncing with base branch %s...\n", t.Base)
-	}
+	c.progressf("Syncing with base branch %s...\n", t.Base)
 
-	syncResult, err := g.Rebase(t.Base)
-	if err != nil || syncResult.ExitCode != 0 {
+	syncResult, err := g.Rebase(ctx, t.Base)
+	if err != nil {
+		// git was stopped part way (timeout or cancellation), so put the branch back
+		abortInterrupted(ctx, g)
+		recordEvent(r, t.ID, task.Event{Type: task.EventSync, Strategy: "rebase", Result: task.ResultFailed, Message: "stopped while syncing onto " + t.Base})
+		return nil, err
+	}
+	if syncResult.ExitCode != 0 {
 		// Check for conflicts
 		if strings.Contains(syncResult.Stderr, "conflict") || strings.Contains(syncResult.Stdout, "conflict") {
-			return errors.SyncConflicts(t.Branch)
+			recordEvent(r, t.ID, task.Event{Type: task.EventSync, Strategy: "rebase", Result: task.ResultConflict, Message: "onto " + t.Base})
+			return nil, errors.SyncConflicts(t.Branch)
 		}
 		// Rebase failed but not conflicts - continue anyway
-		if !opts.OutputJSON {
-			fmt.Printf("Warning: sync failed: %s\n", syncResult.Stderr)
-		}
+		recordEvent(r, t.ID, task.Event{Type: task.EventSync, Strategy: "rebase", Result: task.ResultFailed, Message: strings.TrimSpace(syncResult.Stderr)})
+		c.progressf("Warning: sync failed: %s\n", syncResult.Stderr)
+	} else {
+		head, _ := g.RevParse(ctx, "HEAD")
+		recordEvent(r, t.ID, task.Event{Type: task.EventSync, Strategy: "rebase", Result: task.ResultOK, SHA: head, Message: "onto " + t.Base})
 	}
 
 	// Step 3: Push if configured
 	pushed := false
 	if shouldPush {
-		if !opts.OutputJSON {
-			fmt.Printf("Pushing to remote...\n")
-		}
+		c.progressf("Pushing to remote...\n")
 
 		// Extract branch name without refs/heads/
 		branchName := strings.TrimPrefix(t.Branch, "refs/heads/")
 
-		pushResult, err := g.Push(cfg.RemoteName, branchName, true, false)
+		pushResult, err := g.Push(ctx, cfg.RemoteName, branchName, true, false)
 		if err != nil || pushResult.ExitCode != 0 {
-			return errors.PushRejected(t.Branch, err)
+			message := ""
+			if pushResult != nil {
+				message = strings.TrimSpace(pushResult.Stderr)
+			}
+			recordEvent(r, t.ID, task.Event{Type: task.EventPush, Remote: cfg.RemoteName, Result: task.ResultFailed, Message: message})
+			if errors.ExitCodeOf(err) == errors.ExitGitTimeout {
+				return nil, err
+			}
+			return nil, errors.PushRejected(t.Branch, err)
 		}
 		pushed = true
+		head, _ := g.RevParse(ctx, "HEAD")
+		recordEvent(r, t.ID, task.Event{Type: task.EventPush, Remote: cfg.RemoteName, Result: task.ResultOK, SHA: head})
 	}
 
 	// Step 4: Create PR if configured (requires push)
 	prURL := ""
+	prNumber := 0
 	if shouldCreatePR && shouldPush {
-		if !opts.OutputJSON {
-			fmt.Printf("Creating pull request...\n")
-		}
+		c.progressf("Creating pull request...\n")
 
 		branchName := strings.TrimPrefix(t.Branch, "refs/heads/")
 		baseBranch := stripRemotePrefix(t.Base)
 
-		// Check if gh or glab is available
-		ghAvailable := checkCommandExists("gh")
-		glabAvailable := checkCommandExists("glab")
-
-		if ghAvailable || glabAvailable {
-			var prResult *git.Result
-			if ghAvailable {
-				prResult, err = g.CreatePRWithGH(t.Title, fmt.Sprintf("Task: %s\nAgent: %s\nBranch: %s", t.ID, t.Agent, t.Branch), baseBranch)
+		f, forgeErr := openForge(ctx, g, cfg)
+		if forgeErr == nil {
+			body := c.buildPRBody(ctx, r, g, t)
+			reviewers := appendUnique(body.Reviewers, opts.Reviewers...)
+			labels := appendUnique(body.Labels, opts.Labels...)
+
+			pr, err := f.CreatePR(ctx, &forge.PRRequest{
+				Title: t.Title,
+				Body:  body.Body,
+				Head:  branchName,
+				Base:  baseBranch,
+				Draft: body.Draft || opts.Draft,
+			})
+			if err != nil {
+				recordEvent(r, t.ID, task.Event{Type: task.EventPR, Result: task.ResultFailed, Message: err.Error()})
+				c.progressf("Warning: failed to create PR: %v\n", err)
 			} else {
-				prResult, err = g.CreateMRWithGLab(t.Title, fmt.Sprintf("Task: %s\nAgent: %s\nBranch: %s", t.ID, t.Agent, t.Branch), baseBranch)
-			}
-
-			if err != nil || prResult.ExitCode != 0 {
-				if !opts.OutputJSON {
-					stderr := ""
-					if prResult != nil {
-						stderr = prResult.Stderr
+				prURL = pr.URL
+				prNumber = pr.Number
+				recordEvent(r, t.ID, task.Event{Type: task.EventPR, Result: task.ResultOK, URL: prURL})
+
+				// The PR exists at this point, so failing to set these only warrants a warning
+				if len(reviewers) > 0 {
+					if err := f.RequestReviewers(ctx, pr.Number, reviewers); err != nil {
+						c.progressf("Warning: failed to request reviewers: %v\n", err)
 					}
-					fmt.Printf("Warning: failed to create PR: %s\n", stderr)
 				}
-			} else {
-				prURL = extractPRURL(prResult.Stdout)
-				if prURL != "" {
-					t.PRURL = prURL
+				if len(labels) > 0 {
+					if err := f.AddLabels(ctx, pr.Number, labels); err != nil {
+						c.progressf("Warning: failed to add labels: %v\n", err)
+					}
 				}
 			}
 		} else {
 			// Fallback: generate a compare URL
-			compareURL, urlErr := g.CompareURL(cfg.RemoteName, branchName, baseBranch)
+			compareURL, urlErr := forgeCompareURL(ctx, g, cfg, branchName, baseBranch)
 			if urlErr == nil && compareURL != "" {
 				prURL = compareURL
-				if !opts.OutputJSON {
-					fmt.Printf("gh/glab not found. Open this URL to create a PR:\n  %s\n", compareURL)
-				}
-			} else if !opts.OutputJSON {
-				fmt.Printf("Warning: gh/glab not found and could not generate compare URL: %v\n", urlErr)
+				recordEvent(r, t.ID, task.Event{Type: task.EventPR, Result: task.ResultOK, URL: compareURL, Message: "compare URL"})
+				c.progressf("Cannot create PR through the forge API: %v\nOpen this URL to create a PR:\n  %s\n", forgeErr, compareURL)
+			} else {
+				c.progressf("Warning: cannot create PR through the forge API (%v) and could not generate compare URL: %v\n", forgeErr, urlErr)
 			}
 		}
 	}
 
-	// Step 5: Detach HEAD in worktree
-	if !opts.OutputJSON {
-		fmt.Printf("Detaching HEAD in worktree...\n")
+	// Record the handed-off tip so reconcile can tell the branch apart from its base,
+	// including commits made with plain git rather than awt task commit
+	tip := ""
+	if ahead, err := g.CountCommits(ctx, t.Base+"..HEAD"); err == nil && ahead > 0 {
+		tip, _ = g.RevParse(ctx, "HEAD")
 	}
 
-	detachResult, err := g.Switch("HEAD", true)
+	// Step 5: Detach HEAD in worktree
+	c.progressf("Detaching HEAD in worktree...\n")
+
+	detachResult, err := g.Switch(ctx, "HEAD", true)
 	if err != nil || detachResult.ExitCode != 0 {
-		return errors.DetachFailed(t.WorktreePath, err)
+		return nil, errors.DetachFailed(t.WorktreePath, err)
 	}
 
 	// Step 6: Remove worktree (unless --keep-worktree)
@@ -252,30 +354,25 @@ func runTaskHandoff(opts *HandoffOptions) error {
 			isInside := err == nil && !filepath.IsAbs(rel) && rel != ".." && !hasParentDir(rel)
 
 			if isInside && !opts.ForceRemove {
-				if !opts.OutputJSON {
-					fmt.Printf("Warning: current directory is inside worktree. Keeping worktree.\n")
-					fmt.Printf("Use --force-remove to remove anyway, or cd out of the worktree.\n")
-				}
+				c.progressf("Warning: current directory is inside worktree. Keeping worktree.\n")
+				c.progressf("Use --force-remove to remove anyway, or cd out of the worktree.\n")
 				worktreeKept = true
 			} else if isInside && opts.ForceRemove {
 				// Change to repository root before removing
 				if err := os.Chdir(r.WorkTreeRoot); err != nil {
-					return fmt.Errorf("failed to change directory: %w", err)
+					return nil, fmt.Errorf("failed to change directory: %w", err)
 				}
 			}
 		}
 
 		if !worktreeKept {
-			if !opts.OutputJSON {
-				fmt.Printf("Removing worktree...\n")
-			}
+			c.progressf("Removing worktree...\n")
 
 			// Acquire global lock before removing worktree
-			lm := lock.NewLockManager(r.GitCommonDir)
-			ctx := context.Background()
+			lm := newLockManager(r, cfg)
 			globalLock, err := lm.AcquireGlobal(ctx)
 			if err != nil {
-				return errors.LockTimeout("global")
+				return nil, errors.LockTimeout("global")
 			}
 			defer func() {
 			_ = globalLock.Release()
@@ -283,55 +380,51 @@ func runTaskHandoff(opts *HandoffOptions) error {
 
 			// Create git wrapper from repo root
 			repoGit := git.New(r.WorkTreeRoot, false)
-			removeResult, err := repoGit.WorktreeRemove(t.WorktreePath, true)
+			removeResult, err := repoGit.WorktreeRemove(ctx, t.WorktreePath, true)
 			if err != nil || removeResult.ExitCode != 0 {
-				return errors.RemoveFailed(t.WorktreePath, err)
+				return nil, errors.RemoveFailed(t.WorktreePath, err)
 			}
 		}
 	}
 
-	// Update task state
-	t.State = task.StateHandoffReady
-	if err := store.Save(t); err != nil {
-		return fmt.Errorf("failed to update task metadata: %w", err)
-	}
-
-	// Output result
-	if opts.OutputJSON {
-		output := HandoffResult{
-			TaskID:       taskID,
-			Branch:       t.Branch,
-			Pushed:       pushed,
-			PRURL:        prURL,
-			WorktreeKept: worktreeKept,
+	// Update task state; a removed worktree's path is cleared so prune keeps the metadata
+	updated, err := task.Update(store, taskLock, t.ID, func(t *task.Task) error {
+		if !worktreeKept {
+			t.WorktreePath = ""
 		}
-		data, _ := json.MarshalIndent(output, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		fmt.Printf("\nHandoff completed successfully!\n")
-		fmt.Printf("  Task: %s\n", taskID)
-		fmt.Printf("  Branch: %s\n", t.Branch)
-		fmt.Printf("  State: %s\n", t.State)
-		if pushed {
-			fmt.Printf("  Pushed: yes\n")
+		if tip != "" {
+			t.LastCommit = tip
 		}
 		if prURL != "" {
-			fmt.Printf("  PR: %s\n", prURL)
-		}
-		if worktreeKept {
-			fmt.Printf("  Worktree: kept at %s\n", t.WorktreePath)
-		} else {
-			fmt.Printf("  Worktree: removed\n")
+			if prNumber != t.PRNumber {
+				// The last-seen status was of another pull request
+				t.PRStatus = nil
+			}
+			t.PRURL = prURL
+			t.PRNumber = prNumber
 		}
+		return t.TransitionTo(task.StateHandoffReady, currentActor(), "task handoff")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task metadata: %w", err)
 	}
-
-	return nil
-}
-
-// checkCommandExists checks if a command exists in PATH
-func checkCommandExists(cmd string) bool {
-	_, err := exec.LookPath(cmd)
-	return err == nil
+	*t = *updated
+	handoffMessage := "worktree removed"
+	if worktreeKept {
+		handoffMessage = "worktree kept"
+	}
+	recordEvent(r, t.ID, task.Event{Type: task.EventHandoff, Message: handoffMessage})
+
+	return &HandoffResult{
+		TaskID:       t.ID,
+		Branch:       t.Branch,
+		Pushed:       pushed,
+		PRURL:        prURL,
+		PRNumber:     prNumber,
+		WorktreeKept: worktreeKept,
+		State:        t.State,
+		WorktreePath: t.WorktreePath,
+	}, nil
 }
 
 // stripRemotePrefix converts "origin/main" to "main"
@@ -341,14 +434,3 @@ func stripRemotePrefix(ref string) string {
 	}
 	return ref
 }
-
-// extractPRURL extracts the PR URL from gh/glab output
-func extractPRURL(output string) string {
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "http") {
-			return strings.TrimSpace(line)
-		}
-	}
-	return ""
-}
@@ -1,7 +1,12 @@
 package commands
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/task"
 )
 
 func TestStripRemotePrefix(t *testing.T) {
@@ -26,14 +31,50 @@ func TestStripRemotePrefix(t *testing.T) {
 	}
 }
 
-func TestCheckCommandExists(t *testing.T) {
-	// "git" should exist on any system running these tests
-	if !checkCommandExists("git") {
-		t.Error("expected git to be found")
+func TestRunTaskHandoffRefusesHandedOffTask(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Handoff twice",
+		Base:         "HEAD",
+		ID:           "handoff-twice",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+	tk, err := task.NewTaskStore(filepath.Join(repoPath, ".git")).Load("handoff-twice")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tk.WorktreePath, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := runTaskCommit(&CommitOptions{RepoPath: repoPath, TaskID: tk.ID, Message: "Add feature", All: true, OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskCommit() failed: %v", err)
+	}
+	handoffOpts := &HandoffOptions{RepoPath: repoPath, TaskID: tk.ID, NoPush: true, NoPR: true, OutputJSON: true}
+	if err := runTaskHandoff(handoffOpts); err != nil {
+		t.Fatalf("runTaskHandoff() failed: %v", err)
 	}
 
-	// A nonsense command should not exist
-	if checkCommandExists("awt-nonexistent-command-12345") {
-		t.Error("expected nonexistent command to not be found")
+	// The main checkout, which a handoff without a worktree would run git in, is left alone
+	branch := gitOutput(t, repoPath, "rev-parse", "--abbrev-ref", "HEAD")
+	head := gitOutput(t, repoPath, "rev-parse", "HEAD")
+	err = runTaskHandoff(handoffOpts)
+	if errors.ExitCodeOf(err) != errors.ExitInvalidTaskState {
+		t.Errorf("second runTaskHandoff() error = %v, want exit code %d", err, errors.ExitInvalidTaskState)
+	}
+	if got := gitOutput(t, repoPath, "rev-parse", "--abbrev-ref", "HEAD"); got != branch {
+		t.Errorf("main checkout moved to %s, want %s", got, branch)
+	}
+	if got := gitOutput(t, repoPath, "rev-parse", "HEAD"); got != head {
+		t.Errorf("main checkout HEAD = %s, want %s", got, head)
 	}
 }
@@ -1,12 +1,15 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/forge"
 	"github.com/kernel-labs-ai/awt/internal/git"
 	"github.com/kernel-labs-ai/awt/internal/repo"
 	"github.com/kernel-labs-ai/awt/internal/task"
@@ -16,6 +19,12 @@ import (
 // ListOptions contains options for the list command
 type ListOptions struct {
 	RepoPath   string
+	Agent      string
+	State      string
+	Group      string
+	Reconcile  bool
+	NoFetch    bool
+	PR         bool
 	OutputJSON bool
 }
 
@@ -28,6 +37,10 @@ type TaskListItem struct {
 	Branch       string `json:"branch"`
 	WorktreePath string `json:"worktree_path,omitempty"`
 	CheckedOut   bool   `json:"checked_out"`
+	Group        string `json:"group,omitempty"`
+	// PRNumber and PRStatus are only set with ListOptions.PR
+	PRNumber int            `json:"pr_number,omitempty"`
+	PRStatus *task.PRStatus `json:"pr_status,omitempty"`
 }
 
 // NewListCmd creates the list command
@@ -40,9 +53,20 @@ func NewListCmd() *cobra.Command {
 		Long: `List all AWT tasks with their current status.
 
 Shows task ID, agent, title, state, and checkout status.
+Use --agent, --state and --group to list only matching tasks.
+
+With --reconcile, merged tasks are detected and marked MERGED before listing
+(see 'awt task reconcile').
+
+With --pr, the pull requests of listed tasks are queried through the forge API
+(see 'awt task pr') and a PR column shows their checks and review decision.
+PRs that are already merged or closed keep their last-seen status.
 
 Example:
   awt list
+  awt list --reconcile
+  awt list --pr --state=HANDOFF_READY
+  awt list --agent=claude --state=ACTIVE
   awt list --json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runList(opts)
@@ -50,38 +74,125 @@ Example:
 	}
 
 	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.Agent, "agent", "", "only list tasks of this agent")
+	cmd.Flags().StringVar(&opts.State, "state", "", "only list tasks in this state (e.g. ACTIVE)")
+	cmd.Flags().StringVar(&opts.Group, "group", "", "only list tasks of this task group")
+	cmd.Flags().BoolVar(&opts.Reconcile, "reconcile", false, "detect merged tasks before listing")
+	cmd.Flags().BoolVar(&opts.NoFetch, "no-fetch", false, "skip git fetch when reconciling")
+	cmd.Flags().BoolVar(&opts.PR, "pr", false, "show the PR status of each task")
 	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
 
 	return cmd
 }
 
 func runList(opts *ListOptions) error {
+	items, err := cliClient(opts.OutputJSON).List(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(items, "", "  ")
+		fmt.Println(string(data))
+	} else if len(items) == 0 {
+		fmt.Println("No tasks found")
+	} else {
+		// Print table header
+		header := fmt.Sprintf("%-20s %-12s %-30s %-15s %-11s", "ID", "AGENT", "TITLE", "STATE", "CHECKED OUT")
+		width := 90
+		if opts.PR {
+			header += " PR"
+			width += 30
+		}
+		fmt.Println(strings.TrimRight(header, " "))
+		fmt.Println(strings.Repeat("-", width))
+
+		// Print tasks
+		for _, item := range items {
+			title := item.Title
+			if len(title) > 30 {
+				title = title[:27] + "..."
+			}
+
+			checkedOut := "no"
+			if item.CheckedOut {
+				checkedOut = "yes"
+			}
+
+			row := fmt.Sprintf("%-20s %-12s %-30s %-15s %-11s",
+				item.ID,
+				item.Agent,
+				title,
+				item.State,
+				checkedOut,
+			)
+			if opts.PR {
+				row += " " + prStatusLabel(item.PRNumber, item.PRStatus)
+			}
+			fmt.Println(strings.TrimRight(row, " "))
+		}
+
+		fmt.Printf("\nTotal: %d tasks\n", len(items))
+	}
+
+	return nil
+}
+
+// List returns the tasks matching the filters in opts
+func (c *Client) List(ctx context.Context, opts *ListOptions) ([]TaskListItem, error) {
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
 	}
+	defer func() {
+		_ = store.Close()
+	}()
 
-	store := task.NewTaskStore(r.GitCommonDir)
+	var cfg *config.Config
+	if opts.Reconcile || opts.PR {
+		configLoader := config.NewConfigLoader(r.GitCommonDir)
+		cfg, err = configLoader.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+	}
 
-	// List all tasks
-	tasks, err := store.List()
+	// Detect merged tasks first if requested
+	if opts.Reconcile {
+		reconciled, err := reconcileTasks(ctx, r, cfg, store, nil, !opts.NoFetch, false)
+		if err != nil {
+			return nil, err
+		}
+		if c.Progress != nil && len(reconciled.Merged) > 0 {
+			printReconcileSummary(c.Progress, reconciled)
+			c.progressf("\n")
+		}
+	}
+
+	// List matching tasks
+	tasks, err := store.Find(task.Filter{
+		Agent: opts.Agent,
+		State: task.State(strings.ToUpper(opts.State)),
+		Group: opts.Group,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to list tasks: %w", err)
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
 	}
 
 	if len(tasks) == 0 {
-		if !opts.OutputJSON {
-			fmt.Println("No tasks found")
-		} else {
-			fmt.Println("[]")
-		}
-		return nil
+		return []TaskListItem{}, nil
 	}
 
 	// Create Git wrapper to check worktree status
 	g := git.New(r.WorkTreeRoot, false)
-	worktrees, err := g.WorktreeList()
+	worktrees, err := g.WorktreeList(ctx)
 	if err != nil {
 		// Don't fail if we can't list worktrees
 		worktrees = nil
@@ -93,8 +204,13 @@ func runList(opts *ListOptions) error {
 		worktreeMap[wt.Branch] = wt.Path
 	}
 
+	// Refresh the PR status of tasks with an open pull request
+	if opts.PR {
+		c.refreshPRStatuses(ctx, r, cfg, store, tasks)
+	}
+
 	// Build task list
-	var items []TaskListItem
+	items := []TaskListItem{}
 	for _, t := range tasks {
 		branchRef := t.Branch
 		if !strings.HasPrefix(branchRef, "refs/heads/") {
@@ -118,42 +234,40 @@ func runList(opts *ListOptions) error {
 			Branch:       t.Branch,
 			WorktreePath: wtPath,
 			CheckedOut:   checkedOut,
+			Group:        t.Group,
+		}
+		if opts.PR {
+			item.PRNumber = taskPRNumber(t)
+			item.PRStatus = t.PRStatus
 		}
 		items = append(items, item)
 	}
 
-	// Output result
-	if opts.OutputJSON {
-		data, _ := json.MarshalIndent(items, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		// Print table header
-		fmt.Printf("%-20s %-12s %-30s %-15s %-10s\n", "ID", "AGENT", "TITLE", "STATE", "CHECKED OUT")
-		fmt.Println(strings.Repeat("-", 90))
+	return items, nil
+}
 
-		// Print tasks
-		for _, item := range items {
-			title := item.Title
-			if len(title) > 30 {
-				title = title[:27] + "..."
-			}
+// refreshPRStatuses queries the forge for the pull requests of the tasks that have
+// one still open (as last seen) and updates the tasks in place. Failures are reported
+// as warnings and leave the last-seen status.
+func (c *Client) refreshPRStatuses(ctx context.Context, r *repo.Repo, cfg *config.Config, store task.Store, tasks []*task.Task) {
+	var f forge.Forge
+	for _, t := range tasks {
+		if taskPRNumber(t) == 0 || (t.PRStatus != nil && t.PRStatus.State != forge.PRStateOpen) {
+			continue
+		}
 
-			checkedOut := "no"
-			if item.CheckedOut {
-				checkedOut = "yes"
+		if f == nil {
+			var err error
+			g := git.New(r.WorkTreeRoot, cfg.VerboseGit).WithTimeouts(gitTimeouts(cfg))
+			f, err = openForge(ctx, g, cfg)
+			if err != nil {
+				c.progressf("Warning: cannot query the forge, showing the last-seen PR status: %v\n", err)
+				return
 			}
-
-			fmt.Printf("%-20s %-12s %-30s %-15s %-10s\n",
-				item.ID,
-				item.Agent,
-				title,
-				item.State,
-				checkedOut,
-			)
 		}
 
-		fmt.Printf("\nTotal: %d tasks\n", len(items))
+		if _, err := refreshPRStatus(ctx, r, cfg, store, f, t); err != nil {
+			c.progressf("Warning: failed to refresh the PR of task %s: %v\n", t.ID, err)
+		}
 	}
-
-	return nil
 }
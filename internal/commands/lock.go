@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/lock"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// LockStatusOptions contains options for the lock status command
+type LockStatusOptions struct {
+	RepoPath   string
+	All        bool
+	OutputJSON bool
+}
+
+// LockStatusResult represents the output of the lock status command
+type LockStatusResult struct {
+	Locks []*lock.Status `json:"locks"`
+}
+
+// LockBreakOptions contains options for the lock break command
+type LockBreakOptions struct {
+	RepoPath   string
+	Name       string
+	Force      bool
+	OutputJSON bool
+}
+
+// LockBreakResult represents the output of the lock break command
+type LockBreakResult struct {
+	Name    string         `json:"name"`
+	Removed []*lock.Status `json:"removed"`
+}
+
+// NewLockCmd creates the lock command
+func NewLockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Inspect and break AWT locks",
+		Long: `Commands for inspecting AWT locks.
+
+AWT holds a global lock while creating or removing worktrees, and a per-task
+lock while mutating a task. Each lock records its holder's PID, hostname,
+command line, task ID and acquire time.`,
+	}
+
+	cmd.AddCommand(NewLockStatusCmd())
+	cmd.AddCommand(NewLockBreakCmd())
+
+	return cmd
+}
+
+// NewLockStatusCmd creates the lock status command
+func NewLockStatusCmd() *cobra.Command {
+	opts := &LockStatusOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "List held locks and their holders",
+		Long: `List held locks with their holder's PID, hostname, command line, task ID and
+acquire time. Holders whose process is no longer running are marked dead.
+
+Example:
+  awt lock status
+  awt lock status --all --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLockStatus(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "include lock files that are not held")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+// NewLockBreakCmd creates the lock break command
+func NewLockBreakCmd() *cobra.Command {
+	opts := &LockBreakOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "break <name>",
+		Short: "Force-release a lock whose holder is dead",
+		Long: `Force-release a lock by removing its lock file.
+
+The lock name is "global" or a task ID (see 'awt lock status'). By default
+the lock is only broken if its holder's process is no longer running on this
+host. Use --force to break a lock held by a live or unverifiable process.
+
+Example:
+  awt lock break global
+  awt lock break 20250110-120000-abc123 --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+			return runLockBreak(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "break the lock even if its holder may still be running")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runLockStatus(opts *LockStatusOptions) error {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return errors.RepoNotFound(opts.RepoPath)
+	}
+
+	lm := lock.NewLockManager(r.GitCommonDir)
+	statuses, err := lm.List()
+	if err != nil {
+		return err
+	}
+
+	result := LockStatusResult{Locks: []*lock.Status{}}
+	for _, s := range statuses {
+		if s.Held || opts.All {
+			result.Locks = append(result.Locks, s)
+		}
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(result.Locks) == 0 {
+		fmt.Println("No locks held")
+		return nil
+	}
+
+	fmt.Printf("%-30s %-6s %-8s %-20s %-20s %s\n", "LOCK", "HELD", "PID", "HOST", "ACQUIRED", "COMMAND")
+	fmt.Println(strings.Repeat("-", 110))
+	for _, s := range result.Locks {
+		held := "no"
+		if s.Held {
+			held = "yes"
+		}
+		pid, host, acquired, command := "-", "-", "-", "-"
+		if s.Owner != nil {
+			pid = fmt.Sprintf("%d", s.Owner.PID)
+			if s.OwnerAlive != nil && !*s.OwnerAlive {
+				pid += " (dead)"
+			}
+			if s.Owner.Hostname != "" {
+				host = s.Owner.Hostname
+			}
+			if !s.Owner.AcquiredAt.IsZero() {
+				acquired = s.Owner.AcquiredAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Owner.Command != "" {
+				command = s.Owner.Command
+			}
+		}
+		fmt.Printf("%-30s %-6s %-8s %-20s %-20s %s\n", s.Name, held, pid, host, acquired, command)
+	}
+
+	return nil
+}
+
+func runLockBreak(opts *LockBreakOptions) error {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return errors.RepoNotFound(opts.RepoPath)
+	}
+
+	lm := lock.NewLockManager(r.GitCommonDir)
+	removed, err := lm.Break(opts.Name, opts.Force)
+	if err != nil {
+		var held *lock.HeldError
+		if stderrors.As(err, &held) {
+			return errors.New(
+				errors.ExitLockHeld,
+				fmt.Sprintf("Refusing to break lock: %s", held.Error()),
+				"Wait for the holder to finish, or use --force if it is hung.",
+				err,
+			)
+		}
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(LockBreakResult{Name: opts.Name, Removed: removed}, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		for _, s := range removed {
+			if s.Owner != nil {
+				fmt.Printf("Broke lock %s held by %s\n", s.Name, s.Owner)
+			} else {
+				fmt.Printf("Removed lock file %s\n", s.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// newLockManager creates a lock manager that honors the configured lock timeout
+func newLockManager(r *repo.Repo, cfg *config.Config) *lock.LockManager {
+	return lock.NewLockManagerWithTimeout(r.GitCommonDir, time.Duration(cfg.LockTimeout)*time.Second)
+}
+
+// gitTimeouts returns the configured timeouts for git commands that talk to remotes or rewrite history
+func gitTimeouts(cfg *config.Config) git.Timeouts {
+	return git.Timeouts{
+		Fetch:  time.Duration(cfg.FetchTimeout) * time.Second,
+		Push:   time.Duration(cfg.PushTimeout) * time.Second,
+		Rebase: time.Duration(cfg.RebaseTimeout) * time.Second,
+	}
+}
+
+// acquireTaskLock acquires the per-task lock that serializes commands mutating a task.
+// If another process holds the lock past the timeout, a LOCK_HELD error naming the holder is returned.
+func acquireTaskLock(ctx context.Context, r *repo.Repo, cfg *config.Config, taskID string) (*lock.Lock, error) {
+	lm := newLockManager(r, cfg)
+	taskLock, err := lm.AcquireTask(ctx, taskID)
+	if err != nil {
+		var held *lock.HeldError
+		if stderrors.As(err, &held) {
+			return nil, errors.LockHeld("task "+taskID, held.Holder)
+		}
+		return nil, fmt.Errorf("failed to acquire task lock: %w", err)
+	}
+	return taskLock, nil
+}
+
+// updateTask applies fn to the freshly loaded task and saves it under the task lock,
+// reapplying fn if a writer that does not take the lock saved in between (see task.Update).
+// The caller must not already hold the task lock.
+func updateTask(ctx context.Context, r *repo.Repo, cfg *config.Config, store task.Store, taskID string, fn func(*task.Task) error) (*task.Task, error) {
+	taskLock, err := acquireTaskLock(ctx, r, cfg, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
+
+	return task.Update(store, taskLock, taskID, fn)
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/logger"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// TaskLogOptions contains options for the task log command
+type TaskLogOptions struct {
+	RepoPath   string
+	TaskID     string
+	Branch     string
+	OutputJSON bool
+}
+
+// TaskLogResult represents the output of the task log command
+type TaskLogResult struct {
+	TaskID string       `json:"task_id"`
+	Events []task.Event `json:"events"`
+}
+
+// NewTaskLogCmd creates the task log command
+func NewTaskLogCmd() *cobra.Command {
+	opts := &TaskLogOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "log [task-id]",
+		Short: "Show what happened to a task",
+		Long: `Show the task's event log as a timeline: start, commits, syncs, pushes,
+pull requests, exec commands with their exit codes, handoff and other
+lifecycle changes, with who ran each one.
+
+The task can be specified by:
+  1. Providing the task ID as an argument
+  2. Using --branch flag
+  3. Inferring from current worktree (if in a worktree)
+
+Example:
+  awt task log 20250110-120000-abc123
+  awt task log --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.TaskID = args[0]
+			}
+			return runTaskLog(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.Branch, "branch", "", "branch name")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runTaskLog(opts *TaskLogOptions) error {
+	result, err := cliClient(opts.OutputJSON).Log(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(result.Events) == 0 {
+		fmt.Printf("No events recorded for task %s\n", result.TaskID)
+		return nil
+	}
+
+	fmt.Printf("Task: %s\n", result.TaskID)
+	for _, e := range result.Events {
+		fmt.Printf("  %s  %-8s %s", e.Time.Format("2006-01-02 15:04:05"), e.Type, e.Summary())
+		if e.Actor != "" {
+			fmt.Printf("  (by %s)", e.Actor)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// Log returns the task's recorded events, oldest first
+func (c *Client) Log(ctx context.Context, opts *TaskLogOptions) (*TaskLogResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	// Determine task ID
+	taskID := opts.TaskID
+
+	if taskID == "" && opts.Branch != "" {
+		// Look up the task that owns the branch
+		taskID = taskIDForBranch(store, opts.Branch)
+		if taskID == "" {
+			return nil, fmt.Errorf("no task found for branch: %s", opts.Branch)
+		}
+	}
+
+	if taskID == "" {
+		// Try to infer from current worktree
+		taskID, err = inferTaskIDFromCurrentDirectory(ctx, r)
+		if err != nil {
+			return nil, fmt.Errorf("could not infer task ID: %w\nProvide task ID as argument or use --branch flag", err)
+		}
+	}
+
+	// Require the task to exist so a mistyped ID is reported rather than shown as an empty log
+	if _, err := store.Load(taskID); err != nil {
+		return nil, errors.InvalidTaskID(taskID)
+	}
+
+	events, err := task.NewEventLog(r.GitCommonDir).Read(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := TaskLogResult{TaskID: taskID, Events: events}
+
+	return &result, nil
+}
+
+// recordEvent appends an event to the task's journal, stamped with the current actor.
+// Failures are logged rather than returned so the journal never fails the command it describes.
+func recordEvent(r *repo.Repo, taskID string, e task.Event) {
+	if e.Actor == "" {
+		e.Actor = currentActor()
+	}
+	if err := task.NewEventLog(r.GitCommonDir).Append(taskID, e); err != nil {
+		logger.Warn("Failed to record %s event for task %s: %v", e.Type, taskID, err)
+	}
+}
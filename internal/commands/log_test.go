@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestTaskCommandsRecordEvents(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+	t.Setenv("AWT_ACTOR", "reviewer-bot")
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Logged task",
+		Base:         "HEAD",
+		ID:           "logged-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tk, err := store.Load("logged-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tk.WorktreePath, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := runTaskCommit(&CommitOptions{RepoPath: repoPath, TaskID: "logged-task", Message: "Add feature", All: true, OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskCommit() failed: %v", err)
+	}
+
+	events, err := task.NewEventLog(filepath.Join(repoPath, ".git")).Read("logged-task")
+	if err != nil {
+		t.Fatalf("failed to read event log: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	if events[0].Type != task.EventStart || events[0].Actor != "reviewer-bot" {
+		t.Errorf("first event = %+v, want start by reviewer-bot", events[0])
+	}
+	tk, _ = store.Load("logged-task")
+	if events[1].Type != task.EventCommit || events[1].SHA != tk.LastCommit || events[1].Message != "Add feature" {
+		t.Errorf("second event = %+v, want commit %s", events[1], tk.LastCommit)
+	}
+
+	if err := runTaskLog(&TaskLogOptions{RepoPath: repoPath, TaskID: "logged-task", OutputJSON: true}); err != nil {
+		t.Errorf("runTaskLog() failed: %v", err)
+	}
+	if err := runTaskLog(&TaskLogOptions{RepoPath: repoPath, TaskID: "no-such-task"}); err == nil {
+		t.Error("expected error for an unknown task")
+	}
+}
@@ -0,0 +1,315 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/forge"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// PROptions contains options for the task pr command
+type PROptions struct {
+	RepoPath   string
+	TaskID     string
+	Branch     string
+	OutputJSON bool
+}
+
+// PRResult represents the output of the task pr command
+type PRResult struct {
+	TaskID    string `json:"task_id"`
+	Number    int    `json:"number"`
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	State     string `json:"state"`
+	Draft     bool   `json:"draft,omitempty"`
+	HeadSHA   string `json:"head_sha,omitempty"`
+	Mergeable *bool  `json:"mergeable,omitempty"`
+	// CheckState combines the checks, see forge.CombinedCheckState
+	CheckState forge.CheckState `json:"check_state,omitempty"`
+	Checks     []forge.Check    `json:"checks"`
+	// ReviewDecision sums up the reviews, see forge.ReviewDecision
+	ReviewDecision forge.ReviewState `json:"review_decision,omitempty"`
+	Reviews        []forge.Review    `json:"reviews"`
+	Comments       []forge.Comment   `json:"comments"`
+}
+
+// NewTaskPRCmd creates the task pr command
+func NewTaskPRCmd() *cobra.Command {
+	opts := &PROptions{}
+
+	cmd := &cobra.Command{
+		Use:   "pr [task-id]",
+		Short: "Show the status of a task's pull request",
+		Long: `Show the status of a task's pull request: CI checks, reviews,
+mergeability and comments, as reported by the forge API.
+
+The task must have a pull request opened by 'awt task handoff'. The status is
+saved on the task as its last-seen PR status, which 'awt list --pr' shows.
+
+The task can be specified by:
+  1. Providing the task ID as an argument
+  2. Using --branch flag
+  3. Inferring from current worktree (if in a worktree)
+
+Example:
+  awt task pr 20250110-120000-abc123
+  awt task pr --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.TaskID = args[0]
+			}
+			return runTaskPR(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.Branch, "branch", "", "branch name")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runTaskPR(opts *PROptions) error {
+	result, err := cliClient(opts.OutputJSON).PR(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("PR #%d: %s\n", result.Number, result.Title)
+	fmt.Printf("  URL: %s\n", result.URL)
+	state := result.State
+	if result.Draft {
+		state += " (draft)"
+	}
+	fmt.Printf("  State: %s\n", state)
+	if result.Mergeable != nil {
+		if *result.Mergeable {
+			fmt.Printf("  Mergeable: yes\n")
+		} else {
+			fmt.Printf("  Mergeable: no (conflicts)\n")
+		}
+	}
+
+	if len(result.Checks) == 0 {
+		fmt.Printf("  Checks: none\n")
+	} else {
+		fmt.Printf("  Checks: %s\n", result.CheckState)
+		for _, c := range result.Checks {
+			fmt.Printf("    %-10s %s\n", c.State, c.Name)
+		}
+	}
+
+	decision := string(result.ReviewDecision)
+	if decision == "" {
+		decision = "none"
+	}
+	fmt.Printf("  Review: %s\n", decision)
+	for _, r := range result.Reviews {
+		fmt.Printf("    %-18s %s\n", r.State, r.Author)
+	}
+
+	fmt.Printf("  Comments: %d\n", len(result.Comments))
+
+	return nil
+}
+
+// PR queries the forge for the status of the task's pull request and saves it on the task
+func (c *Client) PR(ctx context.Context, opts *PROptions) (*PRResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	// Determine task ID
+	taskID, err := resolveTaskID(ctx, r, store, opts.TaskID, opts.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load task
+	t, err := store.Load(taskID)
+	if err != nil {
+		return nil, errors.InvalidTaskID(taskID)
+	}
+	if taskPRNumber(t) == 0 {
+		return nil, errors.NoPullRequest(t.ID)
+	}
+
+	g := git.New(r.WorkTreeRoot, cfg.VerboseGit).WithTimeouts(gitTimeouts(cfg))
+	f, err := openForge(ctx, g, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query the forge: %w", err)
+	}
+
+	return refreshPRStatus(ctx, r, cfg, store, f, t)
+}
+
+// refreshPRStatus queries the forge for the task's pull request and saves the
+// snapshot on the task, updating t to the saved record
+func refreshPRStatus(ctx context.Context, r *repo.Repo, cfg *config.Config, store task.Store, f forge.Forge, t *task.Task) (*PRResult, error) {
+	number := taskPRNumber(t)
+	result, err := fetchPRStatus(ctx, f, number)
+	if err != nil {
+		return nil, err
+	}
+	result.TaskID = t.ID
+
+	snapshot := result.snapshot(time.Now())
+	updated, err := updateTask(ctx, r, cfg, store, t.ID, func(t *task.Task) error {
+		t.PRNumber = number
+		t.PRStatus = snapshot
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task metadata: %w", err)
+	}
+	*t = *updated
+	return result, nil
+}
+
+// fetchPRStatus queries the forge for a pull request, its checks, reviews and comments
+func fetchPRStatus(ctx context.Context, f forge.Forge, number int) (*PRResult, error) {
+	pr, err := f.GetPR(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR #%d: %w", number, err)
+	}
+
+	ref := pr.HeadSHA
+	if ref == "" {
+		ref = pr.Head
+	}
+	checks, err := f.ListChecks(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checks of PR #%d: %w", number, err)
+	}
+	reviews, err := f.ListReviews(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews of PR #%d: %w", number, err)
+	}
+	comments, err := f.ListComments(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments of PR #%d: %w", number, err)
+	}
+
+	return &PRResult{
+		Number:         pr.Number,
+		URL:            pr.URL,
+		Title:          pr.Title,
+		State:          pr.State,
+		Draft:          pr.Draft,
+		HeadSHA:        pr.HeadSHA,
+		Mergeable:      pr.Mergeable,
+		CheckState:     forge.CombinedCheckState(checks),
+		Checks:         checks,
+		ReviewDecision: forge.ReviewDecision(reviews),
+		Reviews:        reviews,
+		Comments:       comments,
+	}, nil
+}
+
+// snapshot returns the PR status to save on the task
+func (res *PRResult) snapshot(at time.Time) *task.PRStatus {
+	return &task.PRStatus{
+		State:     res.State,
+		Draft:     res.Draft,
+		HeadSHA:   res.HeadSHA,
+		Checks:    string(res.CheckState),
+		Review:    string(res.ReviewDecision),
+		Mergeable: res.Mergeable,
+		Comments:  len(res.Comments),
+		CheckedAt: at,
+	}
+}
+
+// prURLNumberPattern matches the pull request number in PR URLs of the supported forges
+var prURLNumberPattern = regexp.MustCompile(`/(?:pull|pulls|merge_requests|pull-requests)/(\d+)/?$`)
+
+// taskPRNumber returns the task's pull request number, or 0 if it has none. Tasks
+// handed off before PR numbers were recorded have it parsed from their PR URL.
+func taskPRNumber(t *task.Task) int {
+	if t.PRNumber != 0 {
+		return t.PRNumber
+	}
+	if m := prURLNumberPattern.FindStringSubmatch(t.PRURL); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// prStatusLabel describes a task's last-seen PR status for the list table,
+// e.g. "#42 passing, approved"
+func prStatusLabel(number int, s *task.PRStatus) string {
+	if number == 0 {
+		return "-"
+	}
+	label := fmt.Sprintf("#%d", number)
+	if s == nil {
+		return label
+	}
+	if s.State != forge.PRStateOpen {
+		return label + " " + s.State
+	}
+
+	var parts []string
+	if s.Draft {
+		parts = append(parts, "draft")
+	}
+	switch forge.CheckState(s.Checks) {
+	case forge.CheckSuccess:
+		parts = append(parts, "passing")
+	case forge.CheckFailure:
+		parts = append(parts, "failing")
+	case forge.CheckPending:
+		parts = append(parts, "pending")
+	}
+	switch forge.ReviewState(s.Review) {
+	case forge.ReviewApproved:
+		parts = append(parts, "approved")
+	case forge.ReviewChangesRequested:
+		parts = append(parts, "changes requested")
+	}
+	if s.Mergeable != nil && !*s.Mergeable {
+		parts = append(parts, "conflicts")
+	}
+	if len(parts) == 0 {
+		parts = append(parts, s.State)
+	}
+	return label + " " + strings.Join(parts, ", ")
+}
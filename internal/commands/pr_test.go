@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/forge"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+// newTestForgeRepo creates a test repository whose origin is on github.com and
+// whose repo config points the github.com forge at a stand-in API serving mux
+func newTestForgeRepo(t *testing.T, mux *http.ServeMux) string {
+	t.Helper()
+	repoPath, cleanup := setupTestRepo(t)
+	t.Cleanup(cleanup)
+
+	if out, err := exec.Command("git", "-C", repoPath, "remote", "add", "origin", "git@github.com:owner/repo.git").CombinedOutput(); err != nil {
+		t.Fatalf("git remote add failed: %v\n%s", err, out)
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	configPath := filepath.Join(repoPath, ".git", "awt", "config.json")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"forges": {"github.com": {"token": "secret", "api_url": "` + server.URL + `"}}}`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return repoPath
+}
+
+func TestClientPR(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/42", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"number": 42, "html_url": "https://github.com/owner/repo/pull/42", "title": "Add feature",
+			"state": "open", "head": {"ref": "awt/claude/pr-task", "sha": "abc123"}, "base": {"ref": "main"}, "mergeable": true}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/commits/abc123/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"check_runs": [{"name": "build", "status": "completed", "conclusion": "success"}]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/commits/abc123/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"statuses": []}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/42/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 1, "user": {"login": "alice"}, "state": "APPROVED"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/42/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 10, "user": {"login": "alice"}, "body": "Nit", "path": "main.go", "line": 3}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/42/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	repoPath := newTestForgeRepo(t, mux)
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tasks := []*task.Task{
+		// Handed off before PR numbers were recorded, so only the URL is known
+		{ID: "pr-task", Agent: "claude", Title: "Add feature", Branch: "awt/claude/pr-task", Base: "main", State: task.StateHandoffReady,
+			PRURL: "https://github.com/owner/repo/pull/42"},
+		{ID: "merged-task", Agent: "claude", Title: "Merged", Branch: "awt/claude/merged-task", Base: "main", State: task.StateHandoffReady,
+			PRNumber: 7, PRStatus: &task.PRStatus{State: forge.PRStateMerged}},
+		{ID: "no-pr-task", Agent: "claude", Title: "No PR", Branch: "awt/claude/no-pr-task", Base: "main", State: task.StateHandoffReady},
+	}
+	for _, tk := range tasks {
+		tk.CreatedAt = time.Now()
+		if err := store.Save(tk); err != nil {
+			t.Fatalf("failed to save task: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	c := &Client{}
+
+	result, err := c.PR(ctx, &PROptions{RepoPath: repoPath, TaskID: "pr-task"})
+	if err != nil {
+		t.Fatalf("PR() failed: %v", err)
+	}
+	if result.Number != 42 || result.State != forge.PRStateOpen || result.Mergeable == nil || !*result.Mergeable {
+		t.Errorf("unexpected PR: %+v", result)
+	}
+	if result.CheckState != forge.CheckSuccess || len(result.Checks) != 1 {
+		t.Errorf("checks = %q %+v, want one successful check", result.CheckState, result.Checks)
+	}
+	if result.ReviewDecision != forge.ReviewApproved || len(result.Comments) != 1 {
+		t.Errorf("review decision = %q with %d comments, want approved with 1", result.ReviewDecision, len(result.Comments))
+	}
+
+	// The snapshot and the PR number are saved on the task
+	tk, err := store.Load("pr-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if tk.PRNumber != 42 || tk.PRStatus == nil {
+		t.Fatalf("task PR = #%d %+v, want #42 with a status", tk.PRNumber, tk.PRStatus)
+	}
+	if tk.PRStatus.Checks != "success" || tk.PRStatus.Review != "approved" || tk.PRStatus.Comments != 1 || tk.PRStatus.CheckedAt.IsZero() {
+		t.Errorf("unexpected PR status: %+v", tk.PRStatus)
+	}
+
+	_, err = c.PR(ctx, &PROptions{RepoPath: repoPath, TaskID: "no-pr-task"})
+	var awtErr *errors.AWTError
+	if !stderrors.As(err, &awtErr) || awtErr.Code != errors.ExitInvalidTaskState {
+		t.Errorf("PR() for a task without a PR: error = %v, want INVALID_TASK_STATE", err)
+	}
+
+	// list --pr shows the statuses; the merged PR is not queried again
+	items, err := c.List(ctx, &ListOptions{RepoPath: repoPath, PR: true})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	labels := make(map[string]string)
+	for _, item := range items {
+		labels[item.ID] = prStatusLabel(item.PRNumber, item.PRStatus)
+	}
+	want := map[string]string{
+		"pr-task":     "#42 passing, approved",
+		"merged-task": "#7 merged",
+		"no-pr-task":  "-",
+	}
+	for id, label := range want {
+		if labels[id] != label {
+			t.Errorf("PR label of %s = %q, want %q", id, labels[id], label)
+		}
+	}
+}
+
+func TestTaskPRNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		task task.Task
+		want int
+	}{
+		{"recorded number", task.Task{PRNumber: 5, PRURL: "https://github.com/owner/repo/pull/6"}, 5},
+		{"GitHub URL", task.Task{PRURL: "https://github.com/owner/repo/pull/6"}, 6},
+		{"GitLab URL", task.Task{PRURL: "https://gitlab.com/group/project/-/merge_requests/7"}, 7},
+		{"Gitea URL", task.Task{PRURL: "https://codeberg.org/owner/repo/pulls/8"}, 8},
+		{"Bitbucket Server URL", task.Task{PRURL: "https://bitbucket.example.com/projects/PROJ/repos/repo/pull-requests/9/"}, 9},
+		{"compare URL", task.Task{PRURL: "https://github.com/owner/repo/compare/main...feature?expand=1"}, 0},
+		{"no PR", task.Task{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := taskPRNumber(&tt.task); got != tt.want {
+				t.Errorf("taskPRNumber() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPRStatusLabel(t *testing.T) {
+	conflicted := false
+	tests := []struct {
+		name   string
+		number int
+		status *task.PRStatus
+		want   string
+	}{
+		{"no PR", 0, nil, "-"},
+		{"never checked", 3, nil, "#3"},
+		{"closed", 3, &task.PRStatus{State: "closed"}, "#3 closed"},
+		{"open without checks or reviews", 3, &task.PRStatus{State: "open"}, "#3 open"},
+		{"failing draft", 3, &task.PRStatus{State: "open", Draft: true, Checks: "failure"}, "#3 draft, failing"},
+		{
+			name:   "changes requested with conflicts",
+			number: 3,
+			status: &task.PRStatus{State: "open", Checks: "pending", Review: "changes_requested", Mergeable: &conflicted},
+			want:   "#3 pending, changes requested, conflicts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prStatusLabel(tt.number, tt.status); got != tt.want {
+				t.Errorf("prStatusLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+// PRSummaryFile is the file an agent can write in the root of its worktree to
+// describe its work; its contents are passed to the PR body template
+const PRSummaryFile = "AWT_SUMMARY.md"
+
+// PRTemplateData is the data PR body templates are rendered with
+type PRTemplateData struct {
+	// Task is the task being handed off
+	Task *task.Task
+	// Base is the branch the PR targets, without the remote prefix
+	Base string
+	// Commits are the task's commits on top of its base, oldest first
+	Commits []git.Commit
+	// Diffstat is the output of git diff --stat against the base
+	Diffstat string
+	// Events is the task's event log
+	Events []task.Event
+	// Summary is the contents of PRSummaryFile, or empty if the agent wrote none
+	Summary string
+}
+
+// defaultPRTemplate is used when neither the repo nor the user has a template
+const defaultPRTemplate = `Task: {{.Task.ID}}
+Agent: {{.Task.Agent}}
+Branch: {{.Task.Branch}}
+{{- if .Summary}}
+
+## Summary
+
+{{.Summary}}
+{{- end}}
+{{- if .Commits}}
+
+## Commits
+
+{{range .Commits}}- {{short .SHA}} {{.Subject}}
+{{end}}
+{{- end}}
+{{- if .Diffstat}}
+
+## Changes
+
+` + "```" + `
+{{.Diffstat}}
+` + "```" + `
+{{- end}}
+`
+
+// prBody is a rendered PR body and the PR options the template set
+type prBody struct {
+	Body      string
+	Draft     bool
+	Reviewers []string
+	Labels    []string
+}
+
+// renderPRBody renders a PR body template. Besides the text/template builtins,
+// templates can call short to abbreviate a SHA, and draft, reviewer and label to
+// set PR options; those output nothing.
+func renderPRBody(text string, data *PRTemplateData) (*prBody, error) {
+	out := &prBody{}
+	funcs := template.FuncMap{
+		"short": func(sha string) string {
+			if len(sha) > 7 {
+				return sha[:7]
+			}
+			return sha
+		},
+		"draft": func() string {
+			out.Draft = true
+			return ""
+		},
+		"reviewer": func(names ...string) string {
+			out.Reviewers = appendUnique(out.Reviewers, names...)
+			return ""
+		},
+		"label": func(names ...string) string {
+			out.Labels = appendUnique(out.Labels, names...)
+			return ""
+		},
+	}
+
+	tmpl, err := template.New("pr").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	out.Body = strings.TrimSpace(buf.String())
+	return out, nil
+}
+
+// appendUnique appends the values not already in list, skipping empty ones
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// prTemplateData collects the template data for the task from its worktree and event log
+func prTemplateData(ctx context.Context, r *repo.Repo, g *git.Git, t *task.Task) *PRTemplateData {
+	data := &PRTemplateData{
+		Task: t,
+		Base: stripRemotePrefix(t.Base),
+	}
+	if commits, err := g.Log(ctx, t.Base+"..HEAD"); err == nil {
+		data.Commits = commits
+	}
+	if diffstat, err := g.DiffStat(ctx, t.Base, "HEAD"); err == nil {
+		data.Diffstat = diffstat
+	}
+	if events, err := task.NewEventLog(r.GitCommonDir).Read(t.ID); err == nil {
+		data.Events = events
+	}
+	if summary, err := os.ReadFile(filepath.Join(t.WorktreePath, PRSummaryFile)); err == nil {
+		data.Summary = strings.TrimSpace(string(summary))
+	}
+	return data
+}
+
+// buildPRBody renders the repo or user PR template for the task. A template that
+// cannot be read or rendered is reported and the default template is used instead.
+func (c *Client) buildPRBody(ctx context.Context, r *repo.Repo, g *git.Git, t *task.Task) *prBody {
+	data := prTemplateData(ctx, r, g, t)
+
+	if path := config.NewConfigLoader(r.GitCommonDir).PRTemplatePath(); path != "" {
+		text, err := os.ReadFile(path)
+		if err == nil {
+			body, renderErr := renderPRBody(string(text), data)
+			if renderErr == nil {
+				return body
+			}
+			err = renderErr
+		}
+		c.progressf("Warning: ignoring PR template %s: %v\n", path, err)
+	}
+
+	body, err := renderPRBody(defaultPRTemplate, data)
+	if err != nil {
+		return &prBody{Body: fmt.Sprintf("Task: %s\nAgent: %s\nBranch: %s", t.ID, t.Agent, t.Branch)}
+	}
+	return body
+}
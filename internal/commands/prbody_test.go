@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestRenderPRBody(t *testing.T) {
+	data := &PRTemplateData{
+		Task: &task.Task{ID: "task-1", Agent: "claude", Branch: "awt/claude/task-1", Title: "Add feature"},
+		Base: "main",
+		Commits: []git.Commit{
+			{SHA: "0123456789abcdef", Subject: "Add parser"},
+			{SHA: "fedcba9876543210", Subject: "Add tests"},
+		},
+		Diffstat: " parser.go | 10 ++++++++++\n 1 file changed, 10 insertions(+)",
+		Summary:  "Added a parser.",
+	}
+
+	body, err := renderPRBody(defaultPRTemplate, data)
+	if err != nil {
+		t.Fatalf("renderPRBody() with the default template failed: %v", err)
+	}
+	for _, want := range []string{
+		"Task: task-1\nAgent: claude\nBranch: awt/claude/task-1",
+		"## Summary\n\nAdded a parser.",
+		"- 0123456 Add parser\n- fedcba9 Add tests",
+		"parser.go | 10",
+	} {
+		if !strings.Contains(body.Body, want) {
+			t.Errorf("default body does not contain %q:\n%s", want, body.Body)
+		}
+	}
+	if body.Draft || len(body.Reviewers) > 0 || len(body.Labels) > 0 {
+		t.Errorf("default template should not set PR options: %+v", body)
+	}
+
+	// Without commits, a diffstat or a summary only the task lines remain
+	body, err = renderPRBody(defaultPRTemplate, &PRTemplateData{Task: data.Task})
+	if err != nil {
+		t.Fatalf("renderPRBody() failed: %v", err)
+	}
+	if body.Body != "Task: task-1\nAgent: claude\nBranch: awt/claude/task-1" {
+		t.Errorf("body = %q, want only the task lines", body.Body)
+	}
+}
+
+func TestRenderPRBodyOptions(t *testing.T) {
+	data := &PRTemplateData{Task: &task.Task{ID: "task-1", Title: "Add feature"}}
+
+	text := `{{draft}}{{reviewer "alice" "bob"}}{{reviewer "alice"}}{{label "agent"}}
+{{.Task.Title}} ({{.Task.ID}})`
+	body, err := renderPRBody(text, data)
+	if err != nil {
+		t.Fatalf("renderPRBody() failed: %v", err)
+	}
+	if body.Body != "Add feature (task-1)" {
+		t.Errorf("body = %q, want the option calls to output nothing", body.Body)
+	}
+	if !body.Draft {
+		t.Error("draft was not set")
+	}
+	if strings.Join(body.Reviewers, ",") != "alice,bob" {
+		t.Errorf("reviewers = %v, want [alice bob]", body.Reviewers)
+	}
+	if strings.Join(body.Labels, ",") != "agent" {
+		t.Errorf("labels = %v, want [agent]", body.Labels)
+	}
+
+	if _, err := renderPRBody("{{.Task.Missing}}", data); err == nil {
+		t.Error("renderPRBody() with an unknown field should fail")
+	}
+	if _, err := renderPRBody("{{if}}", data); err == nil {
+		t.Error("renderPRBody() with invalid syntax should fail")
+	}
+}
+
+func TestPRTemplateData(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	base, err := exec.Command("git", "-C", repoPath, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git rev-parse failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "feature.go"), []byte("package feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_ = exec.Command("git", "-C", repoPath, "add", "feature.go").Run()
+	if out, err := exec.Command("git", "-C", repoPath, "commit", "-m", "Add feature").CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, PRSummaryFile), []byte("\nAdded the feature package.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := repo.DiscoverRepo(repoPath)
+	if err != nil {
+		t.Fatalf("DiscoverRepo() failed: %v", err)
+	}
+	tk := &task.Task{ID: "task-1", Base: strings.TrimSpace(string(base)), WorktreePath: repoPath}
+	recordEvent(r, tk.ID, task.Event{Type: task.EventStart})
+
+	data := prTemplateData(context.Background(), r, git.New(repoPath, false), tk)
+	if len(data.Commits) != 1 || data.Commits[0].Subject != "Add feature" {
+		t.Errorf("commits = %+v, want the one task commit", data.Commits)
+	}
+	if !strings.Contains(data.Diffstat, "feature.go") {
+		t.Errorf("diffstat = %q, want feature.go", data.Diffstat)
+	}
+	if len(data.Events) != 1 || data.Events[0].Type != task.EventStart {
+		t.Errorf("events = %+v, want the start event", data.Events)
+	}
+	if data.Summary != "Added the feature package." {
+		t.Errorf("summary = %q, want the trimmed summary file", data.Summary)
+	}
+}
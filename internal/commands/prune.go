@@ -1,13 +1,15 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 
+	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/lock"
 	"github.com/kernel-labs-ai/awt/internal/repo"
 	"github.com/kernel-labs-ai/awt/internal/task"
 	"github.com/spf13/cobra"
@@ -17,14 +19,20 @@ import (
 type PruneOptions struct {
 	RepoPath   string
 	DryRun     bool
+	Reconcile  bool
+	NoFetch    bool
 	OutputJSON bool
 }
 
 // PruneResult represents the output of the prune command
 type PruneResult struct {
 	PrunedWorktrees int      `json:"pruned_worktrees"`
+	MergedTasks     []string `json:"merged_tasks,omitempty"`
 	DeletedTasks    []string `json:"deleted_tasks,omitempty"`
-	DeletedLocks    []string `json:"deleted_locks,omitempty"`
+	// DeletedLocks are the stale lock files cleaned up (see lock.LockManager.RemoveStale)
+	DeletedLocks []string `json:"deleted_locks,omitempty"`
+	// StaleLocks explains why each deleted lock was judged stale
+	StaleLocks []*lock.StaleLock `json:"stale_locks,omitempty"`
 }
 
 // NewPruneCmd creates the prune command
@@ -37,12 +45,20 @@ func NewPruneCmd() *cobra.Command {
 		Long: `Clean up orphaned task metadata and stale locks.
 
 This command performs the following cleanup operations:
-  1. Runs git worktree prune to remove deleted worktrees
-  2. Removes task metadata for non-existent worktrees
-  3. Cleans up stale lock files
+  1. Marks merged tasks as MERGED (only with --reconcile)
+  2. Runs git worktree prune to remove deleted worktrees
+  3. Removes metadata of active tasks whose worktree no longer exists
+  4. Cleans up stale lock files
+
+A lock file is stale if its holder exited without releasing it: a flock-based
+lock that can be acquired without waiting but still records an owner, or an
+O_EXCL fallback lock whose recorded holder ran on this host and is no longer
+running. Fallback lock files are deleted; flock lock files are kept and only
+their owner is cleared.
 
 Example:
   awt prune
+  awt prune --reconcile
   awt prune --dry-run  # preview what would be cleaned`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runPrune(opts)
@@ -51,50 +67,108 @@ Example:
 
 	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
 	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "preview what would be cleaned without making changes")
+	cmd.Flags().BoolVar(&opts.Reconcile, "reconcile", false, "detect merged tasks and mark them MERGED first")
+	cmd.Flags().BoolVar(&opts.NoFetch, "no-fetch", false, "skip git fetch when reconciling")
 	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
 
 	return cmd
 }
 
 func runPrune(opts *PruneOptions) error {
+	result, err := cliClient(opts.OutputJSON).Prune(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Println("\nPrune completed!")
+		if opts.DryRun {
+			fmt.Println("  Mode: dry-run (no changes made)")
+		}
+		if opts.Reconcile {
+			fmt.Printf("  Tasks marked merged: %d\n", len(result.MergedTasks))
+		}
+		fmt.Printf("  Orphaned tasks deleted: %d\n", len(result.DeletedTasks))
+		fmt.Printf("  Stale locks cleaned up: %d\n", len(result.DeletedLocks))
+	}
+
+	return nil
+}
+
+// Prune removes stale worktrees, orphaned task metadata and stale locks
+func (c *Client) Prune(ctx context.Context, opts *PruneOptions) (*PruneResult, error) {
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
 	}
 
-	store := task.NewTaskStore(r.GitCommonDir)
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
 
 	// Create Git wrapper
 	g := git.New(r.WorkTreeRoot, false)
 
 	result := PruneResult{}
 
+	// Step 0: Detect merged tasks if requested
+	if opts.Reconcile {
+		if !opts.DryRun {
+			c.progressf("Reconciling merged tasks...\n")
+		}
+
+		configLoader := config.NewConfigLoader(r.GitCommonDir)
+		cfg, err := configLoader.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+
+		reconciled, err := reconcileTasks(ctx, r, cfg, store, nil, !opts.NoFetch, opts.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range reconciled.Merged {
+			if opts.DryRun {
+				c.progressf("Would mark merged task: %s (%s)\n", item.TaskID, item.Method)
+			} else {
+				c.progressf("Marked merged task: %s (%s)\n", item.TaskID, item.Method)
+			}
+			result.MergedTasks = append(result.MergedTasks, item.TaskID)
+		}
+	}
+
 	// Step 1: Run git worktree prune
-	if !opts.OutputJSON && !opts.DryRun {
-		fmt.Println("Pruning Git worktrees...")
+	if !opts.DryRun {
+		c.progressf("Pruning Git worktrees...\n")
 	}
 
 	if !opts.DryRun {
-		pruneResult, err := g.WorktreePrune()
+		pruneResult, err := g.WorktreePrune(ctx)
 		if err != nil || pruneResult.ExitCode != 0 {
 			// Don't fail if prune fails - just warn
-			if !opts.OutputJSON {
-				fmt.Printf("Warning: git worktree prune failed: %s\n", pruneResult.Stderr)
-			}
+			c.progressf("Warning: git worktree prune failed: %s\n", pruneResult.Stderr)
 		} else {
 			result.PrunedWorktrees = 1 // git worktree prune doesn't report count
 		}
 	}
 
 	// Step 2: Find orphaned task metadata
-	if !opts.OutputJSON && !opts.DryRun {
-		fmt.Println("Checking for orphaned task metadata...")
+	if !opts.DryRun {
+		c.progressf("Checking for orphaned task metadata...\n")
 	}
 
 	tasks, err := store.List()
 	if err != nil {
-		return fmt.Errorf("failed to list tasks: %w", err)
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
 	}
 
 	for _, t := range tasks {
@@ -102,81 +176,61 @@ func runPrune(opts *PruneOptions) error {
 			// Task has no worktree, skip
 			continue
 		}
+		if t.State == task.StateHandoffReady || t.State == task.StateMerged || t.State == task.StateAbandoned {
+			// Finished tasks have no worktree by design; older records may still name the removed one
+			continue
+		}
 
 		// Check if worktree exists
 		if _, err := os.Stat(t.WorktreePath); os.IsNotExist(err) {
 			// Worktree doesn't exist, delete task metadata
 			if !opts.DryRun {
-				if !opts.OutputJSON {
-					fmt.Printf("Deleting orphaned task: %s\n", t.ID)
-				}
+				c.progressf("Deleting orphaned task: %s\n", t.ID)
 				if err := store.Delete(t.ID); err != nil {
-					if !opts.OutputJSON {
-						fmt.Printf("Warning: failed to delete task %s: %v\n", t.ID, err)
-					}
+					c.progressf("Warning: failed to delete task %s: %v\n", t.ID, err)
 				} else {
+					_ = task.NewEventLog(r.GitCommonDir).Delete(t.ID)
+					_ = deleteCheckpoints(ctx, g, t.ID)
 					result.DeletedTasks = append(result.DeletedTasks, t.ID)
 				}
 			} else {
-				if !opts.OutputJSON {
-					fmt.Printf("Would delete orphaned task: %s\n", t.ID)
-				}
+				c.progressf("Would delete orphaned task: %s\n", t.ID)
 				result.DeletedTasks = append(result.DeletedTasks, t.ID)
 			}
 		}
 	}
 
 	// Step 3: Clean up stale lock files
-	if !opts.OutputJSON && !opts.DryRun {
-		fmt.Println("Checking for stale locks...")
+	if !opts.DryRun {
+		c.progressf("Checking for stale locks...\n")
 	}
 
-	locksDir := filepath.Join(r.GitCommonDir, "awt", "locks")
-	if entries, err := os.ReadDir(locksDir); err == nil {
-		for _, entry := range entries {
-			if !entry.IsDir() {
-				lockPath := filepath.Join(locksDir, entry.Name())
-
-				// Try to check if lock is stale
-				// A lock file without an active process holding it is stale
-				if info, err := os.Stat(lockPath); err == nil {
-					// If file size is 0, it's likely stale
-					if info.Size() == 0 {
-						if !opts.DryRun {
-							if !opts.OutputJSON {
-								fmt.Printf("Deleting stale lock: %s\n", entry.Name())
-							}
-							if err := os.Remove(lockPath); err != nil {
-								if !opts.OutputJSON {
-									fmt.Printf("Warning: failed to remove lock %s: %v\n", entry.Name(), err)
-								}
-							} else {
-								result.DeletedLocks = append(result.DeletedLocks, entry.Name())
-							}
-						} else {
-							if !opts.OutputJSON {
-								fmt.Printf("Would delete stale lock: %s\n", entry.Name())
-							}
-							result.DeletedLocks = append(result.DeletedLocks, entry.Name())
-						}
-					}
-				}
-			}
-		}
+	lm := lock.NewLockManager(r.GitCommonDir)
+	staleLocks, err := lm.FindStale()
+	if err != nil {
+		return nil, err
 	}
-
-	// Output result
-	if opts.OutputJSON {
-		data, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		fmt.Println("\nPrune completed!")
+	for _, s := range staleLocks {
 		if opts.DryRun {
-			fmt.Println("  Mode: dry-run (no changes made)")
+			c.progressf("Would clean up stale lock: %s (%s)\n", s.File, s.Describe())
+			result.DeletedLocks = append(result.DeletedLocks, s.File)
+			result.StaleLocks = append(result.StaleLocks, s)
+			continue
 		}
-		fmt.Printf("  Orphaned tasks deleted: %d\n", len(result.DeletedTasks))
-		fmt.Printf("  Stale locks deleted: %d\n", len(result.DeletedLocks))
+
+		removed, err := lm.RemoveStale(s)
+		if err != nil {
+			c.progressf("Warning: failed to remove lock %s: %v\n", s.File, err)
+			continue
+		}
+		if !removed {
+			// Acquired by another process since it was checked
+			continue
+		}
+		c.progressf("Cleaning up stale lock: %s (%s)\n", s.File, s.Describe())
+		result.DeletedLocks = append(result.DeletedLocks, s.File)
+		result.StaleLocks = append(result.StaleLocks, s)
 	}
 
-	return nil
+	return &result, nil
 }
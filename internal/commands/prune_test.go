@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/lock"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestRunPruneKeepsHeldLocks(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	lm := lock.NewLockManager(filepath.Join(repoPath, ".git"))
+	ctx := context.Background()
+
+	// Held flock locks must survive prune
+	held, err := lm.AcquireTask(ctx, "busy-task")
+	if err != nil {
+		t.Fatalf("failed to acquire task lock: %v", err)
+	}
+	defer func() {
+		_ = held.Release()
+	}()
+
+	idle, err := lm.AcquireTask(ctx, "idle-task")
+	if err != nil {
+		t.Fatalf("failed to acquire task lock: %v", err)
+	}
+	if err := idle.Release(); err != nil {
+		t.Fatalf("failed to release task lock: %v", err)
+	}
+
+	// A holder that exited without releasing leaves its owner in the lock file
+	locksDir := filepath.Join(repoPath, ".git", "awt", "locks")
+	if err := os.WriteFile(filepath.Join(locksDir, "crashed-task.lock"), []byte(`{"pid": 1073741824}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPrune(&PruneOptions{RepoPath: repoPath, OutputJSON: true}); err != nil {
+		t.Fatalf("runPrune() failed: %v", err)
+	}
+
+	if lm.Owner("busy-task") == nil {
+		t.Error("owner of held lock was cleared")
+	}
+	// Lock files are kept, so a process that opened one keeps excluding new holders
+	for _, file := range []string{"busy-task.lock", "idle-task.lock", "crashed-task.lock"} {
+		if _, err := os.Stat(filepath.Join(locksDir, file)); err != nil {
+			t.Errorf("lock file %s was deleted: %v", file, err)
+		}
+	}
+	if owner := lm.Owner("crashed-task"); owner != nil {
+		t.Errorf("owner of stale lock = %v, want it cleared", owner)
+	}
+}
+
+func TestRunPruneKeepsHandedOffTasks(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	base := gitOutput(t, repoPath, "branch", "--show-current")
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Prune test",
+		Base:         base,
+		ID:           "handed-off",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	started, err := store.Load("handed-off")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	_ = os.WriteFile(filepath.Join(started.WorktreePath, "work.txt"), []byte("work\n"), 0644)
+	gitOutput(t, started.WorktreePath, "add", ".")
+	gitOutput(t, started.WorktreePath, "commit", "-m", "work")
+
+	handoffOpts := &HandoffOptions{RepoPath: repoPath, TaskID: "handed-off", NoPush: true, NoPR: true, OutputJSON: true}
+	if err := runTaskHandoff(handoffOpts); err != nil {
+		t.Fatalf("runTaskHandoff() failed: %v", err)
+	}
+	handedOff, err := store.Load("handed-off")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if handedOff.WorktreePath != "" {
+		t.Errorf("worktree path = %q, want it cleared after handoff", handedOff.WorktreePath)
+	}
+
+	// Prune keeps the handed-off task and then the task it marks merged
+	if err := runPrune(&PruneOptions{RepoPath: repoPath, NoFetch: true, OutputJSON: true}); err != nil {
+		t.Fatalf("runPrune() failed: %v", err)
+	}
+	if _, err := store.Load("handed-off"); err != nil {
+		t.Fatalf("handed-off task was deleted: %v", err)
+	}
+
+	gitOutput(t, repoPath, "merge", "--no-ff", "-m", "merge", started.Branch)
+	if err := runPrune(&PruneOptions{RepoPath: repoPath, Reconcile: true, NoFetch: true, OutputJSON: true}); err != nil {
+		t.Fatalf("runPrune() failed: %v", err)
+	}
+	merged, err := store.Load("handed-off")
+	if err != nil {
+		t.Fatalf("merged task was deleted: %v", err)
+	}
+	if merged.State != task.StateMerged {
+		t.Errorf("state = %s, want %s", merged.State, task.StateMerged)
+	}
+	if events, err := task.NewEventLog(filepath.Join(repoPath, ".git")).Read("handed-off"); err != nil || len(events) == 0 {
+		t.Errorf("event log was deleted: %v", err)
+	}
+}
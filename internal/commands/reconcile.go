@@ -0,0 +1,348 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// Merge detection methods reported by reconcile
+const (
+	// MergeMethodAncestry means the branch tip is reachable from the base branch
+	MergeMethodAncestry = "ancestry"
+	// MergeMethodRebase means every branch commit has a patch-equivalent commit on the base branch
+	MergeMethodRebase = "rebase"
+	// MergeMethodSquash means the combined branch diff matches a single commit on the base branch
+	MergeMethodSquash = "squash"
+	// MergeMethodRemoteDeleted means the pushed branch no longer exists on the remote
+	MergeMethodRemoteDeleted = "remote-deleted"
+)
+
+// ReconcileOptions contains options for the reconcile command
+type ReconcileOptions struct {
+	RepoPath   string
+	TaskIDs    []string
+	NoFetch    bool
+	DryRun     bool
+	OutputJSON bool
+}
+
+// ReconcileItem describes a task that was detected as merged
+type ReconcileItem struct {
+	TaskID        string `json:"task_id"`
+	Branch        string `json:"branch"`
+	PreviousState string `json:"previous_state"`
+	Method        string `json:"method"`
+	MergeCommit   string `json:"merge_commit,omitempty"`
+}
+
+// ReconcileResult represents the output of the reconcile command
+type ReconcileResult struct {
+	Checked int             `json:"checked"`
+	Merged  []ReconcileItem `json:"merged"`
+	DryRun  bool            `json:"dry_run,omitempty"`
+}
+
+// NewTaskReconcileCmd creates the task reconcile command
+func NewTaskReconcileCmd() *cobra.Command {
+	opts := &ReconcileOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "reconcile [task-id...]",
+		Short: "Detect merged tasks and mark them MERGED",
+		Long: `Check task branches against their base branch and mark merged tasks as MERGED.
+
+Only HANDOFF_READY tasks, and ACTIVE tasks with a recorded commit, are checked.
+A task is considered merged when any of the following holds:
+  1. The branch tip is an ancestor of the base branch (merge or fast-forward)
+  2. Every branch commit has a patch-equivalent commit on the base (rebase merge)
+  3. The combined branch diff matches a commit on the base (squash merge)
+  4. The branch was pushed and has since been deleted from the remote
+
+The commit on the base branch that integrated the task is recorded when known.
+
+Example:
+  awt task reconcile
+  awt task reconcile 20250110-120000-abc123
+  awt task reconcile --dry-run --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.TaskIDs = args
+			return runTaskReconcile(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().BoolVar(&opts.NoFetch, "no-fetch", false, "skip git fetch")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "report merged tasks without updating metadata")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runTaskReconcile(opts *ReconcileOptions) error {
+	result, err := cliClient(opts.OutputJSON).Reconcile(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printReconcileSummary(os.Stdout, result)
+	}
+
+	return nil
+}
+
+// Reconcile detects merged tasks and marks them MERGED
+func (c *Client) Reconcile(ctx context.Context, opts *ReconcileOptions) (*ReconcileResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	return reconcileTasks(ctx, r, cfg, store, opts.TaskIDs, !opts.NoFetch, opts.DryRun)
+}
+
+// reconcileTasks checks the given tasks (all tasks if none are given) and marks merged ones as MERGED.
+// When dryRun is set, merged tasks are reported but metadata is not updated.
+func reconcileTasks(ctx context.Context, r *repo.Repo, cfg *config.Config, store task.Store, taskIDs []string, fetch bool, dryRun bool) (*ReconcileResult, error) {
+	var tasks []*task.Task
+	if len(taskIDs) > 0 {
+		for _, id := range taskIDs {
+			t, err := store.Load(id)
+			if err != nil {
+				return nil, errors.InvalidTaskID(id)
+			}
+			tasks = append(tasks, t)
+		}
+	} else {
+		all, err := store.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tasks: %w", err)
+		}
+		tasks = all
+	}
+
+	g := git.New(r.WorkTreeRoot, cfg.VerboseGit).WithTimeouts(gitTimeouts(cfg))
+
+	// Fetch so base branches and remote-tracking refs are current (failures are ignored - might be offline)
+	if fetch {
+		_, _ = g.Fetch(ctx, cfg.RemoteName, "")
+	}
+
+	result := &ReconcileResult{
+		Merged: []ReconcileItem{},
+		DryRun: dryRun,
+	}
+
+	for _, t := range tasks {
+		if !isReconcileCandidate(t) {
+			continue
+		}
+		result.Checked++
+
+		method, mergeCommit, err := detectMerge(ctx, g, cfg.RemoteName, t)
+		if err != nil || method == "" {
+			// Undecidable tasks (missing branch, unknown base, ...) are left untouched
+			continue
+		}
+
+		item := ReconcileItem{
+			TaskID:        t.ID,
+			Branch:        t.Branch,
+			PreviousState: string(t.State),
+			Method:        method,
+			MergeCommit:   mergeCommit,
+		}
+
+		if !dryRun {
+			now := time.Now()
+			_, err := updateTask(ctx, r, cfg, store, t.ID, func(t *task.Task) error {
+				if err := t.TransitionTo(task.StateMerged, currentActor(), "task reconcile"); err != nil {
+					return err
+				}
+				t.MergeCommit = mergeCommit
+				t.MergedAt = &now
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to update task metadata for %s: %w", t.ID, err)
+			}
+			recordEvent(r, t.ID, task.Event{Type: task.EventMerged, Strategy: method, SHA: mergeCommit})
+		}
+
+		result.Merged = append(result.Merged, item)
+	}
+
+	return result, nil
+}
+
+// isReconcileCandidate reports whether a task should be checked for a merge.
+// Tasks without a recorded commit are skipped: a branch with no commits of its own
+// is trivially an ancestor of its base and would otherwise be reported as merged.
+func isReconcileCandidate(t *task.Task) bool {
+	switch t.State {
+	case task.StateActive, task.StateHandoffReady:
+		return t.LastCommit != ""
+	default:
+		return false
+	}
+}
+
+// ancestryMergeCommit returns the merge commit on base that brought in tip. When the
+// earliest descendant of tip on base is not a merge with tip as a parent, the branch
+// was fast-forwarded (or built upon) and the tip itself is the integrated commit.
+func ancestryMergeCommit(ctx context.Context, g *git.Git, tip, base string) string {
+	// Each line is "<commit> <parent>..."; the first one is a direct child of the tip
+	lines, err := g.RevList(ctx, "--ancestry-path", "--reverse", "--parents", tip+".."+base)
+	if err != nil || len(lines) == 0 {
+		return tip
+	}
+	fields := strings.Fields(lines[0])
+	if len(fields) < 3 {
+		return tip
+	}
+	for _, parent := range fields[1:] {
+		if parent == tip {
+			return fields[0]
+		}
+	}
+	return tip
+}
+
+// detectMerge determines whether a task branch has been integrated into its base.
+// Returns the detection method and the merge commit on the base (if known),
+// or an empty method if the task does not appear to be merged.
+func detectMerge(ctx context.Context, g *git.Git, remote string, t *task.Task) (string, string, error) {
+	branch := strings.TrimPrefix(t.Branch, "refs/heads/")
+	trackingRef := fmt.Sprintf("refs/remotes/%s/%s", remote, branch)
+
+	// Resolve the branch tip, falling back to the remote-tracking branch
+	tip, err := g.RevParse(ctx, "refs/heads/"+branch)
+	if err != nil {
+		tip, _ = g.RevParse(ctx, trackingRef)
+	}
+
+	if tip != "" {
+		// Merge commit or fast-forward: the tip is reachable from the base
+		isAncestor, err := g.IsAncestor(ctx, tip, t.Base)
+		if err != nil {
+			return "", "", err
+		}
+		if isAncestor {
+			return MergeMethodAncestry, ancestryMergeCommit(ctx, g, tip, t.Base), nil
+		}
+
+		mergeBase, err := g.MergeBase(ctx, tip, t.Base)
+		if err != nil {
+			return "", "", err
+		}
+
+		baseIDs, err := g.PatchIDs(ctx, mergeBase+".."+t.Base)
+		if err != nil {
+			return "", "", err
+		}
+		basePatches := make(map[string]string, len(baseIDs)) // patch ID -> base commit
+		for _, id := range baseIDs {
+			basePatches[id.ID] = id.Commit
+		}
+
+		// Rebase merge: every branch commit has an equivalent on the base
+		branchIDs, err := g.PatchIDs(ctx, mergeBase+".."+tip)
+		if err != nil {
+			return "", "", err
+		}
+		if len(branchIDs) > 0 {
+			allFound := true
+			for _, id := range branchIDs {
+				if _, ok := basePatches[id.ID]; !ok {
+					allFound = false
+					break
+				}
+			}
+			if allFound {
+				// branchIDs is newest first, so the first entry corresponds to the tip
+				return MergeMethodRebase, basePatches[branchIDs[0].ID], nil
+			}
+		}
+
+		// Squash merge: the combined branch diff was applied as one commit
+		combined, err := g.DiffPatchID(ctx, mergeBase, tip)
+		if err != nil {
+			return "", "", err
+		}
+		if combined != "" {
+			if commit, ok := basePatches[combined]; ok {
+				return MergeMethodSquash, commit, nil
+			}
+		}
+	}
+
+	// Remote branch deleted: only meaningful if the branch was pushed at some point
+	pushed := t.PRURL != ""
+	if !pushed {
+		pushed, _ = g.RefExists(ctx, trackingRef)
+	}
+	if pushed {
+		exists, err := g.RemoteBranchExists(ctx, remote, branch)
+		if err != nil {
+			// Remote unreachable - cannot decide
+			return "", "", nil
+		}
+		if !exists {
+			return MergeMethodRemoteDeleted, "", nil
+		}
+	}
+
+	return "", "", nil
+}
+
+// printReconcileSummary writes a human-readable reconcile summary to w
+func printReconcileSummary(w io.Writer, result *ReconcileResult) {
+	for _, item := range result.Merged {
+		verb := "Marked"
+		if result.DryRun {
+			verb = "Would mark"
+		}
+		commit := item.MergeCommit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if commit != "" {
+			_, _ = fmt.Fprintf(w, "%s %s as MERGED (%s, %s)\n", verb, item.TaskID, item.Method, commit)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s %s as MERGED (%s)\n", verb, item.TaskID, item.Method)
+		}
+	}
+	_, _ = fmt.Fprintf(w, "Reconciled %d task(s): %d merged\n", result.Checked, len(result.Merged))
+}
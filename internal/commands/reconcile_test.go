@@ -0,0 +1,220 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+// gitOutput runs a git command in dir and returns its trimmed stdout
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// startReconcileTask starts a task on the repo's current branch and commits one file in its worktree
+func startReconcileTask(t *testing.T, repoPath, id string) *task.Task {
+	t.Helper()
+
+	base := gitOutput(t, repoPath, "branch", "--show-current")
+	opts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Reconcile test",
+		Base:         base,
+		ID:           id,
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(opts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tk, err := store.Load(id)
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+
+	_ = os.WriteFile(filepath.Join(tk.WorktreePath, id+".txt"), []byte(id+"\n"), 0644)
+	gitOutput(t, tk.WorktreePath, "add", ".")
+	gitOutput(t, tk.WorktreePath, "commit", "-m", "work on "+id)
+
+	tk.LastCommit = gitOutput(t, tk.WorktreePath, "rev-parse", "HEAD")
+	if err := tk.TransitionTo(task.StateHandoffReady, "tester", "test"); err != nil {
+		t.Fatalf("failed to transition task: %v", err)
+	}
+	if err := store.Save(tk); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+	return tk
+}
+
+func TestReconcileTasks(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	merged := startReconcileTask(t, repoPath, "merged-task")
+	squashed := startReconcileTask(t, repoPath, "squashed-task")
+	picked := startReconcileTask(t, repoPath, "picked-task")
+	open := startReconcileTask(t, repoPath, "open-task")
+
+	// A second commit makes the squashed diff differ from every individual commit
+	_ = os.WriteFile(filepath.Join(squashed.WorktreePath, "second.txt"), []byte("second\n"), 0644)
+	gitOutput(t, squashed.WorktreePath, "add", ".")
+	gitOutput(t, squashed.WorktreePath, "commit", "-m", "second commit")
+
+	// Integrate three of the four branches into the base with different strategies
+	gitOutput(t, repoPath, "merge", "--no-ff", "-m", "merge", merged.Branch)
+	gitOutput(t, repoPath, "merge", "--squash", squashed.Branch)
+	gitOutput(t, repoPath, "commit", "-m", "squash")
+	gitOutput(t, repoPath, "cherry-pick", picked.Branch)
+
+	opts := &ReconcileOptions{RepoPath: repoPath, NoFetch: true, OutputJSON: true}
+	if err := runTaskReconcile(opts); err != nil {
+		t.Fatalf("runTaskReconcile() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tests := []struct {
+		id        string
+		wantState task.State
+	}{
+		{merged.ID, task.StateMerged},   // merge commit
+		{squashed.ID, task.StateMerged}, // squash merge
+		{picked.ID, task.StateMerged},   // rebase/cherry-pick
+		{open.ID, task.StateHandoffReady},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, err := store.Load(tt.id)
+			if err != nil {
+				t.Fatalf("failed to load task: %v", err)
+			}
+			if got.State != tt.wantState {
+				t.Errorf("state = %s, want %s", got.State, tt.wantState)
+			}
+			if tt.wantState == task.StateMerged && got.MergeCommit == "" {
+				t.Error("expected merge commit to be recorded")
+			}
+		})
+	}
+}
+
+func TestReconcileSkipsFreshActiveTask(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	tk := startReconcileTask(t, repoPath, "fresh-task")
+
+	// An ACTIVE branch with no recorded commit is trivially an ancestor of its base
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	gitOutput(t, tk.WorktreePath, "reset", "--hard", "HEAD~1")
+	if err := tk.TransitionTo(task.StateActive, "tester", "test"); err != nil {
+		t.Fatalf("failed to transition task: %v", err)
+	}
+	tk.LastCommit = ""
+	if err := store.Save(tk); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+
+	opts := &ReconcileOptions{RepoPath: repoPath, NoFetch: true, OutputJSON: true}
+	if err := runTaskReconcile(opts); err != nil {
+		t.Fatalf("runTaskReconcile() failed: %v", err)
+	}
+
+	got, err := store.Load(tk.ID)
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if got.State != task.StateActive {
+		t.Errorf("state = %s, want %s", got.State, task.StateActive)
+	}
+}
+
+func TestReconcileSkipsHandedOffTaskWithoutCommits(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	tk := startReconcileTask(t, repoPath, "empty-task")
+
+	// A handed-off branch with no commits of its own is trivially an ancestor of its base
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	gitOutput(t, tk.WorktreePath, "reset", "--hard", "HEAD~1")
+	tk.LastCommit = ""
+	if err := store.Save(tk); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+
+	opts := &ReconcileOptions{RepoPath: repoPath, NoFetch: true, OutputJSON: true}
+	if err := runTaskReconcile(opts); err != nil {
+		t.Fatalf("runTaskReconcile() failed: %v", err)
+	}
+
+	got, err := store.Load(tk.ID)
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if got.State != task.StateHandoffReady {
+		t.Errorf("state = %s, want %s", got.State, task.StateHandoffReady)
+	}
+}
+
+func TestReconcileRecordsMergeCommit(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	merged := startReconcileTask(t, repoPath, "merged-task")
+	gitOutput(t, repoPath, "merge", "--no-ff", "-m", "merge", merged.Branch)
+	mergeCommit := gitOutput(t, repoPath, "rev-parse", "HEAD")
+
+	// A fast-forward followed by unrelated work on the base integrates the tip itself
+	forwarded := startReconcileTask(t, repoPath, "forwarded-task")
+	gitOutput(t, repoPath, "merge", "--ff-only", forwarded.Branch)
+	_ = os.WriteFile(filepath.Join(repoPath, "later.txt"), []byte("later\n"), 0644)
+	gitOutput(t, repoPath, "add", ".")
+	gitOutput(t, repoPath, "commit", "-m", "later work")
+
+	opts := &ReconcileOptions{RepoPath: repoPath, NoFetch: true, OutputJSON: true}
+	if err := runTaskReconcile(opts); err != nil {
+		t.Fatalf("runTaskReconcile() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{merged.ID, mergeCommit},
+		{forwarded.ID, forwarded.LastCommit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, err := store.Load(tt.id)
+			if err != nil {
+				t.Fatalf("failed to load task: %v", err)
+			}
+			if got.State != task.StateMerged {
+				t.Errorf("state = %s, want %s", got.State, task.StateMerged)
+			}
+			if got.MergeCommit != tt.want {
+				t.Errorf("merge commit = %s, want %s", got.MergeCommit, tt.want)
+			}
+		})
+	}
+}
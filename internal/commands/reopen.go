@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// ReopenOptions contains options for the reopen command
+type ReopenOptions struct {
+	RepoPath   string
+	TaskID     string
+	OutputJSON bool
+}
+
+// ReopenResult represents the output of the reopen command
+type ReopenResult struct {
+	TaskID       string `json:"task_id"`
+	Branch       string `json:"branch"`
+	WorktreePath string `json:"worktree_path"`
+	State        string `json:"state"`
+}
+
+// NewTaskReopenCmd creates the task reopen command
+func NewTaskReopenCmd() *cobra.Command {
+	opts := &ReopenOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "reopen <task-id>",
+		Short: "Reopen an abandoned or handed-off task",
+		Long: `Reopen an abandoned (or handed-off) task by recreating its worktree.
+
+The worktree is recreated from the task's branch, or switched back to the
+branch if handoff kept it detached. If the local branch was deleted, it is
+restored from the remote-tracking branch when available.
+The task returns to ACTIVE.
+
+Example:
+  awt task reopen 20250110-120000-abc123`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.TaskID = args[0]
+			return runTaskReopen(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runTaskReopen(opts *ReopenOptions) error {
+	result, err := cliClient(opts.OutputJSON).Reopen(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Task reopened successfully!\n")
+		fmt.Printf("  Task: %s\n", result.TaskID)
+		fmt.Printf("  Branch: %s\n", result.Branch)
+		fmt.Printf("  Worktree: %s\n", result.WorktreePath)
+		fmt.Printf("  State: %s\n", result.State)
+	}
+
+	return nil
+}
+
+// Reopen recreates the worktree of an abandoned or handed-off task and makes it ACTIVE again
+func (c *Client) Reopen(ctx context.Context, opts *ReopenOptions) (*ReopenResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	// Load task
+	t, err := store.Load(opts.TaskID)
+	if err != nil {
+		return nil, errors.InvalidTaskID(opts.TaskID)
+	}
+
+	// Hold the task lock so concurrent commands cannot mutate the task
+	taskLock, err := acquireTaskLock(ctx, r, cfg, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
+
+	// Reload the task now that it is locked, in case it changed while waiting
+	if fresh, err := store.Load(t.ID); err == nil {
+		*t = *fresh
+	}
+
+	// Check the transition up front so no worktree is created for a task that cannot be reopened
+	if !task.CanTransition(t.State, task.StateActive) {
+		return nil, errors.InvalidTransition(t.ID, string(t.State), string(task.StateActive))
+	}
+
+	// Record the pre-operation state so the command can be undone
+	if err := recordOperation(ctx, r, "task reopen", t, false); err != nil {
+		return nil, err
+	}
+
+	// A worktree kept by handoff is still detached and must be switched back to the branch
+	if err := c.reactivateWorktree(ctx, r, cfg, t); err != nil {
+		return nil, err
+	}
+
+	// Update task state
+	worktreePath := t.WorktreePath
+	t, err = task.Update(store, taskLock, t.ID, func(t *task.Task) error {
+		t.WorktreePath = worktreePath
+		return t.TransitionTo(task.StateActive, currentActor(), "task reopen")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task metadata: %w", err)
+	}
+	recordEvent(r, t.ID, task.Event{Type: task.EventReopen, Message: "worktree at " + t.WorktreePath})
+
+	result := ReopenResult{
+		TaskID:       t.ID,
+		Branch:       t.Branch,
+		WorktreePath: t.WorktreePath,
+		State:        string(t.State),
+	}
+
+	return &result, nil
+}
+
+// recreateTaskWorktree creates a worktree for the task's existing branch at the configured
+// worktree path and records it on the task (metadata is not saved).
+// If the local branch is gone it is restored from the remote-tracking branch.
+// The caller must hold the global lock.
+func recreateTaskWorktree(ctx context.Context, r *repo.Repo, cfg *config.Config, t *task.Task) error {
+	g := git.New(r.WorkTreeRoot, cfg.VerboseGit)
+	branchName := strings.TrimPrefix(t.Branch, "refs/heads/")
+
+	// Nothing to do if the worktree is still there
+	if t.WorktreePath != "" {
+		if _, err := os.Stat(t.WorktreePath); err == nil {
+			return nil
+		}
+	}
+
+	// Check if branch is checked out elsewhere
+	checkedOut, path, err := g.IsBranchCheckedOut(ctx, branchName)
+	if err != nil {
+		return fmt.Errorf("failed to check branch checkout status: %w", err)
+	}
+	if checkedOut {
+		return errors.BranchCheckedOutElsewhere(branchName, path)
+	}
+
+	worktreePath := cfg.GetWorktreePath(r.WorkTreeRoot, t.ID)
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		return fmt.Errorf("failed to create worktree parent directory: %w", err)
+	}
+
+	exists, err := g.BranchExists(ctx, branchName)
+	if err != nil {
+		return fmt.Errorf("failed to check branch existence: %w", err)
+	}
+
+	var result *git.Result
+	if exists {
+		result, err = g.WorktreeAddExisting(ctx, worktreePath, branchName)
+	} else {
+		// Restore the branch from the remote-tracking ref
+		trackingRef := fmt.Sprintf("%s/%s", cfg.RemoteName, branchName)
+		hasTracking, _ := g.RefExists(ctx, "refs/remotes/"+trackingRef)
+		if !hasTracking {
+			return fmt.Errorf("branch %s no longer exists locally or on %s; the task cannot be reopened", branchName, cfg.RemoteName)
+		}
+		result, err = g.WorktreeAdd(ctx, worktreePath, branchName, trackingRef)
+	}
+	if err != nil || result.ExitCode != 0 {
+		return errors.WorktreeAddFailed(worktreePath, result.Stderr)
+	}
+
+	// Restore upstream tracking (non-fatal)
+	wtGit := git.New(worktreePath, cfg.VerboseGit)
+	_, _ = wtGit.SetUpstream(ctx, cfg.RemoteName, branchName)
+
+	t.WorktreePath = worktreePath
+	return nil
+}
@@ -6,13 +6,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/kernel-labs-ai/awt/internal/git"
 	"github.com/kernel-labs-ai/awt/internal/idgen"
-	"github.com/kernel-labs-ai/awt/internal/lock"
 	"github.com/kernel-labs-ai/awt/internal/logger"
 	"github.com/kernel-labs-ai/awt/internal/repo"
 	"github.com/kernel-labs-ai/awt/internal/safety"
@@ -27,6 +27,7 @@ type StartOptions struct {
 	Title        string
 	Base         string
 	ID           string
+	Agents       []string
 	NoFetch      bool
 	BranchPrefix string
 	WorktreeDir  string
@@ -36,8 +37,16 @@ type StartOptions struct {
 // StartResult represents the output of the start command
 type StartResult struct {
 	ID           string `json:"id"`
+	Agent        string `json:"agent,omitempty"`
 	Branch       string `json:"branch"`
 	WorktreePath string `json:"worktree_path"`
+	Group        string `json:"group,omitempty"`
+}
+
+// GroupStartResult represents the output of the start command with --agents
+type GroupStartResult struct {
+	Group string        `json:"group"`
+	Tasks []StartResult `json:"tasks"`
 }
 
 // NewTaskCmd creates the task command group
@@ -55,11 +64,20 @@ func NewTaskCmd() *cobra.Command {
 	cmd.AddCommand(NewTaskCommitCmd())
 	cmd.AddCommand(NewTaskSyncCmd())
 	cmd.AddCommand(NewTaskHandoffCmd())
+	cmd.AddCommand(NewTaskPRCmd())
+	cmd.AddCommand(NewTaskFeedbackCmd())
 	cmd.AddCommand(NewTaskCheckoutCmd())
 	cmd.AddCommand(NewTaskAdoptCmd())
 	cmd.AddCommand(NewTaskUnlockCmd())
 	cmd.AddCommand(NewTaskCopyCmd())
 	cmd.AddCommand(NewTaskEditorCmd())
+	cmd.AddCommand(NewTaskReconcileCmd())
+	cmd.AddCommand(NewTaskAbandonCmd())
+	cmd.AddCommand(NewTaskReopenCmd())
+	cmd.AddCommand(NewTaskLogCmd())
+	cmd.AddCommand(NewTaskCheckpointCmd())
+	cmd.AddCommand(NewTaskCheckpointsCmd())
+	cmd.AddCommand(NewTaskRestoreCmd())
 
 	return cmd
 }
@@ -67,8 +85,7 @@ func NewTaskCmd() *cobra.Command {
 // NewTaskStartCmd creates the task start command
 func NewTaskStartCmd() *cobra.Command {
 	opts := &StartOptions{
-		BranchPrefix: "awt",
-		WorktreeDir:  ".awt/wt",
+		WorktreeDir: ".awt/wt",
 	}
 
 	cmd := &cobra.Command{
@@ -83,29 +100,82 @@ This command:
   4. Saves task metadata
   5. Outputs the task details
 
+With --agents, the same task is fanned out to several agents as a "race" group:
+one task, branch and worktree is created per agent, all sharing a group ID
+(--id sets the group ID; task IDs are <group>-<agent>). Use 'awt group status'
+to compare the attempts.
+
 Example:
   awt task start --agent=claude --title="Add user authentication"
-  awt task start --agent=claude --title="Fix bug" --base=develop --no-fetch`,
+  awt task start --agent=claude --title="Fix bug" --base=develop --no-fetch
+  awt task start --agents=claude,codex,factory --title="Add rate limiting"`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runTaskStart(opts)
 		},
 	}
 
 	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
-	cmd.Flags().StringVar(&opts.Agent, "agent", "", "agent name (required)")
+	cmd.Flags().StringVar(&opts.Agent, "agent", "", "agent name (required unless --agents is set)")
+	cmd.Flags().StringSliceVar(&opts.Agents, "agents", nil, "comma-separated agent names to fan the task out to")
 	cmd.Flags().StringVar(&opts.Title, "title", "", "task title (required)")
 	cmd.Flags().StringVar(&opts.Base, "base", "origin/main", "base branch")
 	cmd.Flags().StringVar(&opts.ID, "id", "", "task ID (auto-generated if not provided)")
 	cmd.Flags().BoolVar(&opts.NoFetch, "no-fetch", false, "skip git fetch")
 	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
 
-	_ = cmd.MarkFlagRequired("agent")
+	cmd.MarkFlagsOneRequired("agent", "agents")
+	cmd.MarkFlagsMutuallyExclusive("agent", "agents")
 	_ = cmd.MarkFlagRequired("title")
 
 	return cmd
 }
 
 func runTaskStart(opts *StartOptions) error {
+	result, err := cliClient(opts.OutputJSON).StartTask(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if result.Group == "" {
+		t := result.Tasks[0]
+		if opts.OutputJSON {
+			data, _ := json.MarshalIndent(t, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			fmt.Printf("Task started successfully!\n")
+			fmt.Printf("  ID: %s\n", t.ID)
+			fmt.Printf("  Branch: %s\n", t.Branch)
+			fmt.Printf("  Worktree: %s\n", t.WorktreePath)
+			fmt.Printf("  Agent: %s\n", t.Agent)
+			fmt.Printf("  Title: %s\n", opts.Title)
+		}
+		return nil
+	}
+
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Task group started successfully!\n")
+		fmt.Printf("  Group: %s\n", result.Group)
+		fmt.Printf("  Title: %s\n", opts.Title)
+		for _, t := range result.Tasks {
+			fmt.Printf("\n  Agent: %s\n", t.Agent)
+			fmt.Printf("    ID: %s\n", t.ID)
+			fmt.Printf("    Branch: %s\n", t.Branch)
+			fmt.Printf("    Worktree: %s\n", t.WorktreePath)
+		}
+		fmt.Printf("\nUse 'awt group status %s' to compare the attempts.\n", result.Group)
+	}
+
+	return nil
+}
+
+// StartTask creates the branch, worktree and metadata for a new task. With opts.Agents
+// set, one task is created per agent under a shared group ID (result.Group);
+// otherwise result.Tasks holds the single new task and result.Group is empty.
+func (c *Client) StartTask(ctx context.Context, opts *StartOptions) (*GroupStartResult, error) {
 	log := logger.WithFields(map[string]string{
 		"command": "task start",
 		"agent":   opts.Agent,
@@ -115,59 +185,161 @@ func runTaskStart(opts *StartOptions) error {
 	// Validate inputs
 	validator := safety.NewValidator()
 
-	if err := validator.ValidateAgentName(opts.Agent); err != nil {
-		return fmt.Errorf("invalid agent name: %w", err)
+	if len(opts.Agents) > 0 {
+		seen := make(map[string]bool)
+		for _, agent := range opts.Agents {
+			if err := validator.ValidateAgentName(agent); err != nil {
+				return nil, fmt.Errorf("invalid agent name %q: %w", agent, err)
+			}
+			if seen[strings.ToLower(agent)] {
+				return nil, fmt.Errorf("duplicate agent name: %s", agent)
+			}
+			seen[strings.ToLower(agent)] = true
+		}
+	} else if err := validator.ValidateAgentName(opts.Agent); err != nil {
+		return nil, fmt.Errorf("invalid agent name: %w", err)
 	}
 
 	if err := validator.ValidateTaskTitle(opts.Title); err != nil {
-		return fmt.Errorf("invalid task title: %w", err)
+		return nil, fmt.Errorf("invalid task title: %w", err)
 	}
 
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
 	}
 	log.Debug("Repository discovered at %s", r.WorkTreeRoot)
 
-	// Load config to get remote name
+	// Load config to get remote name and branch naming
 	configLoader := config.NewConfigLoader(r.GitCommonDir)
 	cfg, err := configLoader.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if opts.BranchPrefix == "" {
+		opts.BranchPrefix = cfg.BranchPrefix
 	}
 
 	// Create Git wrapper
-	g := git.New(r.WorkTreeRoot, false)
+	g := git.New(r.WorkTreeRoot, false).WithTimeouts(gitTimeouts(cfg))
 
 	// Acquire global lock for worktree creation
-	lm := lock.NewLockManager(r.GitCommonDir)
-	ctx := context.Background()
+	lm := newLockManager(r, cfg)
 	globalLock, err := lm.AcquireGlobal(ctx)
 	if err != nil {
-		return errors.LockTimeout("global")
+		return nil, errors.LockTimeout("global")
 	}
 	defer func() {
 		_ = globalLock.Release()
 	}()
 
-	// Generate or validate task ID
+	// Generate or validate task ID (the group ID with --agents)
 	taskID := opts.ID
 	if taskID == "" {
 		taskID, err = idgen.GenerateTaskID()
 		if err != nil {
-			return fmt.Errorf("failed to generate task ID: %w", err)
+			return nil, fmt.Errorf("failed to generate task ID: %w", err)
 		}
 	} else if !idgen.ValidateTaskID(taskID) {
-		return errors.InvalidTaskID(taskID)
+		return nil, errors.InvalidTaskID(taskID)
 	}
 
-	// Generate branch name
-	branchName := idgen.GenerateBranchName(opts.BranchPrefix, opts.Agent, taskID)
+	// Fetch unless --no-fetch
+	if !opts.NoFetch {
+		// Fetch failures are ignored - might be offline - but a timeout is worth a warning
+		if _, err := g.Fetch(ctx, "", ""); errors.ExitCodeOf(err) == errors.ExitGitTimeout {
+			c.progressf("Warning: %v\n", err)
+		}
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	if len(opts.Agents) > 0 {
+		return startTaskGroup(ctx, r, cfg, g, store, opts, taskID)
+	}
+
+	t, err := createTask(ctx, r, cfg, g, store, opts, opts.Agent, taskID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &GroupStartResult{Tasks: []StartResult{startResult(t, "")}}, nil
+}
+
+// startTaskGroup creates one task per agent under a shared group ID.
+// If any task fails, the tasks already created for the group are rolled back.
+// The caller must hold the global lock.
+func startTaskGroup(ctx context.Context, r *repo.Repo, cfg *config.Config, g *git.Git, store task.Store, opts *StartOptions, groupID string) (*GroupStartResult, error) {
+	var created []*task.Task
+
+	for _, agent := range opts.Agents {
+		taskID := fmt.Sprintf("%s-%s", groupID, idgen.SanitizeName(agent))
+		if !idgen.ValidateTaskID(taskID) {
+			rollbackTasks(ctx, r, g, store, created)
+			return nil, errors.InvalidTaskID(taskID)
+		}
+
+		t, err := createTask(ctx, r, cfg, g, store, opts, agent, taskID, groupID)
+		if err != nil {
+			rollbackTasks(ctx, r, g, store, created)
+			return nil, err
+		}
+		created = append(created, t)
+	}
+
+	result := &GroupStartResult{Group: groupID, Tasks: []StartResult{}}
+	for _, t := range created {
+		result.Tasks = append(result.Tasks, startResult(t, groupID))
+	}
+	return result, nil
+}
+
+// startResult describes a newly created task
+func startResult(t *task.Task, groupID string) StartResult {
+	return StartResult{
+		ID:           t.ID,
+		Agent:        t.Agent,
+		Branch:       t.Branch,
+		WorktreePath: t.WorktreePath,
+		Group:        groupID,
+	}
+}
+
+// rollbackTasks removes the worktrees, branches, metadata and event logs of tasks created by a failed group start
+func rollbackTasks(ctx context.Context, r *repo.Repo, g *git.Git, store task.Store, tasks []*task.Task) {
+	for _, t := range tasks {
+		_, _ = g.WorktreeRemove(ctx, t.WorktreePath, true)
+		_, _ = g.DeleteBranch(ctx, strings.TrimPrefix(t.Branch, "refs/heads/"), true)
+		_ = store.Delete(t.ID)
+		_ = task.NewEventLog(r.GitCommonDir).Delete(t.ID)
+	}
+}
+
+// createTask creates the branch, worktree and metadata for a single task.
+// The caller must hold the global lock.
+func createTask(ctx context.Context, r *repo.Repo, cfg *config.Config, g *git.Git, store task.Store, opts *StartOptions, agent, taskID, group string) (*task.Task, error) {
+	log := logger.WithFields(map[string]string{
+		"command": "task start",
+		"agent":   agent,
+	})
+	validator := safety.NewValidator()
+
+	// Generate branch name from the configured template
+	branchName, err := idgen.GenerateBranchName(cfg.BranchTemplate, opts.BranchPrefix, agent, opts.Title, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid branch name: %w", err)
+	}
 
 	// Validate branch name
 	if err := validator.ValidateBranchName(branchName); err != nil {
-		return fmt.Errorf("invalid branch name: %w", err)
+		return nil, fmt.Errorf("invalid branch name: %w", err)
 	}
 
 	// Generate worktree path using config (supports global worktree directory)
@@ -176,49 +348,48 @@ func runTaskStart(opts *StartOptions) error {
 	// Ensure worktree parent directory exists (for global paths)
 	worktreeParent := filepath.Dir(worktreePath)
 	if err := os.MkdirAll(worktreeParent, 0755); err != nil {
-		return fmt.Errorf("failed to create worktree parent directory: %w", err)
+		return nil, fmt.Errorf("failed to create worktree parent directory: %w", err)
 	}
 
 	// Validate worktree path
 	if err := validator.ValidateWorktreePath(worktreePath, r.WorkTreeRoot); err != nil {
-		return fmt.Errorf("invalid worktree path: %w", err)
-	}
-
-	// Fetch unless --no-fetch
-	if !opts.NoFetch {
-		_, _ = g.Fetch("", "")
-		// Fetch failures are ignored - might be offline
+		return nil, fmt.Errorf("invalid worktree path: %w", err)
 	}
 
 	// Check if branch already exists
-	exists, err := g.BranchExists(branchName)
+	exists, err := g.BranchExists(ctx, branchName)
 	if err != nil {
-		return fmt.Errorf("failed to check branch existence: %w", err)
+		return nil, fmt.Errorf("failed to check branch existence: %w", err)
 	}
 	if exists {
-		return errors.BranchExists(branchName)
+		return nil, errors.BranchExists(branchName)
 	}
 
 	// Check if branch is checked out elsewhere
-	checkedOut, path, err := g.IsBranchCheckedOut(branchName)
+	checkedOut, path, err := g.IsBranchCheckedOut(ctx, branchName)
 	if err != nil {
-		return fmt.Errorf("failed to check branch checkout status: %w", err)
+		return nil, fmt.Errorf("failed to check branch checkout status: %w", err)
 	}
 	if checkedOut {
-		return errors.BranchCheckedOutElsewhere(branchName, path)
+		return nil, errors.BranchCheckedOutElsewhere(branchName, path)
+	}
+
+	// Record the operation so the task can be undone
+	if err := recordOperation(ctx, r, "task start", &task.Task{ID: taskID, Branch: branchName}, true); err != nil {
+		return nil, err
 	}
 
 	// Create worktree
 	log.Info("Creating worktree at %s", worktreePath)
-	result, err := g.WorktreeAdd(worktreePath, branchName, opts.Base)
+	result, err := g.WorktreeAdd(ctx, worktreePath, branchName, opts.Base)
 	if err != nil || result.ExitCode != 0 {
-		return fmt.Errorf("failed to create worktree: %s", result.Stderr)
+		return nil, errors.WorktreeAddFailed(worktreePath, result.Stderr)
 	}
 
 	// Set upstream tracking branch to origin/<branchName>
 	// This ensures the branch tracks the remote branch with the same name
 	wtGit := git.New(worktreePath, false)
-	setUpstreamResult, err := wtGit.SetUpstream(cfg.RemoteName, branchName)
+	setUpstreamResult, err := wtGit.SetUpstream(ctx, cfg.RemoteName, branchName)
 	if err != nil || setUpstreamResult.ExitCode != 0 {
 		// Non-fatal: log warning but continue
 		log.Debug("Failed to set upstream tracking (non-fatal): %s", setUpstreamResult.Stderr)
@@ -229,43 +400,30 @@ func runTaskStart(opts *StartOptions) error {
 	// Create task metadata
 	t := &task.Task{
 		ID:           taskID,
-		Agent:        opts.Agent,
+		Agent:        agent,
 		Title:        opts.Title,
 		Branch:       branchName,
 		Base:         opts.Base,
 		CreatedAt:    time.Now(),
-		State:        task.StateActive,
+		State:        task.StateNew,
 		WorktreePath: worktreePath,
+		Group:        group,
+	}
+	if err := t.TransitionTo(task.StateActive, currentActor(), "task start"); err != nil {
+		_, _ = g.WorktreeRemove(ctx, worktreePath, true)
+		return nil, err
 	}
 
 	// Save task
-	store := task.NewTaskStore(r.GitCommonDir)
 	log.Debug("Saving task metadata for %s", taskID)
 	if err := store.Save(t); err != nil {
 		// Try to clean up worktree
 		log.Error("Failed to save task, cleaning up worktree")
-		_, _ = g.WorktreeRemove(worktreePath, true)
-		return fmt.Errorf("failed to save task: %w", err)
+		_, _ = g.WorktreeRemove(ctx, worktreePath, true)
+		return nil, fmt.Errorf("failed to save task: %w", err)
 	}
 	log.Info("Task %s created successfully", taskID)
+	recordEvent(r, taskID, task.Event{Type: task.EventStart, Message: fmt.Sprintf("%s from %s", branchName, opts.Base)})
 
-	// Output result
-	if opts.OutputJSON {
-		output := StartResult{
-			ID:           taskID,
-			Branch:       branchName,
-			WorktreePath: worktreePath,
-		}
-		data, _ := json.MarshalIndent(output, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		fmt.Printf("Task started successfully!\n")
-		fmt.Printf("  ID: %s\n", taskID)
-		fmt.Printf("  Branch: %s\n", branchName)
-		fmt.Printf("  Worktree: %s\n", worktreePath)
-		fmt.Printf("  Agent: %s\n", opts.Agent)
-		fmt.Printf("  Title: %s\n", opts.Title)
-	}
-
-	return nil
+	return t, nil
 }
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/kernel-labs-ai/awt/internal/task"
 )
 
@@ -281,3 +283,72 @@ func TestRunTaskStartSetsUpstreamTracking(t *testing.T) {
 		t.Errorf("branch merge ref = %q, want %q", mergeRef, expectedMergeRef)
 	}
 }
+
+func TestRunTaskStartUsesBranchTemplate(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+	t.Setenv("AWT_BRANCH_PREFIX", "feat")
+	t.Setenv("AWT_BRANCH_TEMPLATE", "{prefix}/{agent}/{slug}-{id}")
+
+	opts := &StartOptions{
+		RepoPath:   repoPath,
+		Agent:      "claude",
+		Title:      "Add rate limiting",
+		Base:       "HEAD",
+		ID:         "tmpl",
+		NoFetch:    true,
+		OutputJSON: true,
+	}
+	if err := runTaskStart(opts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(filepath.Join(repoPath, ".git"))
+	tk, err := store.Load("tmpl")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if tk.Branch != "feat/claude/add-rate-limiting-tmpl" {
+		t.Errorf("branch = %q, want %q", tk.Branch, "feat/claude/add-rate-limiting-tmpl")
+	}
+
+	// The branch resolves back to the task through the store
+	if id := taskIDForBranch(store, "refs/heads/"+tk.Branch); id != "tmpl" {
+		t.Errorf("taskIDForBranch() = %q, want %q", id, "tmpl")
+	}
+	if id := taskIDForBranch(store, "feat/claude/unknown"); id != "" {
+		t.Errorf("taskIDForBranch() = %q, want empty", id)
+	}
+}
+
+func TestRunTaskStartWorktreeAddFailed(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	worktreeDir := t.TempDir()
+	t.Setenv("AWT_WORKTREE_DIR", worktreeDir)
+
+	// A stale worktree registered at the task's path makes 'git worktree add' fail
+	worktreePath := filepath.Join(worktreeDir, config.GenerateProjectID(repoPath), "blocked")
+	if out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "-b", "stale", worktreePath).CombinedOutput(); err != nil {
+		t.Fatalf("failed to add worktree: %v\n%s", err, out)
+	}
+	if err := os.RemoveAll(worktreePath); err != nil {
+		t.Fatalf("failed to remove worktree: %v", err)
+	}
+
+	opts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Blocked task",
+		Base:         "HEAD",
+		ID:           "blocked",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	err := runTaskStart(opts)
+	if errors.ExitCodeOf(err) != errors.ExitWorktreeAddFailed {
+		t.Errorf("runTaskStart() error = %v, want exit code %d", err, errors.ExitWorktreeAddFailed)
+	}
+}
@@ -1,9 +1,11 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
 
 	"github.com/kernel-labs-ai/awt/internal/errors"
@@ -33,6 +35,10 @@ type StatusResult struct {
 	CreatedAt    string `json:"created_at"`
 	LastCommit   string `json:"last_commit,omitempty"`
 	PRURL        string `json:"pr_url,omitempty"`
+	MergeCommit  string `json:"merge_commit,omitempty"`
+	Group        string `json:"group,omitempty"`
+
+	History []task.Transition `json:"history,omitempty"`
 }
 
 // NewTaskStatusCmd creates the task status command
@@ -70,96 +76,111 @@ Example:
 }
 
 func runTaskStatus(opts *StatusOptions) error {
+	result, err := cliClient(opts.OutputJSON).Status(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Task: %s\n", result.ID)
+		fmt.Printf("  Agent: %s\n", result.Agent)
+		if result.Group != "" {
+			fmt.Printf("  Group: %s\n", result.Group)
+		}
+		fmt.Printf("  Title: %s\n", result.Title)
+		fmt.Printf("  Branch: %s\n", result.Branch)
+		fmt.Printf("  Base: %s\n", result.Base)
+		fmt.Printf("  State: %s\n", result.State)
+		fmt.Printf("  Worktree: %s\n", result.WorktreePath)
+		fmt.Printf("  Created: %s\n", result.CreatedAt)
+		if result.LastCommit != "" {
+			fmt.Printf("  Last Commit: %s\n", result.LastCommit)
+		}
+		if result.PRURL != "" {
+			fmt.Printf("  PR URL: %s\n", result.PRURL)
+		}
+		if result.MergeCommit != "" {
+			fmt.Printf("  Merge Commit: %s\n", result.MergeCommit)
+		}
+		if len(result.History) > 0 {
+			fmt.Printf("  History:\n")
+			for _, tr := range result.History {
+				fmt.Printf("    %s  %s -> %s", tr.At.Format("2006-01-02 15:04:05"), tr.From, tr.To)
+				if tr.Command != "" {
+					fmt.Printf("  (%s", tr.Command)
+					if tr.Actor != "" {
+						fmt.Printf(" by %s", tr.Actor)
+					}
+					fmt.Printf(")")
+				}
+				if tr.Reason != "" {
+					fmt.Printf(": %s", tr.Reason)
+				}
+				fmt.Println()
+			}
+		}
+	}
+
+	return nil
+}
+
+// Status returns the task's metadata and state history
+func (c *Client) Status(ctx context.Context, opts *StatusOptions) (*StatusResult, error) {
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
 	}
 
-	store := task.NewTaskStore(r.GitCommonDir)
-
-	// Determine task ID
-	taskID := opts.TaskID
-
-	if taskID == "" && opts.Branch != "" {
-		// Extract task ID from branch name
-		// Branch format: awt/<agent>/<id>
-		taskID = extractTaskIDFromBranch(opts.Branch)
-		if taskID == "" {
-			return fmt.Errorf("could not extract task ID from branch: %s", opts.Branch)
-		}
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
 	}
+	defer func() {
+		_ = store.Close()
+	}()
 
-	if taskID == "" {
-		// Try to infer from current worktree
-		taskID, err = inferTaskIDFromCurrentDirectory(r)
-		if err != nil {
-			return fmt.Errorf("could not infer task ID: %w\nProvide task ID as argument or use --branch flag", err)
-		}
+	// Determine task ID
+	taskID, err := resolveTaskID(ctx, r, store, opts.TaskID, opts.Branch)
+	if err != nil {
+		return nil, err
 	}
 
 	// Load task
 	t, err := store.Load(taskID)
 	if err != nil {
-		return errors.InvalidTaskID(taskID)
-	}
-
-	// Output result
-	if opts.OutputJSON {
-		result := StatusResult{
-			ID:           t.ID,
-			Agent:        t.Agent,
-			Title:        t.Title,
-			Branch:       t.Branch,
-			Base:         t.Base,
-			State:        string(t.State),
-			WorktreePath: t.WorktreePath,
-			CreatedAt:    t.CreatedAt.Format("2006-01-02 15:04:05"),
-			LastCommit:   t.LastCommit,
-			PRURL:        t.PRURL,
-		}
-		data, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		fmt.Printf("Task: %s\n", t.ID)
-		fmt.Printf("  Agent: %s\n", t.Agent)
-		fmt.Printf("  Title: %s\n", t.Title)
-		fmt.Printf("  Branch: %s\n", t.Branch)
-		fmt.Printf("  Base: %s\n", t.Base)
-		fmt.Printf("  State: %s\n", t.State)
-		fmt.Printf("  Worktree: %s\n", t.WorktreePath)
-		fmt.Printf("  Created: %s\n", t.CreatedAt.Format("2006-01-02 15:04:05"))
-		if t.LastCommit != "" {
-			fmt.Printf("  Last Commit: %s\n", t.LastCommit)
-		}
-		if t.PRURL != "" {
-			fmt.Printf("  PR URL: %s\n", t.PRURL)
-		}
+		return nil, errors.InvalidTaskID(taskID)
 	}
 
-	return nil
+	return &StatusResult{
+		ID:           t.ID,
+		Agent:        t.Agent,
+		Title:        t.Title,
+		Branch:       t.Branch,
+		Base:         t.Base,
+		State:        string(t.State),
+		WorktreePath: t.WorktreePath,
+		CreatedAt:    t.CreatedAt.Format("2006-01-02 15:04:05"),
+		LastCommit:   t.LastCommit,
+		PRURL:        t.PRURL,
+		MergeCommit:  t.MergeCommit,
+		Group:        t.Group,
+		History:      t.History,
+	}, nil
 }
 
-// extractTaskIDFromBranch extracts the task ID from a branch name
-// Branch format: awt/<agent>/<id>
-func extractTaskIDFromBranch(branch string) string {
-	// Remove refs/heads/ prefix if present
-	if len(branch) > 11 && branch[:11] == "refs/heads/" {
-		branch = branch[11:]
-	}
-
-	// Split by /
-	parts := filepath.SplitList(branch)
-	if len(parts) < 3 {
-		// Try with plain string split
-		parts := splitPath(branch)
-		if len(parts) >= 3 {
-			return parts[2]
-		}
+// taskIDForBranch returns the ID of the task that owns a branch, or "" if there is none.
+// Branches are resolved through the task store so any branch_template round-trips.
+func taskIDForBranch(store task.Store, branch string) string {
+	t, err := store.FindByBranch(branch)
+	if err != nil {
 		return ""
 	}
-
-	return parts[2]
+	return t.ID
 }
 
 // splitPath splits a path by /
@@ -183,7 +204,7 @@ func splitPath(path string) []string {
 }
 
 // inferTaskIDFromCurrentDirectory tries to infer the task ID from the current directory
-func inferTaskIDFromCurrentDirectory(r *repo.Repo) (string, error) {
+func inferTaskIDFromCurrentDirectory(ctx context.Context, r *repo.Repo) (string, error) {
 	// Get current directory
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -192,10 +213,18 @@ func inferTaskIDFromCurrentDirectory(r *repo.Repo) (string, error) {
 
 	// Check if we're in a worktree
 	g := git.New(r.WorkTreeRoot, false)
-	worktrees, err := g.WorktreeList()
+	worktrees, err := g.WorktreeList(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	store, err := openTaskStore(r)
 	if err != nil {
 		return "", err
 	}
+	defer func() {
+		_ = store.Close()
+	}()
 
 	// Find matching worktree
 	for _, wt := range worktrees {
@@ -217,8 +246,8 @@ func inferTaskIDFromCurrentDirectory(r *repo.Repo) (string, error) {
 		// Use filepath.Rel to check if cwd is under wtPath
 		rel, err := filepath.Rel(wtPathAbs, cwdAbs)
 		if err == nil && !filepath.IsAbs(rel) && rel != ".." && !hasParentDir(rel) {
-			// Extract task ID from branch
-			taskID := extractTaskIDFromBranch(wt.Branch)
+			// Look up the task that owns the worktree's branch
+			taskID := taskIDForBranch(store, wt.Branch)
 			if taskID != "" {
 				return taskID, nil
 			}
@@ -228,6 +257,18 @@ func inferTaskIDFromCurrentDirectory(r *repo.Repo) (string, error) {
 	return "", fmt.Errorf("not in a task worktree")
 }
 
+// currentActor returns who is running the current command, for task history.
+// $AWT_ACTOR takes precedence so agents can identify themselves; otherwise the OS user is used.
+func currentActor() string {
+	if actor := os.Getenv("AWT_ACTOR"); actor != "" {
+		return actor
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
 // hasParentDir checks if a relative path contains ..
 func hasParentDir(path string) bool {
 	parts := splitPath(path)
@@ -0,0 +1,375 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// StoreMigrateOptions contains options for the store migrate command
+type StoreMigrateOptions struct {
+	RepoPath   string
+	From       string
+	To         string
+	NoSwitch   bool
+	OutputJSON bool
+}
+
+// StoreMigrateResult represents the output of the store migrate command
+type StoreMigrateResult struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Migrated []string `json:"migrated"`
+	Switched bool     `json:"switched"`
+}
+
+// StoreSyncOptions contains options for the store push and fetch commands
+type StoreSyncOptions struct {
+	RepoPath   string
+	Remote     string
+	OutputJSON bool
+}
+
+// StoreSyncResult represents the output of the store push and fetch commands
+type StoreSyncResult struct {
+	Remote string   `json:"remote"`
+	Tasks  []string `json:"tasks"`
+}
+
+// NewStoreCmd creates the store command
+func NewStoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "store",
+		Short: "Manage the task metadata store",
+		Long: `Commands for managing where task metadata is stored.
+
+Backends:
+  json    One JSON file per task under .git/awt/tasks (default)
+  sqlite  Embedded SQLite database at .git/awt/tasks.db
+  git     JSON blobs under refs/awt/tasks/<id>, shared through a remote
+
+The backend is selected with the task_store setting.`,
+	}
+
+	cmd.AddCommand(NewStoreMigrateCmd())
+	cmd.AddCommand(NewStorePushCmd())
+	cmd.AddCommand(NewStoreFetchCmd())
+
+	return cmd
+}
+
+// NewStoreMigrateCmd creates the store migrate command
+func NewStoreMigrateCmd() *cobra.Command {
+	opts := &StoreMigrateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy all tasks to another store backend",
+		Long: `Copy all tasks from one store backend to another and switch the
+repository to the new backend (task_store in the repo config).
+
+The source store is left in place, so migrating back restores it.
+Tasks that already exist in the destination are overwritten.
+
+Example:
+  awt store migrate --to=sqlite
+  awt store migrate --to=git
+  awt store migrate --from=sqlite --to=json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStoreMigrate(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.From, "from", "", "source backend (default: the configured task_store)")
+	cmd.Flags().StringVar(&opts.To, "to", "", "destination backend (json, sqlite or git)")
+	cmd.Flags().BoolVar(&opts.NoSwitch, "no-switch", false, "copy tasks without changing the task_store setting")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// NewStorePushCmd creates the store push command
+func NewStorePushCmd() *cobra.Command {
+	opts := &StoreSyncOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Push task refs to a remote (git store)",
+		Long: `Push task metadata under refs/awt/tasks/ to a remote.
+
+The remote's task refs are fetched and merged first, so concurrent updates
+from other clones are kept. Requires task_store=git.
+
+Example:
+  awt store push
+  awt store push --remote=upstream`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStorePush(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.Remote, "remote", "", "remote name (default: remote_name setting)")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+// NewStoreFetchCmd creates the store fetch command
+func NewStoreFetchCmd() *cobra.Command {
+	opts := &StoreSyncOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "fetch",
+		Short: "Fetch task refs from a remote (git store)",
+		Long: `Fetch task metadata under refs/awt/tasks/ from a remote and merge it into
+the local tasks.
+
+Tasks changed on both sides are merged field by field: each field keeps the
+value written last. A task deleted on either side stays deleted unless the
+other side changed it after the deletion.
+Requires task_store=git.
+
+Example:
+  awt store fetch
+  awt store fetch --remote=upstream --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStoreFetch(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().StringVar(&opts.Remote, "remote", "", "remote name (default: remote_name setting)")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runStorePush(opts *StoreSyncOptions) error {
+	store, remote, err := openGitRefStore(opts)
+	if err != nil {
+		return err
+	}
+
+	pushed, err := store.Push(context.Background(), remote)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	result := StoreSyncResult{Remote: remote, Tasks: pushed}
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Pushed %d task(s) to %s\n", len(pushed), remote)
+		for _, id := range pushed {
+			fmt.Printf("  %s\n", id)
+		}
+	}
+
+	return nil
+}
+
+func runStoreFetch(opts *StoreSyncOptions) error {
+	store, remote, err := openGitRefStore(opts)
+	if err != nil {
+		return err
+	}
+
+	updated, err := store.Fetch(context.Background(), remote)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	result := StoreSyncResult{Remote: remote, Tasks: updated}
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Fetched %d updated task(s) from %s\n", len(updated), remote)
+		for _, id := range updated {
+			fmt.Printf("  %s\n", id)
+		}
+	}
+
+	return nil
+}
+
+// openGitRefStore opens the git-refs store for push and fetch and resolves the remote.
+// Fails unless the repository is configured with task_store=git.
+func openGitRefStore(opts *StoreSyncOptions) (*task.GitRefStore, string, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, "", errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if cfg.TaskStore != task.BackendGit {
+		return nil, "", fmt.Errorf("task_store is %s; push and fetch need the git store\nRun 'awt store migrate --to=git' first", cfg.TaskStore)
+	}
+
+	remote := opts.Remote
+	if remote == "" {
+		remote = cfg.RemoteName
+	}
+
+	return task.NewGitRefStore(r.GitCommonDir).WithTimeouts(gitTimeouts(cfg)), remote, nil
+}
+
+func runStoreMigrate(opts *StoreMigrateOptions) error {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	from := opts.From
+	if from == "" {
+		from = cfg.TaskStore
+	}
+	if err := task.ValidateBackend(from); err != nil {
+		return err
+	}
+	if err := task.ValidateBackend(opts.To); err != nil {
+		return err
+	}
+	if from == opts.To {
+		return fmt.Errorf("source and destination backends are both %s", from)
+	}
+
+	// Hold the global lock so no task is created or removed mid-migration
+	lm := newLockManager(r, cfg)
+	globalLock, err := lm.AcquireGlobal(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to acquire global lock: %w", err)
+	}
+	defer func() {
+		_ = globalLock.Release()
+	}()
+
+	src, err := task.OpenStore(r.GitCommonDir, from)
+	if err != nil {
+		return fmt.Errorf("failed to open %s store: %w", from, err)
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	dst, err := task.OpenStore(r.GitCommonDir, opts.To)
+	if err != nil {
+		return fmt.Errorf("failed to open %s store: %w", opts.To, err)
+	}
+	defer func() {
+		_ = dst.Close()
+	}()
+
+	result, err := migrateTasks(src, dst)
+	if err != nil {
+		return err
+	}
+	result.From = from
+	result.To = opts.To
+
+	// Point the repository at the new backend
+	if !opts.NoSwitch {
+		if err := setRepoTaskStore(configLoader, opts.To); err != nil {
+			return err
+		}
+		result.Switched = true
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Migrated %d task(s) from %s to %s\n", len(result.Migrated), from, opts.To)
+		if result.Switched {
+			fmt.Printf("  task_store = %s (scope: repo)\n", opts.To)
+		}
+	}
+
+	return nil
+}
+
+// migrateTasks copies every task from src to dst, replacing existing copies in dst
+func migrateTasks(src, dst task.Store) (*StoreMigrateResult, error) {
+	tasks, err := src.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	result := &StoreMigrateResult{Migrated: []string{}}
+	for _, t := range tasks {
+		// Delete first so Save does not check the copy's state against a stale one
+		if err := dst.Delete(t.ID); err != nil {
+			return nil, err
+		}
+		if err := dst.Save(t); err != nil {
+			return nil, fmt.Errorf("failed to migrate task %s: %w", t.ID, err)
+		}
+		result.Migrated = append(result.Migrated, t.ID)
+	}
+
+	return result, nil
+}
+
+// setRepoTaskStore sets task_store in the repository config
+func setRepoTaskStore(loader *config.ConfigLoader, backend string) error {
+	cfg := config.Default()
+	scopePath, _ := loader.GetConfigPath("repo")
+	if data, err := os.ReadFile(scopePath); err == nil {
+		_ = json.Unmarshal(data, cfg)
+	}
+
+	cfg.TaskStore = backend
+	if err := loader.Save(cfg, "repo"); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// openTaskStore opens the task store selected by the task_store setting
+func openTaskStore(r *repo.Repo) (task.Store, error) {
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := task.OpenStore(r.GitCommonDir, cfg.TaskStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open task store: %w", err)
+	}
+
+	return store, nil
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestRunStoreMigrate(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	for _, agent := range []string{"claude", "codex"} {
+		startOpts := &StartOptions{
+			RepoPath:     repoPath,
+			Agent:        agent,
+			Title:        "Migrated task",
+			Base:         "HEAD",
+			ID:           "task-" + agent,
+			NoFetch:      true,
+			BranchPrefix: "awt",
+			OutputJSON:   true,
+		}
+		if err := runTaskStart(startOpts); err != nil {
+			t.Fatalf("runTaskStart() failed: %v", err)
+		}
+	}
+
+	if err := runStoreMigrate(&StoreMigrateOptions{RepoPath: repoPath, To: task.BackendSQLite, OutputJSON: true}); err != nil {
+		t.Fatalf("runStoreMigrate() failed: %v", err)
+	}
+
+	// The repository now uses the SQLite store
+	cfg, err := config.NewConfigLoader(filepath.Join(repoPath, ".git")).Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.TaskStore != task.BackendSQLite {
+		t.Fatalf("task_store = %q, want %q", cfg.TaskStore, task.BackendSQLite)
+	}
+
+	r, err := repo.DiscoverRepo(repoPath)
+	if err != nil {
+		t.Fatalf("failed to discover repo: %v", err)
+	}
+	store, err := openTaskStore(r)
+	if err != nil {
+		t.Fatalf("openTaskStore() failed: %v", err)
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+	if _, ok := store.(*task.SQLiteStore); !ok {
+		t.Fatalf("openTaskStore() = %T, want *task.SQLiteStore", store)
+	}
+
+	found, err := store.Find(task.Filter{Agent: "codex"})
+	if err != nil {
+		t.Fatalf("Find() failed: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != "task-codex" {
+		t.Errorf("Find(agent=codex) = %v, want task-codex", found)
+	}
+
+	// Migrating to the backend already in use is refused
+	if err := runStoreMigrate(&StoreMigrateOptions{RepoPath: repoPath, To: task.BackendSQLite, OutputJSON: true}); err == nil {
+		t.Error("expected error migrating to the current backend")
+	}
+}
@@ -1,10 +1,12 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/kernel-labs-ai/awt/internal/git"
 	"github.com/kernel-labs-ai/awt/internal/repo"
@@ -71,58 +73,108 @@ Example:
 }
 
 func runTaskSync(opts *SyncOptions) error {
+	result, err := cliClient(opts.OutputJSON).Sync(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Synced successfully!\n")
+		fmt.Printf("  Task: %s\n", result.TaskID)
+		fmt.Printf("  Strategy: %s\n", result.Strategy)
+		fmt.Printf("  Base: %s\n", result.Base)
+		if opts.Submodules {
+			fmt.Printf("  Submodules: updated\n")
+		}
+	}
+
+	return nil
+}
+
+// Sync rebases (or merges) the task branch onto its base
+func (c *Client) Sync(ctx context.Context, opts *SyncOptions) (*SyncResult, error) {
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
 	}
 
-	store := task.NewTaskStore(r.GitCommonDir)
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
 
 	// Determine task ID
-	taskID := opts.TaskID
+	taskID, err := resolveTaskID(ctx, r, store, opts.TaskID, opts.Branch)
+	if err != nil {
+		return nil, err
+	}
 
-	if taskID == "" && opts.Branch != "" {
-		// Extract task ID from branch name
-		taskID = extractTaskIDFromBranch(opts.Branch)
-		if taskID == "" {
-			return fmt.Errorf("could not extract task ID from branch: %s", opts.Branch)
-		}
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if taskID == "" {
-		// Try to infer from current worktree
-		taskID, err = inferTaskIDFromCurrentDirectory(r)
-		if err != nil {
-			return fmt.Errorf("could not infer task ID: %w\nProvide task ID as argument or use --branch flag", err)
-		}
+	// Hold the task lock so concurrent commands cannot mutate the task
+	taskLock, err := acquireTaskLock(ctx, r, cfg, taskID)
+	if err != nil {
+		return nil, err
 	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
 
 	// Load task
 	t, err := store.Load(taskID)
 	if err != nil {
-		return errors.InvalidTaskID(taskID)
+		return nil, errors.InvalidTaskID(taskID)
+	}
+
+	// Only active tasks own their branch
+	if err := t.RequireState("sync", task.StateActive); err != nil {
+		return nil, err
+	}
+	if err := requireWorktree(t); err != nil {
+		return nil, err
+	}
+
+	// Record the pre-operation state so the command can be undone
+	if err := recordOperation(ctx, r, "task sync", t, false); err != nil {
+		return nil, err
 	}
 
 	// Create Git wrapper for the worktree
-	g := git.New(t.WorktreePath, false)
+	g := git.New(t.WorktreePath, false).WithTimeouts(gitTimeouts(cfg))
 
 	// Fetch base ref
-	result, err := g.Fetch("", "")
+	result, err := g.Fetch(ctx, "", "")
 	if err != nil || result.ExitCode != 0 {
 		// Check if it's a shallow clone
 		if strings.Contains(result.Stderr, "shallow") {
 			// Try to unshallow
-			result, err = g.FetchUnshallow()
+			result, err = g.FetchUnshallow(ctx)
+			if errors.ExitCodeOf(err) == errors.ExitGitTimeout {
+				return nil, err
+			}
 			if err != nil || result.ExitCode != 0 {
-				return fmt.Errorf("failed to unshallow repository: %s", result.Stderr)
+				return nil, errors.SyncFailed(t.Branch, "unshallow the repository for", result.Stderr)
 			}
+		} else if err != nil {
+			// Timed out or could not run; continue with local refs like an offline fetch
+			c.progressf("Warning: fetch failed, continuing with local refs: %v\n", err)
 		} else {
 			// Fetch failed, but continue anyway (might be offline)
 			// Log warning but don't fail
-			if !opts.OutputJSON {
-				fmt.Printf("Warning: fetch failed, continuing with local refs: %s\n", result.Stderr)
-			}
+			c.progressf("Warning: fetch failed, continuing with local refs: %s\n", result.Stderr)
 		}
 	}
 
@@ -135,46 +187,50 @@ func runTaskSync(opts *SyncOptions) error {
 	// Execute sync
 	var syncResult *git.Result
 	if strategy == "merge" {
-		syncResult, err = g.Merge(t.Base)
+		syncResult, err = g.Merge(ctx, t.Base)
 	} else {
-		syncResult, err = g.Rebase(t.Base)
+		syncResult, err = g.Rebase(ctx, t.Base)
 	}
 
-	if err != nil || syncResult.ExitCode != 0 {
+	if err != nil {
+		// git was stopped part way (timeout or cancellation), so put the branch back
+		abortInterrupted(ctx, g)
+		recordEvent(r, taskID, task.Event{Type: task.EventSync, Strategy: strategy, Result: task.ResultFailed, Message: "stopped while syncing onto " + t.Base})
+		return nil, err
+	}
+	if syncResult.ExitCode != 0 {
 		// Check for conflicts
 		if strings.Contains(syncResult.Stderr, "conflict") || strings.Contains(syncResult.Stdout, "conflict") {
-			return errors.SyncConflicts(t.Branch)
+			recordEvent(r, taskID, task.Event{Type: task.EventSync, Strategy: strategy, Result: task.ResultConflict, Message: "onto " + t.Base})
+			return nil, errors.SyncConflicts(t.Branch)
 		}
-		return fmt.Errorf("failed to %s: %s", strategy, syncResult.Stderr)
+		recordEvent(r, taskID, task.Event{Type: task.EventSync, Strategy: strategy, Result: task.ResultFailed, Message: strings.TrimSpace(syncResult.Stderr)})
+		return nil, errors.SyncFailed(t.Branch, strategy, syncResult.Stderr)
 	}
+	head, _ := g.RevParse(ctx, "HEAD")
+	recordEvent(r, taskID, task.Event{Type: task.EventSync, Strategy: strategy, Result: task.ResultOK, SHA: head, Message: "onto " + t.Base})
 
 	// Update submodules if requested
 	if opts.Submodules {
-		subResult, err := g.SubmoduleUpdate()
+		subResult, err := g.SubmoduleUpdate(ctx)
 		if err != nil || subResult.ExitCode != 0 {
-			return fmt.Errorf("failed to update submodules: %s", subResult.Stderr)
+			return nil, errors.SubmoduleFailed(t.WorktreePath, subResult.Stderr)
 		}
 	}
 
-	// Output result
-	if opts.OutputJSON {
-		output := SyncResult{
-			TaskID:   taskID,
-			Strategy: strategy,
-			Base:     t.Base,
-			Success:  true,
-		}
-		data, _ := json.MarshalIndent(output, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		fmt.Printf("Synced successfully!\n")
-		fmt.Printf("  Task: %s\n", taskID)
-		fmt.Printf("  Strategy: %s\n", strategy)
-		fmt.Printf("  Base: %s\n", t.Base)
-		if opts.Submodules {
-			fmt.Printf("  Submodules: updated\n")
-		}
-	}
+	return &SyncResult{
+		TaskID:   taskID,
+		Strategy: strategy,
+		Base:     t.Base,
+		Success:  true,
+	}, nil
+}
 
-	return nil
+// abortInterrupted aborts the rebase or merge that a stopped git process left in progress.
+// It runs even if ctx was canceled, since leaving the worktree mid-rebase is worse.
+func abortInterrupted(ctx context.Context, g *git.Git) {
+	ctx = context.WithoutCancel(ctx)
+	if op, err := g.OperationInProgress(ctx); err == nil && op != "" {
+		_, _ = g.AbortOperation(ctx, op)
+	}
 }
@@ -0,0 +1,559 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/config"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/git"
+	"github.com/kernel-labs-ai/awt/internal/oplog"
+	"github.com/kernel-labs-ai/awt/internal/repo"
+	"github.com/kernel-labs-ai/awt/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// UndoOptions contains options for the undo command
+type UndoOptions struct {
+	RepoPath   string
+	OpID       string
+	List       bool
+	Force      bool
+	OutputJSON bool
+}
+
+// UndoResult represents the output of the undo command
+type UndoResult struct {
+	OpID         string `json:"op_id"`
+	UndoID       string `json:"undo_id"`
+	Command      string `json:"command"`
+	TaskID       string `json:"task_id"`
+	Branch       string `json:"branch,omitempty"`
+	BranchOID    string `json:"branch_oid,omitempty"`
+	State        string `json:"state,omitempty"`
+	WorktreePath string `json:"worktree_path,omitempty"`
+	Aborted      string `json:"aborted,omitempty"`
+	TaskDeleted  bool   `json:"task_deleted,omitempty"`
+}
+
+// OperationListItem is an operation log entry as shown by 'awt undo --list'
+type OperationListItem struct {
+	*oplog.Operation
+	UndoneBy string `json:"undone_by,omitempty"`
+}
+
+// NewUndoCmd creates the undo command
+func NewUndoCmd() *cobra.Command {
+	opts := &UndoOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "undo [op-id]",
+		Short: "Undo a task operation",
+		Long: `Undo an operation recorded in the operation log.
+
+Before a command mutates a task (start, commit, sync, handoff, feedback,
+abandon, reopen), AWT records the task branch, the worktree HEAD and a
+snapshot of the task metadata. Undo restores them:
+  - an interrupted rebase or merge in the worktree is aborted
+  - the task branch is moved back (undoing a commit keeps its changes staged)
+  - the worktree is recreated, switched back or removed as it was before
+  - the task metadata is restored; a state change is recorded in its history
+Undoing 'task start' removes the task, its worktree and its branch.
+
+Without an op ID the most recent operation that has not been undone is undone,
+so repeated undos walk back through the log. MERGED is terminal: reconcile is
+not recorded, and operations on tasks merged since are skipped. Pushes, pull
+requests and remote branch deletions are not undone. Undo is itself recorded,
+so it can be undone.
+
+Example:
+  awt undo
+  awt undo --list
+  awt undo 20250110-120000-abc123`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.OpID = args[0]
+			}
+			return runUndo(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "path to Git repository")
+	cmd.Flags().BoolVar(&opts.List, "list", false, "list recorded operations instead of undoing one")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "undo even if later operations touched the task, discarding uncommitted changes")
+	cmd.Flags().BoolVar(&opts.OutputJSON, "json", false, "output result as JSON")
+
+	return cmd
+}
+
+func runUndo(opts *UndoOptions) error {
+	if opts.List {
+		return listOperations(opts)
+	}
+
+	result, err := cliClient(opts.OutputJSON).Undo(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Undid %s (%s) on task %s\n", result.OpID, result.Command, result.TaskID)
+		if result.Aborted != "" {
+			fmt.Printf("  Aborted: interrupted %s\n", result.Aborted)
+		}
+		if result.TaskDeleted {
+			fmt.Printf("  Task, worktree and branch removed\n")
+		} else {
+			if result.BranchOID != "" {
+				fmt.Printf("  Branch: %s at %s\n", result.Branch, result.BranchOID)
+			}
+			fmt.Printf("  State: %s\n", result.State)
+			if result.WorktreePath != "" {
+				fmt.Printf("  Worktree: %s\n", result.WorktreePath)
+			}
+		}
+		fmt.Printf("  Undo again with: awt undo %s\n", result.UndoID)
+	}
+
+	return nil
+}
+
+// Undo reverts a recorded operation, the most recent undoable one unless opts.OpID is set
+func (c *Client) Undo(ctx context.Context, opts *UndoOptions) (*UndoResult, error) {
+	// Discover repository
+	r, err := repo.DiscoverRepo(opts.RepoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(opts.RepoPath)
+	}
+
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	// Pick the operation
+	log := oplog.NewLog(r.GitCommonDir)
+	var op *oplog.Operation
+	if opts.OpID != "" {
+		op, err = log.Get(opts.OpID)
+	} else {
+		op, err = log.LastUndoable(func(op *oplog.Operation) bool {
+			return canRestore(store, op)
+		})
+		if err == nil && op == nil {
+			err = fmt.Errorf("nothing to undo")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold the task lock, then the global lock for worktree changes
+	taskLock, err := acquireTaskLock(ctx, r, cfg, op.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
+
+	lm := newLockManager(r, cfg)
+	globalLock, err := lm.AcquireGlobal(ctx)
+	if err != nil {
+		return nil, errors.LockTimeout("global")
+	}
+	defer func() {
+		_ = globalLock.Release()
+	}()
+
+	if err := checkUndoable(log, op, opts.Force); err != nil {
+		return nil, err
+	}
+
+	result, err := undoOperation(ctx, r, cfg, store, log, op, opts.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// listOperations prints the operation log, newest first
+func listOperations(opts *UndoOptions) error {
+	items, err := cliClient(opts.OutputJSON).Operations(context.Background(), opts.RepoPath)
+	if err != nil {
+		return err
+	}
+
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(items, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(items) == 0 {
+		fmt.Println("No operations recorded")
+		return nil
+	}
+
+	fmt.Printf("%-23s %-20s %-16s %-23s %s\n", "OP", "TIME", "COMMAND", "TASK", "UNDONE")
+	fmt.Println(strings.Repeat("-", 100))
+	for _, item := range items {
+		command := item.Command
+		if item.UndoOf != "" {
+			command = "undo " + item.UndoOf
+		}
+		undoneBy := "-"
+		if item.UndoneBy != "" {
+			undoneBy = "by " + item.UndoneBy
+		}
+		fmt.Printf("%-23s %-20s %-16s %-23s %s\n", item.ID, item.Time.Format("2006-01-02 15:04:05"), command, item.TaskID, undoneBy)
+	}
+
+	return nil
+}
+
+// Operations returns the recorded operations, newest first
+func (c *Client) Operations(ctx context.Context, repoPath string) ([]OperationListItem, error) {
+	r, err := repo.DiscoverRepo(repoPath)
+	if err != nil {
+		return nil, errors.RepoNotFound(repoPath)
+	}
+
+	ops, err := oplog.NewLog(r.GitCommonDir).List()
+	if err != nil {
+		return nil, err
+	}
+	undone := oplog.UndoneBy(ops)
+
+	items := []OperationListItem{}
+	for i := len(ops) - 1; i >= 0; i-- {
+		items = append(items, OperationListItem{Operation: ops[i], UndoneBy: undone[ops[i].ID]})
+	}
+	return items, nil
+}
+
+// checkUndoable refuses to undo an operation that was already undone, or one that later
+// operations on the same task build on (unless forced), since restoring it would discard them
+func checkUndoable(log *oplog.Log, op *oplog.Operation, force bool) error {
+	ops, err := log.List()
+	if err != nil {
+		return err
+	}
+	undone := oplog.UndoneBy(ops)
+	if by := undone[op.ID]; by != "" {
+		return errors.UndoRefused(op.ID, "it was already undone by "+by, fmt.Sprintf("Use 'awt undo %s' to redo it.", by))
+	}
+	if force {
+		return nil
+	}
+
+	seen := false
+	for _, later := range ops {
+		if later.ID == op.ID {
+			seen = true
+			continue
+		}
+		if seen && later.TaskID == op.TaskID && later.UndoOf == "" && undone[later.ID] == "" {
+			return errors.UndoRefused(
+				op.ID,
+				fmt.Sprintf("task %s was changed since by %s (%s)", op.TaskID, later.ID, later.Command),
+				"Undo the later operations first (run 'awt undo' repeatedly), or use --force.",
+			)
+		}
+	}
+	return nil
+}
+
+// canRestore reports whether the task metadata recorded by op can be restored: the task's
+// current state must be allowed to change back to the recorded one. Operations on a task
+// that has since been merged therefore cannot be undone.
+func canRestore(store task.Store, op *oplog.Operation) bool {
+	current, err := store.Load(op.TaskID)
+	if err != nil {
+		return true
+	}
+	snapshot, err := op.Snapshot()
+	if err != nil {
+		return true
+	}
+	if snapshot == nil {
+		return current.State != task.StateMerged
+	}
+	return current.State == snapshot.State || task.CanTransition(current.State, snapshot.State)
+}
+
+// undoOperation restores the task branch, worktree and metadata recorded by op, and
+// records the undo as an operation of its own. The undo is only recorded once the
+// restore succeeded, so a failed undo can be retried and does not mark op as undone.
+// The caller must hold the task lock and the global lock.
+func undoOperation(ctx context.Context, r *repo.Repo, cfg *config.Config, store task.Store, log *oplog.Log, op *oplog.Operation, force bool) (*UndoResult, error) {
+	snapshot, err := op.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode task snapshot of %s: %w", op.ID, err)
+	}
+	current, err := store.Load(op.TaskID)
+	if err != nil {
+		current = nil
+	}
+
+	// Check the state change up front so nothing is touched if the metadata cannot be restored
+	if snapshot != nil && current != nil && current.State != snapshot.State && !task.CanTransition(current.State, snapshot.State) {
+		return nil, errors.InvalidTransition(op.TaskID, string(current.State), string(snapshot.State))
+	}
+
+	// Capture the state before the undo, so the undo can be undone in turn
+	undoOp, err := newOperation(ctx, r, "undo", currentOrPlaceholder(current, op), current == nil)
+	if err != nil {
+		return nil, err
+	}
+	undoOp.UndoOf = op.ID
+
+	result, err := restoreOperation(ctx, r, cfg, store, op, snapshot, current, force)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := log.Record(undoOp); err != nil {
+		return nil, fmt.Errorf("failed to record operation: %w", err)
+	}
+	result.UndoID = undoOp.ID
+	return result, nil
+}
+
+// restoreOperation puts the task branch, worktree and metadata back to the state
+// recorded by op, whose decoded task snapshot is given (nil if op created the task).
+// current is the task as it is now, or nil if it no longer exists.
+func restoreOperation(ctx context.Context, r *repo.Repo, cfg *config.Config, store task.Store, op *oplog.Operation, snapshot, current *task.Task, force bool) (*UndoResult, error) {
+	result := &UndoResult{
+		OpID:    op.ID,
+		Command: op.Command,
+		TaskID:  op.TaskID,
+		Branch:  op.Branch,
+	}
+
+	repoGit := git.New(r.WorkTreeRoot, cfg.VerboseGit)
+
+	// Find the task's current worktree, if it still exists
+	worktreePath := ""
+	if current != nil && current.WorktreePath != "" {
+		if _, err := os.Stat(current.WorktreePath); err == nil {
+			worktreePath = current.WorktreePath
+		}
+	}
+
+	// Abort an interrupted rebase or merge left by the operation
+	if worktreePath != "" {
+		wtGit := git.New(worktreePath, cfg.VerboseGit)
+		inProgress, err := wtGit.OperationInProgress(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect worktree: %w", err)
+		}
+		if inProgress != "" {
+			abortResult, err := wtGit.AbortOperation(ctx, inProgress)
+			if err != nil || abortResult.ExitCode != 0 {
+				return nil, fmt.Errorf("failed to abort %s: %s", inProgress, abortResult.Stderr)
+			}
+			result.Aborted = inProgress
+		}
+	}
+
+	// Undoing the creation of a task removes it entirely
+	if snapshot == nil {
+		if worktreePath != "" {
+			removeResult, err := repoGit.WorktreeRemove(ctx, worktreePath, force)
+			if err != nil || removeResult.ExitCode != 0 {
+				return nil, errors.RemoveFailed(worktreePath, fmt.Errorf("%s (use --force to discard changes)", removeResult.Stderr))
+			}
+		}
+		if op.BranchOID == "" {
+			if exists, _ := repoGit.BranchExists(ctx, op.Branch); exists {
+				deleteResult, err := repoGit.DeleteBranch(ctx, op.Branch, true)
+				if err != nil || deleteResult.ExitCode != 0 {
+					return nil, fmt.Errorf("failed to delete branch %s: %s", op.Branch, deleteResult.Stderr)
+				}
+			}
+		}
+		if current != nil {
+			if err := store.Delete(op.TaskID); err != nil {
+				return nil, fmt.Errorf("failed to delete task metadata: %w", err)
+			}
+		}
+		_ = task.NewEventLog(r.GitCommonDir).Delete(op.TaskID)
+		result.TaskDeleted = true
+		return result, nil
+	}
+
+	// Move the branch back
+	if op.BranchOID != "" {
+		onBranch := false
+		if worktreePath != "" {
+			branch, _ := git.New(worktreePath, cfg.VerboseGit).CurrentBranch(ctx)
+			onBranch = branch == op.Branch
+		}
+		if onBranch {
+			// Reset through the worktree so its files follow. --keep refuses to overwrite
+			// uncommitted changes; a commit is undone softly so its changes stay staged.
+			mode := "--keep"
+			if op.Command == "task commit" {
+				mode = "--soft"
+			} else if force {
+				mode = "--hard"
+			}
+			resetResult, err := git.New(worktreePath, cfg.VerboseGit).Reset(ctx, mode, op.BranchOID)
+			if err != nil || resetResult.ExitCode != 0 {
+				return nil, fmt.Errorf("failed to reset %s to %s: %s\nCommit or stash the worktree changes, or use --force to discard them", op.Branch, op.BranchOID, resetResult.Stderr)
+			}
+		} else {
+			currentOID, _ := repoGit.RevParse(ctx, "refs/heads/"+op.Branch)
+			if currentOID != op.BranchOID {
+				if err := repoGit.UpdateRef(ctx, "refs/heads/"+op.Branch, op.BranchOID, currentOID); err != nil {
+					return nil, fmt.Errorf("failed to restore branch %s: %w", op.Branch, err)
+				}
+			}
+		}
+		result.BranchOID = op.BranchOID
+	}
+
+	restored := *snapshot
+	restored.WorktreePath = ""
+
+	// Put the worktree back the way it was
+	switch {
+	case op.WorktreePath != "" && worktreePath == "":
+		if err := recreateTaskWorktree(ctx, r, cfg, &restored); err != nil {
+			return nil, err
+		}
+		if op.HeadRef == "" && op.Head != "" {
+			switchResult, err := git.New(restored.WorktreePath, cfg.VerboseGit).Switch(ctx, op.Head, true)
+			if err != nil || switchResult.ExitCode != 0 {
+				return nil, fmt.Errorf("failed to detach worktree at %s: %s", op.Head, switchResult.Stderr)
+			}
+		}
+	case op.WorktreePath != "":
+		restored.WorktreePath = worktreePath
+		wtGit := git.New(worktreePath, cfg.VerboseGit)
+		branch, _ := wtGit.CurrentBranch(ctx)
+		if op.HeadRef != "" && branch != op.HeadRef {
+			switchResult, err := wtGit.Switch(ctx, op.HeadRef, false)
+			if err != nil || switchResult.ExitCode != 0 {
+				return nil, fmt.Errorf("failed to switch worktree to %s: %s", op.HeadRef, switchResult.Stderr)
+			}
+		} else if op.HeadRef == "" && op.Head != "" {
+			if head, _ := wtGit.RevParse(ctx, "HEAD"); branch != "" || head != op.Head {
+				switchResult, err := wtGit.Switch(ctx, op.Head, true)
+				if err != nil || switchResult.ExitCode != 0 {
+					return nil, fmt.Errorf("failed to detach worktree at %s: %s", op.Head, switchResult.Stderr)
+				}
+			}
+		}
+	case worktreePath != "":
+		removeResult, err := repoGit.WorktreeRemove(ctx, worktreePath, force)
+		if err != nil || removeResult.ExitCode != 0 {
+			return nil, errors.RemoveFailed(worktreePath, fmt.Errorf("%s (use --force to discard changes)", removeResult.Stderr))
+		}
+	}
+
+	// Restore the metadata on top of the current record, keeping its history
+	if current != nil {
+		restored.Revision = current.Revision
+		restored.History = current.History
+		if current.State != snapshot.State {
+			restored.History = append(restored.History, task.Transition{
+				From:    current.State,
+				To:      snapshot.State,
+				At:      time.Now(),
+				Actor:   currentActor(),
+				Command: "undo",
+				Reason:  fmt.Sprintf("undo of %s (%s)", op.Command, op.ID),
+			})
+		}
+	} else {
+		restored.Revision = 0
+	}
+	if err := store.Save(&restored); err != nil {
+		return nil, fmt.Errorf("failed to restore task metadata: %w", err)
+	}
+	recordEvent(r, op.TaskID, task.Event{Type: task.EventUndo, Message: fmt.Sprintf("%s (%s)", op.Command, op.ID)})
+
+	result.State = string(restored.State)
+	result.WorktreePath = restored.WorktreePath
+	return result, nil
+}
+
+// currentOrPlaceholder returns the current task, or a placeholder naming the task and
+// branch of op when the task no longer exists
+func currentOrPlaceholder(current *task.Task, op *oplog.Operation) *task.Task {
+	if current != nil {
+		return current
+	}
+	return &task.Task{ID: op.TaskID, Branch: op.Branch}
+}
+
+// newOperation captures the task's branch, worktree HEAD and metadata.
+// creates is set when the command creates the task, so there is no metadata to snapshot.
+func newOperation(ctx context.Context, r *repo.Repo, command string, t *task.Task, creates bool) (*oplog.Operation, error) {
+	op := &oplog.Operation{
+		Command: command,
+		Actor:   currentActor(),
+		TaskID:  t.ID,
+		Branch:  strings.TrimPrefix(t.Branch, "refs/heads/"),
+	}
+
+	g := git.New(r.WorkTreeRoot, false)
+	if exists, _ := g.BranchExists(ctx, op.Branch); exists {
+		op.BranchOID, _ = g.RevParse(ctx, "refs/heads/"+op.Branch)
+	}
+
+	if t.WorktreePath != "" {
+		if _, err := os.Stat(t.WorktreePath); err == nil {
+			wtGit := git.New(t.WorktreePath, false)
+			op.WorktreePath = t.WorktreePath
+			op.Head, _ = wtGit.RevParse(ctx, "HEAD")
+			op.HeadRef, _ = wtGit.CurrentBranch(ctx)
+		}
+	}
+
+	if !creates {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot task: %w", err)
+		}
+		op.Task = data
+	}
+
+	return op, nil
+}
+
+// recordOperation records the task's branch, worktree HEAD and metadata in the operation
+// log before command mutates them, so 'awt undo' can restore them.
+// creates is set when the command creates the task.
+func recordOperation(ctx context.Context, r *repo.Repo, command string, t *task.Task, creates bool) error {
+	op, err := newOperation(ctx, r, command, t, creates)
+	if err != nil {
+		return err
+	}
+	if err := oplog.NewLog(r.GitCommonDir).Record(op); err != nil {
+		return fmt.Errorf("failed to record operation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kernel-labs-ai/awt/internal/oplog"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+func TestRunUndoRestoresCommitAndHandoff(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Undo task",
+		Base:         "HEAD",
+		ID:           "undo-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	gitCommonDir := filepath.Join(repoPath, ".git")
+	store := task.NewTaskStore(gitCommonDir)
+	started, err := store.Load("undo-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	branchHead := func() string {
+		out, err := exec.Command("git", "-C", repoPath, "rev-parse", started.Branch).Output()
+		if err != nil {
+			t.Fatalf("failed to resolve branch: %v", err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	base := branchHead()
+
+	// Commit, then hand off (removing the worktree)
+	if err := os.WriteFile(filepath.Join(started.WorktreePath, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := runTaskCommit(&CommitOptions{RepoPath: repoPath, TaskID: "undo-task", Message: "Add feature", All: true, OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskCommit() failed: %v", err)
+	}
+	if err := runTaskHandoff(&HandoffOptions{RepoPath: repoPath, TaskID: "undo-task", NoPush: true, NoPR: true, OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskHandoff() failed: %v", err)
+	}
+
+	// Undo the handoff: the task is ACTIVE again with a worktree on its branch
+	if err := runUndo(&UndoOptions{RepoPath: repoPath, OutputJSON: true}); err != nil {
+		t.Fatalf("undo of handoff failed: %v", err)
+	}
+	tk, err := store.Load("undo-task")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if tk.State != task.StateActive {
+		t.Errorf("state after undoing handoff = %s, want ACTIVE", tk.State)
+	}
+	if _, err := os.Stat(filepath.Join(tk.WorktreePath, "feature.txt")); err != nil {
+		t.Errorf("worktree was not restored: %v", err)
+	}
+	if last := tk.History[len(tk.History)-1]; last.Command != "undo" || last.To != task.StateActive {
+		t.Errorf("last transition = %+v, want undo to ACTIVE", last)
+	}
+
+	// Undo the commit: the branch moves back and the change stays staged
+	if err := runUndo(&UndoOptions{RepoPath: repoPath, OutputJSON: true}); err != nil {
+		t.Fatalf("undo of commit failed: %v", err)
+	}
+	if got := branchHead(); got != base {
+		t.Errorf("branch after undoing commit = %s, want %s", got, base)
+	}
+	status, err := exec.Command("git", "-C", tk.WorktreePath, "status", "--porcelain").Output()
+	if err != nil || !strings.Contains(string(status), "A  feature.txt") {
+		t.Errorf("expected feature.txt staged after undoing commit, got %q (%v)", status, err)
+	}
+	tk, _ = store.Load("undo-task")
+	if tk.LastCommit != "" {
+		t.Errorf("last_commit after undoing commit = %q, want empty", tk.LastCommit)
+	}
+
+	// An undone operation cannot be undone twice, but the undo itself can be undone
+	ops, err := oplog.NewLog(gitCommonDir).List()
+	if err != nil {
+		t.Fatalf("failed to list operations: %v", err)
+	}
+	undone := oplog.UndoneBy(ops)
+	var commitOp *oplog.Operation
+	for _, op := range ops {
+		if op.Command == "task commit" {
+			commitOp = op
+		}
+	}
+	if commitOp == nil || undone[commitOp.ID] == "" {
+		t.Fatalf("commit operation not marked undone: %+v", ops)
+	}
+	if err := runUndo(&UndoOptions{RepoPath: repoPath, OpID: commitOp.ID}); err == nil {
+		t.Error("expected error undoing an operation twice")
+	}
+
+	// Undo the start (forced, since the worktree has a staged change): the task is gone
+	if err := runUndo(&UndoOptions{RepoPath: repoPath, Force: true, OutputJSON: true}); err != nil {
+		t.Fatalf("undo of start failed: %v", err)
+	}
+	if _, err := store.Load("undo-task"); err == nil {
+		t.Error("task still exists after undoing start")
+	}
+	if _, err := os.Stat(tk.WorktreePath); !os.IsNotExist(err) {
+		t.Errorf("worktree still exists after undoing start: %v", err)
+	}
+	if err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", started.Branch).Run(); err == nil {
+		t.Error("branch still exists after undoing start")
+	}
+}
+
+func TestRunUndoRetriesAfterFailedRestore(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	base := gitOutput(t, repoPath, "rev-parse", "--abbrev-ref", "HEAD")
+	startOpts := &StartOptions{
+		RepoPath:     repoPath,
+		Agent:        "test-agent",
+		Title:        "Undo retry task",
+		Base:         base,
+		ID:           "undo-retry",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+		OutputJSON:   true,
+	}
+	if err := runTaskStart(startOpts); err != nil {
+		t.Fatalf("runTaskStart() failed: %v", err)
+	}
+
+	gitCommonDir := filepath.Join(repoPath, ".git")
+	store := task.NewTaskStore(gitCommonDir)
+	tk, err := store.Load("undo-retry")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	before := gitOutput(t, repoPath, "rev-parse", tk.Branch)
+
+	// Move the base on and sync the task onto it
+	readme := filepath.Join(repoPath, "README.md")
+	if err := os.WriteFile(readme, []byte("# Test Repo\n\nUpdated\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	gitOutput(t, repoPath, "commit", "-am", "Update readme")
+	if err := runTaskSync(&SyncOptions{RepoPath: repoPath, TaskID: "undo-retry", OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskSync() failed: %v", err)
+	}
+
+	// A local change to a file the sync brought in makes the undo refuse to reset
+	wtReadme := filepath.Join(tk.WorktreePath, "README.md")
+	if err := os.WriteFile(wtReadme, []byte("local change\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := runUndo(&UndoOptions{RepoPath: repoPath, OutputJSON: true}); err == nil {
+		t.Fatal("expected undo to fail with a dirty worktree")
+	}
+
+	ops, err := oplog.NewLog(gitCommonDir).List()
+	if err != nil {
+		t.Fatalf("failed to list operations: %v", err)
+	}
+	for _, op := range ops {
+		if op.UndoOf != "" {
+			t.Errorf("failed undo was recorded: %+v", op)
+		}
+	}
+
+	// Once the change is discarded the undo can be retried
+	gitOutput(t, tk.WorktreePath, "checkout", "--", "README.md")
+	if err := runUndo(&UndoOptions{RepoPath: repoPath, OutputJSON: true}); err != nil {
+		t.Fatalf("retried undo failed: %v", err)
+	}
+	if got := gitOutput(t, repoPath, "rev-parse", tk.Branch); got != before {
+		t.Errorf("branch after undoing sync = %s, want %s", got, before)
+	}
+
+	ops, err = oplog.NewLog(gitCommonDir).List()
+	if err != nil {
+		t.Fatalf("failed to list operations: %v", err)
+	}
+	undone := oplog.UndoneBy(ops)
+	for _, op := range ops {
+		if op.Command == "task sync" && undone[op.ID] == "" {
+			t.Errorf("sync operation not marked undone: %+v", op)
+		}
+	}
+}
+
+func TestRunUndoSkipsMergedTasks(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+
+	older := startReconcileTask(t, repoPath, "older-task")
+	merged := startReconcileTask(t, repoPath, "merged-task")
+	gitOutput(t, repoPath, "merge", "--no-ff", "-m", "merge", merged.Branch)
+	if err := runTaskReconcile(&ReconcileOptions{RepoPath: repoPath, NoFetch: true, OutputJSON: true}); err != nil {
+		t.Fatalf("runTaskReconcile() failed: %v", err)
+	}
+
+	gitCommonDir := filepath.Join(repoPath, ".git")
+	ops, err := oplog.NewLog(gitCommonDir).List()
+	if err != nil {
+		t.Fatalf("failed to list operations: %v", err)
+	}
+	for _, op := range ops {
+		if op.Command == "task reconcile" {
+			t.Errorf("reconcile recorded operation %s, but MERGED cannot be undone", op.ID)
+		}
+	}
+
+	// MERGED is terminal, so undo passes over the merged task to the older one
+	if err := runUndo(&UndoOptions{RepoPath: repoPath, OutputJSON: true}); err != nil {
+		t.Fatalf("runUndo() failed: %v", err)
+	}
+
+	store := task.NewTaskStore(gitCommonDir)
+	if got, err := store.Load(merged.ID); err != nil || got.State != task.StateMerged {
+		t.Errorf("merged task after undo = %+v, %v, want MERGED", got, err)
+	}
+	if _, err := store.Load(older.ID); err == nil {
+		t.Error("expected undo to remove the older task")
+	}
+}
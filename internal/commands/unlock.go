@@ -7,11 +7,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/kernel-labs-ai/awt/internal/config"
 	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/kernel-labs-ai/awt/internal/git"
-	"github.com/kernel-labs-ai/awt/internal/lock"
 	"github.com/kernel-labs-ai/awt/internal/repo"
-	"github.com/kernel-labs-ai/awt/internal/task"
 	"github.com/spf13/cobra"
 )
 
@@ -70,42 +69,93 @@ Example:
 }
 
 func runTaskUnlock(opts *UnlockOptions) error {
+	result, err := cliClient(opts.OutputJSON).Unlock(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	// Nothing was checked out, so there is nothing to report
+	if len(result.WorktreesFreed) == 0 {
+		return nil
+	}
+
+	// Output result
+	if opts.OutputJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("\nUnlock completed successfully!\n")
+		fmt.Printf("  Task: %s\n", result.TaskID)
+		fmt.Printf("  Branch: %s\n", result.Branch)
+		fmt.Printf("  Worktrees freed: %d\n", len(result.WorktreesFreed))
+		if opts.Remove {
+			fmt.Printf("  Worktrees removed: %d\n", len(result.WorktreesRemoved))
+		}
+	}
+
+	return nil
+}
+
+// Unlock detaches the task's branch from every worktree that has it checked out
+func (c *Client) Unlock(ctx context.Context, opts *UnlockOptions) (*UnlockResult, error) {
 	// Discover repository
 	r, err := repo.DiscoverRepo(opts.RepoPath)
 	if err != nil {
-		return errors.RepoNotFound(opts.RepoPath)
+		return nil, errors.RepoNotFound(opts.RepoPath)
 	}
 
-	store := task.NewTaskStore(r.GitCommonDir)
+	store, err := openTaskStore(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
 
 	// Determine task ID
 	taskID := opts.TaskID
 
 	if taskID == "" && opts.Branch != "" {
-		// Extract task ID from branch name
-		taskID = extractTaskIDFromBranch(opts.Branch)
+		// Look up the task that owns the branch
+		taskID = taskIDForBranch(store, opts.Branch)
 		if taskID == "" {
-			return fmt.Errorf("could not extract task ID from branch: %s", opts.Branch)
+			return nil, fmt.Errorf("no task found for branch: %s", opts.Branch)
 		}
 	}
 
 	if taskID == "" {
-		return fmt.Errorf("task ID is required\nProvide task ID as argument or use --branch flag")
+		return nil, fmt.Errorf("task ID is required\nProvide task ID as argument or use --branch flag")
 	}
 
+	// Load config
+	configLoader := config.NewConfigLoader(r.GitCommonDir)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Hold the task lock so concurrent commands cannot mutate the task
+	taskLock, err := acquireTaskLock(ctx, r, cfg, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
+
 	// Load task
 	t, err := store.Load(taskID)
 	if err != nil {
-		return errors.InvalidTaskID(taskID)
+		return nil, errors.InvalidTaskID(taskID)
 	}
 
 	// Create Git wrapper
 	g := git.New(r.WorkTreeRoot, false)
 
 	// Find worktrees where this branch is checked out
-	worktrees, err := g.WorktreeList()
+	worktrees, err := g.WorktreeList(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list worktrees: %w", err)
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
 	var worktreesWithBranch []*git.Worktree
@@ -121,18 +171,15 @@ func runTaskUnlock(opts *UnlockOptions) error {
 	}
 
 	if len(worktreesWithBranch) == 0 {
-		if !opts.OutputJSON {
-			fmt.Printf("Branch %s is not checked out in any worktree\n", t.Branch)
-		}
-		return nil
+		c.progressf("Branch %s is not checked out in any worktree\n", t.Branch)
+		return &UnlockResult{TaskID: taskID, Branch: t.Branch}, nil
 	}
 
 	// Acquire global lock for safety
-	lm := lock.NewLockManager(r.GitCommonDir)
-	ctx := context.Background()
+	lm := newLockManager(r, cfg)
 	globalLock, err := lm.AcquireGlobal(ctx)
 	if err != nil {
-		return errors.LockTimeout("global")
+		return nil, errors.LockTimeout("global")
 	}
 	defer func() {
 		_ = globalLock.Release()
@@ -143,59 +190,38 @@ func runTaskUnlock(opts *UnlockOptions) error {
 
 	// Detach HEAD in each worktree
 	for _, wt := range worktreesWithBranch {
-		if !opts.OutputJSON {
-			fmt.Printf("Detaching HEAD in worktree: %s\n", wt.Path)
-		}
+		c.progressf("Detaching HEAD in worktree: %s\n", wt.Path)
 
 		// Create git wrapper for the worktree
 		wtGit := git.New(wt.Path, false)
-		result, err := wtGit.Switch("HEAD", true)
+		result, err := wtGit.Switch(ctx, "HEAD", true)
 		if err != nil || result.ExitCode != 0 {
-			return errors.DetachFailed(wt.Path, err)
+			return nil, errors.DetachFailed(wt.Path, err)
 		}
 
 		worktreesFreed = append(worktreesFreed, wt.Path)
 
 		// Remove worktree if requested
 		if opts.Remove {
-			if !opts.OutputJSON {
-				fmt.Printf("Removing worktree: %s\n", wt.Path)
-			}
+			c.progressf("Removing worktree: %s\n", wt.Path)
 
 			// Resolve absolute path
 			wtPathAbs, _ := filepath.Abs(wt.Path)
 
-			removeResult, err := g.WorktreeRemove(wtPathAbs, true)
+			removeResult, err := g.WorktreeRemove(ctx, wtPathAbs, true)
 			if err != nil || removeResult.ExitCode != 0 {
 				// Don't fail if removal fails - just warn
-				if !opts.OutputJSON {
-					fmt.Printf("Warning: failed to remove worktree %s: %s\n", wt.Path, removeResult.Stderr)
-				}
+				c.progressf("Warning: failed to remove worktree %s: %s\n", wt.Path, removeResult.Stderr)
 			} else {
 				worktreesRemoved = append(worktreesRemoved, wt.Path)
 			}
 		}
 	}
 
-	// Output result
-	if opts.OutputJSON {
-		output := UnlockResult{
-			TaskID:           taskID,
-			Branch:           t.Branch,
-			WorktreesFreed:   worktreesFreed,
-			WorktreesRemoved: worktreesRemoved,
-		}
-		data, _ := json.MarshalIndent(output, "", "  ")
-		fmt.Println(string(data))
-	} else {
-		fmt.Printf("\nUnlock completed successfully!\n")
-		fmt.Printf("  Task: %s\n", taskID)
-		fmt.Printf("  Branch: %s\n", t.Branch)
-		fmt.Printf("  Worktrees freed: %d\n", len(worktreesFreed))
-		if opts.Remove {
-			fmt.Printf("  Worktrees removed: %d\n", len(worktreesRemoved))
-		}
-	}
-
-	return nil
+	return &UnlockResult{
+		TaskID:           taskID,
+		Branch:           t.Branch,
+		WorktreesFreed:   worktreesFreed,
+		WorktreesRemoved: worktreesRemoved,
+	}, nil
 }
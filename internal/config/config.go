@@ -18,6 +18,10 @@ type Config struct {
 	// BranchPrefix is the prefix for AWT branches (default: awt)
 	BranchPrefix string `json:"branch_prefix,omitempty"`
 
+	// BranchTemplate is the template for task branch names (default: {prefix}/{agent}/{id})
+	// Supported placeholders: {prefix}, {agent}, {slug}, {id}
+	BranchTemplate string `json:"branch_template,omitempty"`
+
 	// WorktreeDir is the directory for worktrees (default: ~/.awt)
 	// Worktrees are stored at <WorktreeDir>/<project-id>/<task-id>
 	WorktreeDir string `json:"worktree_dir,omitempty"`
@@ -37,23 +41,63 @@ type Config struct {
 	// LockTimeout is the lock acquisition timeout in seconds (default: 30)
 	LockTimeout int `json:"lock_timeout,omitempty"`
 
+	// FetchTimeout bounds each git fetch in seconds, 0 means no limit (default: 300)
+	FetchTimeout int `json:"fetch_timeout"`
+
+	// PushTimeout bounds each git push in seconds, 0 means no limit (default: 300)
+	PushTimeout int `json:"push_timeout"`
+
+	// RebaseTimeout bounds each git rebase or merge run by sync and handoff in seconds,
+	// 0 means no limit (default: 600)
+	RebaseTimeout int `json:"rebase_timeout"`
+
 	// VerboseGit enables verbose git command output (default: false)
 	VerboseGit bool `json:"verbose_git,omitempty"`
+
+	// TaskStore is the task metadata backend: json, sqlite or git (default: json)
+	TaskStore string `json:"task_store,omitempty"`
+
+	// Forges holds per-host settings for the forge API used by handoff, keyed by
+	// the host name of the remote URL (e.g. github.com)
+	Forges map[string]ForgeConfig `json:"forges,omitempty"`
+}
+
+// ForgeConfig holds the forge API settings for one host
+type ForgeConfig struct {
+	// Kind is the forge software: github, gitlab, gitea, forgejo or bitbucket-server.
+	// When empty it is guessed from the host name (github and gitlab only).
+	Kind string `json:"kind,omitempty"`
+
+	// Token authenticates API requests; when empty it is read from the environment
+	Token string `json:"token,omitempty"`
+
+	// APIURL overrides the API base URL derived from the host
+	APIURL string `json:"api_url,omitempty"`
+}
+
+// Forge returns the forge settings for host, or zero settings if there are none
+func (c *Config) Forge(host string) ForgeConfig {
+	return c.Forges[strings.ToLower(host)]
 }
 
 // Default returns a config with default values
 func Default() *Config {
 	homeDir, _ := os.UserHomeDir()
 	return &Config{
-		DefaultAgent:  "unknown",
-		BranchPrefix:  "awt",
-		WorktreeDir:   filepath.Join(homeDir, ".awt"),
-		RebaseDefault: true,
-		AutoPush:      true,
-		AutoPR:        true,
-		RemoteName:    "origin",
-		LockTimeout:   30,
-		VerboseGit:    false,
+		DefaultAgent:   "unknown",
+		BranchPrefix:   "awt",
+		BranchTemplate: "{prefix}/{agent}/{id}",
+		WorktreeDir:    filepath.Join(homeDir, ".awt"),
+		RebaseDefault:  true,
+		AutoPush:       true,
+		AutoPR:         true,
+		RemoteName:     "origin",
+		LockTimeout:    30,
+		FetchTimeout:   300,
+		PushTimeout:    300,
+		RebaseTimeout:  600,
+		VerboseGit:     false,
+		TaskStore:      "json",
 	}
 }
 
@@ -120,6 +164,9 @@ func (cl *ConfigLoader) loadFromFile(path string, config *Config) error {
 	if partial.BranchPrefix != "" {
 		config.BranchPrefix = partial.BranchPrefix
 	}
+	if partial.BranchTemplate != "" {
+		config.BranchTemplate = partial.BranchTemplate
+	}
 	if partial.WorktreeDir != "" {
 		config.WorktreeDir = partial.WorktreeDir
 	}
@@ -129,6 +176,26 @@ func (cl *ConfigLoader) loadFromFile(path string, config *Config) error {
 	if partial.LockTimeout > 0 {
 		config.LockTimeout = partial.LockTimeout
 	}
+	if partial.TaskStore != "" {
+		config.TaskStore = partial.TaskStore
+	}
+	for host, forge := range partial.Forges {
+		host = strings.ToLower(host)
+		merged := config.Forges[host]
+		if forge.Kind != "" {
+			merged.Kind = forge.Kind
+		}
+		if forge.Token != "" {
+			merged.Token = forge.Token
+		}
+		if forge.APIURL != "" {
+			merged.APIURL = forge.APIURL
+		}
+		if config.Forges == nil {
+			config.Forges = make(map[string]ForgeConfig)
+		}
+		config.Forges[host] = merged
+	}
 
 	// For booleans, we need to check if they were explicitly set
 	// This is tricky with JSON unmarshalling, so we use a workaround
@@ -146,6 +213,17 @@ func (cl *ConfigLoader) loadFromFile(path string, config *Config) error {
 		config.VerboseGit = partial.VerboseGit
 	}
 
+	// The git timeouts use 0 for no limit, so an explicit 0 overrides as well
+	if strings.Contains(string(data), "\"fetch_timeout\"") && partial.FetchTimeout >= 0 {
+		config.FetchTimeout = partial.FetchTimeout
+	}
+	if strings.Contains(string(data), "\"push_timeout\"") && partial.PushTimeout >= 0 {
+		config.PushTimeout = partial.PushTimeout
+	}
+	if strings.Contains(string(data), "\"rebase_timeout\"") && partial.RebaseTimeout >= 0 {
+		config.RebaseTimeout = partial.RebaseTimeout
+	}
+
 	return nil
 }
 
@@ -157,6 +235,9 @@ func (cl *ConfigLoader) loadFromEnv(config *Config) {
 	if val := os.Getenv("AWT_BRANCH_PREFIX"); val != "" {
 		config.BranchPrefix = val
 	}
+	if val := os.Getenv("AWT_BRANCH_TEMPLATE"); val != "" {
+		config.BranchTemplate = val
+	}
 	if val := os.Getenv("AWT_WORKTREE_DIR"); val != "" {
 		config.WorktreeDir = val
 	}
@@ -168,6 +249,21 @@ func (cl *ConfigLoader) loadFromEnv(config *Config) {
 			config.LockTimeout = timeout
 		}
 	}
+	if val := os.Getenv("AWT_FETCH_TIMEOUT"); val != "" {
+		if timeout, err := strconv.Atoi(val); err == nil && timeout >= 0 {
+			config.FetchTimeout = timeout
+		}
+	}
+	if val := os.Getenv("AWT_PUSH_TIMEOUT"); val != "" {
+		if timeout, err := strconv.Atoi(val); err == nil && timeout >= 0 {
+			config.PushTimeout = timeout
+		}
+	}
+	if val := os.Getenv("AWT_REBASE_TIMEOUT"); val != "" {
+		if timeout, err := strconv.Atoi(val); err == nil && timeout >= 0 {
+			config.RebaseTimeout = timeout
+		}
+	}
 	if val := os.Getenv("AWT_REBASE_DEFAULT"); val != "" {
 		config.RebaseDefault = parseBool(val)
 	}
@@ -180,6 +276,9 @@ func (cl *ConfigLoader) loadFromEnv(config *Config) {
 	if val := os.Getenv("AWT_VERBOSE_GIT"); val != "" {
 		config.VerboseGit = parseBool(val)
 	}
+	if val := os.Getenv("AWT_TASK_STORE"); val != "" {
+		config.TaskStore = val
+	}
 }
 
 // parseBool parses a boolean from a string (supports 1/0, true/false, yes/no)
@@ -233,6 +332,22 @@ func (cl *ConfigLoader) Save(config *Config, scope string) error {
 	return nil
 }
 
+// PRTemplateFile is the name of the pull request body template, which is looked up
+// next to the repo and user config files
+const PRTemplateFile = "pr_template.md"
+
+// PRTemplatePath returns the pull request body template to use: the repo template
+// if it exists, else the user template, else ""
+func (cl *ConfigLoader) PRTemplatePath() string {
+	for _, configPath := range []string{cl.repoPath, cl.userPath} {
+		path := filepath.Join(filepath.Dir(configPath), PRTemplateFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
 // GetConfigPath returns the path for a given scope
 func (cl *ConfigLoader) GetConfigPath(scope string) (string, error) {
 	switch scope {
@@ -16,6 +16,9 @@ func TestDefault(t *testing.T) {
 	if cfg.BranchPrefix != "awt" {
 		t.Errorf("BranchPrefix = %q, want %q", cfg.BranchPrefix, "awt")
 	}
+	if cfg.BranchTemplate != "{prefix}/{agent}/{id}" {
+		t.Errorf("BranchTemplate = %q, want %q", cfg.BranchTemplate, "{prefix}/{agent}/{id}")
+	}
 	// WorktreeDir should default to ~/.awt
 	homeDir, _ := os.UserHomeDir()
 	expectedWorktreeDir := filepath.Join(homeDir, ".awt")
@@ -37,6 +40,9 @@ func TestDefault(t *testing.T) {
 	if cfg.LockTimeout != 30 {
 		t.Errorf("LockTimeout = %d, want %d", cfg.LockTimeout, 30)
 	}
+	if cfg.FetchTimeout != 300 || cfg.PushTimeout != 300 || cfg.RebaseTimeout != 600 {
+		t.Errorf("git timeouts = %d/%d/%d, want 300/300/600", cfg.FetchTimeout, cfg.PushTimeout, cfg.RebaseTimeout)
+	}
 	if cfg.VerboseGit {
 		t.Error("VerboseGit should be false by default")
 	}
@@ -48,9 +54,11 @@ func TestConfigLoader_LoadFromEnv(t *testing.T) {
 	envVars := []string{
 		"AWT_DEFAULT_AGENT",
 		"AWT_BRANCH_PREFIX",
+		"AWT_BRANCH_TEMPLATE",
 		"AWT_WORKTREE_DIR",
 		"AWT_REMOTE_NAME",
 		"AWT_LOCK_TIMEOUT",
+		"AWT_FETCH_TIMEOUT",
 		"AWT_REBASE_DEFAULT",
 		"AWT_AUTO_PUSH",
 		"AWT_AUTO_PR",
@@ -74,9 +82,11 @@ func TestConfigLoader_LoadFromEnv(t *testing.T) {
 	// Set test env vars
 	_ = os.Setenv("AWT_DEFAULT_AGENT", "test-agent")
 	_ = os.Setenv("AWT_BRANCH_PREFIX", "test")
+	_ = os.Setenv("AWT_BRANCH_TEMPLATE", "{prefix}/{slug}-{id}")
 	_ = os.Setenv("AWT_WORKTREE_DIR", "/custom/worktrees")
 	_ = os.Setenv("AWT_REMOTE_NAME", "upstream")
 	_ = os.Setenv("AWT_LOCK_TIMEOUT", "60")
+	_ = os.Setenv("AWT_FETCH_TIMEOUT", "15")
 	_ = os.Setenv("AWT_REBASE_DEFAULT", "false")
 	_ = os.Setenv("AWT_AUTO_PUSH", "no")
 	_ = os.Setenv("AWT_AUTO_PR", "0")
@@ -104,6 +114,9 @@ func TestConfigLoader_LoadFromEnv(t *testing.T) {
 	if cfg.BranchPrefix != "test" {
 		t.Errorf("BranchPrefix = %q, want %q", cfg.BranchPrefix, "test")
 	}
+	if cfg.BranchTemplate != "{prefix}/{slug}-{id}" {
+		t.Errorf("BranchTemplate = %q, want %q", cfg.BranchTemplate, "{prefix}/{slug}-{id}")
+	}
 	if cfg.WorktreeDir != "/custom/worktrees" {
 		t.Errorf("WorktreeDir = %q, want %q", cfg.WorktreeDir, "/custom/worktrees")
 	}
@@ -113,6 +126,9 @@ func TestConfigLoader_LoadFromEnv(t *testing.T) {
 	if cfg.LockTimeout != 60 {
 		t.Errorf("LockTimeout = %d, want %d", cfg.LockTimeout, 60)
 	}
+	if cfg.FetchTimeout != 15 {
+		t.Errorf("FetchTimeout = %d, want %d", cfg.FetchTimeout, 15)
+	}
 	if cfg.RebaseDefault {
 		t.Error("RebaseDefault should be false")
 	}
@@ -183,6 +199,117 @@ func TestConfigLoader_SaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestConfigLoader_MergesForges(t *testing.T) {
+	tempDir := t.TempDir()
+	loader := &ConfigLoader{
+		systemPath: filepath.Join(tempDir, "system.json"),
+		userPath:   filepath.Join(tempDir, "user.json"),
+		repoPath:   filepath.Join(tempDir, "repo.json"),
+	}
+
+	user := `{"forges": {"GitHub.com": {"token": "user-token"}, "git.example.com": {"kind": "gitea", "token": "example-token"}}}`
+	repo := `{"forges": {"github.com": {"api_url": "http://localhost:8080"}, "git.example.com": {"kind": "forgejo"}}}`
+	if err := os.WriteFile(loader.userPath, []byte(user), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(loader.repoPath, []byte(repo), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	github := cfg.Forge("github.com")
+	if github.Token != "user-token" || github.APIURL != "http://localhost:8080" {
+		t.Errorf("Forge(github.com) = %+v, want the user token and the repo API URL", github)
+	}
+	if got := cfg.Forge("GIT.example.com"); got.Token != "example-token" || got.Kind != "forgejo" {
+		t.Errorf("Forge(GIT.example.com) = %+v, want the user token and the repo kind", got)
+	}
+	if got := cfg.Forge("gitlab.com"); got != (ForgeConfig{}) {
+		t.Errorf("Forge(gitlab.com) = %+v, want zero settings", got)
+	}
+}
+
+func TestConfigLoader_ZeroTimeouts(t *testing.T) {
+	tempDir := t.TempDir()
+	loader := &ConfigLoader{
+		systemPath: filepath.Join(tempDir, "system.json"),
+		userPath:   filepath.Join(tempDir, "user.json"),
+		repoPath:   filepath.Join(tempDir, "repo.json"),
+	}
+
+	user := `{"fetch_timeout": 120, "push_timeout": 0}`
+	repo := `{"fetch_timeout": 0}`
+	if err := os.WriteFile(loader.userPath, []byte(user), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(loader.repoPath, []byte(repo), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// An explicit 0 means no limit and overrides both the default and lower layers
+	if cfg.FetchTimeout != 0 {
+		t.Errorf("FetchTimeout = %d, want 0", cfg.FetchTimeout)
+	}
+	if cfg.PushTimeout != 0 {
+		t.Errorf("PushTimeout = %d, want 0", cfg.PushTimeout)
+	}
+	if cfg.RebaseTimeout != 600 {
+		t.Errorf("RebaseTimeout = %d, want the default 600", cfg.RebaseTimeout)
+	}
+
+	t.Setenv("AWT_REBASE_TIMEOUT", "0")
+	cfg, err = loader.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.RebaseTimeout != 0 {
+		t.Errorf("RebaseTimeout with AWT_REBASE_TIMEOUT=0 = %d, want 0", cfg.RebaseTimeout)
+	}
+}
+
+func TestConfigLoader_PRTemplatePath(t *testing.T) {
+	tempDir := t.TempDir()
+	loader := &ConfigLoader{
+		userPath: filepath.Join(tempDir, "user", "config.json"),
+		repoPath: filepath.Join(tempDir, "repo", "config.json"),
+	}
+
+	if got := loader.PRTemplatePath(); got != "" {
+		t.Errorf("PRTemplatePath() with no templates = %q, want empty", got)
+	}
+
+	userTemplate := filepath.Join(tempDir, "user", PRTemplateFile)
+	if err := os.MkdirAll(filepath.Dir(userTemplate), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userTemplate, []byte("user"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := loader.PRTemplatePath(); got != userTemplate {
+		t.Errorf("PRTemplatePath() = %q, want the user template %q", got, userTemplate)
+	}
+
+	repoTemplate := filepath.Join(tempDir, "repo", PRTemplateFile)
+	if err := os.MkdirAll(filepath.Dir(repoTemplate), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(repoTemplate, []byte("repo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := loader.PRTemplatePath(); got != repoTemplate {
+		t.Errorf("PRTemplatePath() = %q, want the repo template %q", got, repoTemplate)
+	}
+}
+
 func TestConfigLoader_GetConfigPath(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "awt-config-test")
 	if err != nil {
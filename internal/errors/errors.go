@@ -2,8 +2,12 @@ package errors
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 )
 
 // ExitCode represents an AWT error exit code
@@ -12,6 +16,10 @@ type ExitCode int
 const (
 	// ExitSuccess is the success exit code
 	ExitSuccess ExitCode = 0
+	// ExitGeneric is used for errors that have no specific code
+	ExitGeneric ExitCode = 1
+	// ExitUsage is used for invalid flags or arguments
+	ExitUsage ExitCode = 2
 
 	// Repository errors (10-19)
 	ExitRepoNotFound ExitCode = 10
@@ -24,10 +32,14 @@ const (
 	ExitWorktreeNotFound         ExitCode = 23
 	ExitDetachFailed             ExitCode = 24
 	ExitRemoveFailed             ExitCode = 25
+	ExitWorktreeAddFailed        ExitCode = 26
+	ExitSubmoduleFailed          ExitCode = 27
 
 	// Sync/push errors (30-39)
 	ExitSyncConflicts ExitCode = 30
 	ExitPushRejected  ExitCode = 31
+	ExitSyncFailed    ExitCode = 32
+	ExitGitTimeout    ExitCode = 33
 
 	// Lock errors (40-49)
 	ExitLockTimeout ExitCode = 40
@@ -39,6 +51,17 @@ const (
 	// Task errors (60-69)
 	ExitInvalidTaskID      ExitCode = 60
 	ExitCaseOnlyCollision  ExitCode = 61
+	ExitInvalidTransition  ExitCode = 62
+	ExitInvalidTaskState   ExitCode = 63
+	ExitTaskConflict       ExitCode = 64
+
+	// Undo errors (70-79)
+	ExitUndoRefused ExitCode = 70
+
+	// Commit errors (80-89)
+	ExitNothingToCommit      ExitCode = 80
+	ExitCommitFailed         ExitCode = 81
+	ExitInvalidCommitMessage ExitCode = 82
 )
 
 // AWTError represents an AWT-specific error with an exit code and hint
@@ -66,7 +89,7 @@ func (e *AWTError) Unwrap() error {
 type JSONError struct {
 	Error string   `json:"error"`
 	Code  ExitCode `json:"code"`
-	Hint  string   `json:"hint,omitempty"`
+	Hint  string   `json:"hint"`
 }
 
 // ToJSON returns the JSON representation of the error
@@ -96,28 +119,73 @@ func Handle(err error, useJSON bool) {
 		return
 	}
 
-	// Check if it's an AWTError
-	if awtErr, ok := err.(*AWTError); ok {
+	Print(os.Stderr, err, useJSON)
+	os.Exit(int(ExitCodeOf(err)))
+}
+
+// Print writes an error to w, as a JSON object ({error, code, hint}) if useJSON is set
+func Print(w io.Writer, err error, useJSON bool) {
+	if err == nil {
+		return
+	}
+
+	// Check if it's an AWTError, possibly wrapped with more context
+	var awtErr *AWTError
+	if !errors.As(err, &awtErr) {
+		// Generic error
 		if useJSON {
-			fmt.Fprintln(os.Stderr, awtErr.ToJSON())
+			je := JSONError{
+				Error: err.Error(),
+				Code:  ExitGeneric,
+			}
+			data, _ := json.MarshalIndent(je, "", "  ")
+			_, _ = fmt.Fprintln(w, string(data))
 		} else {
-			fmt.Fprintln(os.Stderr, awtErr.Error())
+			_, _ = fmt.Fprintf(w, "Error: %v\n", err)
 		}
-		os.Exit(int(awtErr.Code))
+		return
 	}
 
-	// Generic error
-	if useJSON {
-		je := JSONError{
-			Error: err.Error(),
-			Code:  1,
-		}
-		data, _ := json.MarshalIndent(je, "", "  ")
-		fmt.Fprintln(os.Stderr, string(data))
-	} else {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if !useJSON {
+		_, _ = fmt.Fprintln(w, err.Error())
+		return
+	}
+
+	// Keep the context added by wrapping, but report the hint separately
+	message := awtErr.Message
+	if err != error(awtErr) {
+		message = strings.Replace(err.Error(), awtErr.Error(), awtErr.Message, 1)
 	}
-	os.Exit(1)
+	je := JSONError{
+		Error: message,
+		Code:  awtErr.Code,
+		Hint:  awtErr.Hint,
+	}
+	data, _ := json.MarshalIndent(je, "", "  ")
+	_, _ = fmt.Fprintln(w, string(data))
+}
+
+// ExitCodeOf returns the exit code for an error: the code of the first AWTError in its
+// chain, ExitGeneric for other errors and ExitSuccess for nil
+func ExitCodeOf(err error) ExitCode {
+	if err == nil {
+		return ExitSuccess
+	}
+	var awtErr *AWTError
+	if errors.As(err, &awtErr) {
+		return awtErr.Code
+	}
+	return ExitGeneric
+}
+
+// Usage creates a USAGE error for invalid flags or arguments
+func Usage(cause error, commandPath string) *AWTError {
+	return New(
+		ExitUsage,
+		cause.Error(),
+		fmt.Sprintf("Run '%s --help' for usage.", commandPath),
+		cause,
+	)
 }
 
 // Predefined error constructors for common cases
@@ -222,6 +290,17 @@ func PushRejected(branch string, cause error) *AWTError {
 	)
 }
 
+// GitTimeout creates a GIT_TIMEOUT error for a git command that was stopped when
+// the timeout from the given setting ran out
+func GitTimeout(command, setting string, timeout time.Duration) *AWTError {
+	return New(
+		ExitGitTimeout,
+		fmt.Sprintf("Timed out running git %s after %s", command, timeout),
+		fmt.Sprintf("The git process was stopped. Check access to the remote, or raise the limit with 'awt config set %s <seconds>'.", setting),
+		nil,
+	)
+}
+
 // LockTimeout creates a LOCK_TIMEOUT error
 func LockTimeout(lockName string) *AWTError {
 	return New(
@@ -232,12 +311,16 @@ func LockTimeout(lockName string) *AWTError {
 	)
 }
 
-// LockHeld creates a LOCK_HELD error
-func LockHeld(lockName string) *AWTError {
+// LockHeld creates a LOCK_HELD error, naming the current holder when known
+func LockHeld(lockName, holder string) *AWTError {
+	message := fmt.Sprintf("Lock is held: %s", lockName)
+	if holder != "" {
+		message = fmt.Sprintf("Lock is held: %s (by %s)", lockName, holder)
+	}
 	return New(
 		ExitLockHeld,
-		fmt.Sprintf("Lock is held: %s", lockName),
-		"Another AWT operation is currently using this lock.",
+		message,
+		"Another AWT operation is currently using this lock. Wait for it to complete, or increase lock_timeout.",
 		nil,
 	)
 }
@@ -271,3 +354,150 @@ func CaseOnlyCollision(branch1, branch2 string) *AWTError {
 		nil,
 	)
 }
+
+// InvalidTransition creates an INVALID_TRANSITION error
+func InvalidTransition(taskID, from, to string) *AWTError {
+	return New(
+		ExitInvalidTransition,
+		fmt.Sprintf("Invalid state transition for task %s: %s -> %s", taskID, from, to),
+		"Use 'awt task status' to see the task's current state and history.",
+		nil,
+	)
+}
+
+// InvalidTaskState creates an INVALID_TASK_STATE error
+func InvalidTaskState(taskID, state, operation string) *AWTError {
+	return New(
+		ExitInvalidTaskState,
+		fmt.Sprintf("Cannot %s task %s in state %s", operation, taskID, state),
+		"Use 'awt task status' to see the task's current state and history.",
+		nil,
+	)
+}
+
+// TaskConflict creates a TASK_CONFLICT error for a save whose expected revision
+// no longer matches the stored record
+func TaskConflict(taskID string, expected, actual int64) *AWTError {
+	return New(
+		ExitTaskConflict,
+		fmt.Sprintf("Task %s was modified concurrently (expected revision %d, found %d)", taskID, expected, actual),
+		"Another command updated the task; reload it and retry.",
+		nil,
+	)
+}
+
+// UndoRefused creates an UNDO_REFUSED error for an operation that cannot be undone as asked
+func UndoRefused(opID, reason, hint string) *AWTError {
+	return New(
+		ExitUndoRefused,
+		fmt.Sprintf("Cannot undo operation %s: %s", opID, reason),
+		hint,
+		nil,
+	)
+}
+
+// WorktreeAddFailed creates a WORKTREE_ADD_FAILED error
+func WorktreeAddFailed(path, stderr string) *AWTError {
+	return New(
+		ExitWorktreeAddFailed,
+		fmt.Sprintf("Failed to create worktree at %s: %s", path, stderr),
+		"Check that the path is free and the branch is not checked out elsewhere, then run 'git worktree prune' if a stale worktree is registered.",
+		nil,
+	)
+}
+
+// SubmoduleFailed creates a SUBMODULE_FAILED error
+func SubmoduleFailed(worktree, stderr string) *AWTError {
+	return New(
+		ExitSubmoduleFailed,
+		fmt.Sprintf("Failed to update submodules in %s: %s", worktree, stderr),
+		"Check submodule URLs and access, then run 'git submodule update --init --recursive' in the worktree.",
+		nil,
+	)
+}
+
+// SyncFailed creates a SYNC_FAILED error for a sync that failed without conflicts
+func SyncFailed(branch, step, stderr string) *AWTError {
+	return New(
+		ExitSyncFailed,
+		fmt.Sprintf("Failed to %s branch %s: %s", step, branch, stderr),
+		"Check the worktree with 'git status'; abort any half-finished rebase or merge before retrying.",
+		nil,
+	)
+}
+
+// TaskNotFoundForBranch creates an INVALID_TASK_ID error for a branch no task owns
+func TaskNotFoundForBranch(branch string) *AWTError {
+	return New(
+		ExitInvalidTaskID,
+		fmt.Sprintf("No task found for branch: %s", branch),
+		"Use 'awt list' to see task branches, or 'awt task adopt' to create a task for the branch.",
+		nil,
+	)
+}
+
+// TaskIDRequired creates an INVALID_TASK_ID error for a command that could not tell which task to act on
+func TaskIDRequired(cause error) *AWTError {
+	if cause == nil {
+		return New(
+			ExitInvalidTaskID,
+			"Task ID is required",
+			"Provide the task ID as an argument or use --branch.",
+			nil,
+		)
+	}
+	return New(
+		ExitInvalidTaskID,
+		fmt.Sprintf("Could not infer task ID: %v", cause),
+		"Provide the task ID as an argument, use --branch, or run the command from inside the task's worktree.",
+		cause,
+	)
+}
+
+// NothingToCommit creates a NOTHING_TO_COMMIT error
+func NothingToCommit(staged bool) *AWTError {
+	if staged {
+		return New(
+			ExitNothingToCommit,
+			"No changes added to commit",
+			"Use --all to stage all modified files, or stage files manually.",
+			nil,
+		)
+	}
+	return New(
+		ExitNothingToCommit,
+		"Nothing to commit, working tree clean",
+		"Make changes in the task's worktree before committing.",
+		nil,
+	)
+}
+
+// CommitFailed creates a COMMIT_FAILED error
+func CommitFailed(step, stderr string) *AWTError {
+	return New(
+		ExitCommitFailed,
+		fmt.Sprintf("Failed to %s: %s", step, stderr),
+		"Check the worktree with 'git status' and any commit hooks that may have rejected the commit.",
+		nil,
+	)
+}
+
+// InvalidCommitMessage creates an INVALID_COMMIT_MESSAGE error
+func InvalidCommitMessage(cause error) *AWTError {
+	return New(
+		ExitInvalidCommitMessage,
+		fmt.Sprintf("Invalid commit message: %v", cause),
+		"Pass a non-empty message with -m; keep the subject line within 100 characters.",
+		cause,
+	)
+}
+
+// NoPullRequest creates an INVALID_TASK_STATE error for a task without a pull request
+func NoPullRequest(taskID string) *AWTError {
+	return New(
+		ExitInvalidTaskState,
+		fmt.Sprintf("Task %s has no pull request", taskID),
+		"Use 'awt task handoff' to push the task and open its pull request through the forge API.",
+		nil,
+	)
+}
@@ -1,10 +1,13 @@
 package errors
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAWTErrorBasic(t *testing.T) {
@@ -77,11 +80,26 @@ func TestPredefinedErrors(t *testing.T) {
 		{"RemoveFailed", RemoveFailed("/tmp/wt", nil), ExitRemoveFailed},
 		{"SyncConflicts", SyncConflicts("feature"), ExitSyncConflicts},
 		{"PushRejected", PushRejected("feature", nil), ExitPushRejected},
+		{"GitTimeout", GitTimeout("fetch", "fetch_timeout", 5*time.Minute), ExitGitTimeout},
 		{"LockTimeout", LockTimeout("global"), ExitLockTimeout},
-		{"LockHeld", LockHeld("global"), ExitLockHeld},
+		{"LockHeld", LockHeld("global", "pid 1234"), ExitLockHeld},
 		{"ToolMissing", ToolMissing("gh"), ExitToolMissing},
 		{"InvalidTaskID", InvalidTaskID("bad-id"), ExitInvalidTaskID},
 		{"CaseOnlyCollision", CaseOnlyCollision("Feature", "feature"), ExitCaseOnlyCollision},
+		{"InvalidTransition", InvalidTransition("task-1", "MERGED", "ACTIVE"), ExitInvalidTransition},
+		{"InvalidTaskState", InvalidTaskState("task-1", "ABANDONED", "commit"), ExitInvalidTaskState},
+		{"TaskConflict", TaskConflict("task-1", 2, 3), ExitTaskConflict},
+		{"UndoRefused", UndoRefused("op-1", "it was already undone", "Redo it instead."), ExitUndoRefused},
+		{"Usage", Usage(errors.New("unknown flag: --bogus"), "awt list"), ExitUsage},
+		{"WorktreeAddFailed", WorktreeAddFailed("/tmp/wt", "fatal: already exists"), ExitWorktreeAddFailed},
+		{"SubmoduleFailed", SubmoduleFailed("/tmp/wt", "fatal: clone failed"), ExitSubmoduleFailed},
+		{"SyncFailed", SyncFailed("feature", "rebase", "fatal: bad revision"), ExitSyncFailed},
+		{"TaskNotFoundForBranch", TaskNotFoundForBranch("feature"), ExitInvalidTaskID},
+		{"TaskIDRequired", TaskIDRequired(nil), ExitInvalidTaskID},
+		{"NothingToCommit", NothingToCommit(false), ExitNothingToCommit},
+		{"CommitFailed", CommitFailed("commit", "hook failed"), ExitCommitFailed},
+		{"InvalidCommitMessage", InvalidCommitMessage(errors.New("empty")), ExitInvalidCommitMessage},
+		{"NoPullRequest", NoPullRequest("task-1"), ExitInvalidTaskState},
 	}
 
 	for _, tt := range tests {
@@ -117,6 +135,19 @@ func TestExitCodes(t *testing.T) {
 		ExitToolMissing:               "ExitToolMissing",
 		ExitInvalidTaskID:             "ExitInvalidTaskID",
 		ExitCaseOnlyCollision:         "ExitCaseOnlyCollision",
+		ExitInvalidTransition:         "ExitInvalidTransition",
+		ExitInvalidTaskState:          "ExitInvalidTaskState",
+		ExitTaskConflict:              "ExitTaskConflict",
+		ExitUndoRefused:               "ExitUndoRefused",
+		ExitGeneric:                   "ExitGeneric",
+		ExitUsage:                     "ExitUsage",
+		ExitWorktreeAddFailed:         "ExitWorktreeAddFailed",
+		ExitSubmoduleFailed:           "ExitSubmoduleFailed",
+		ExitSyncFailed:                "ExitSyncFailed",
+		ExitGitTimeout:                "ExitGitTimeout",
+		ExitNothingToCommit:           "ExitNothingToCommit",
+		ExitCommitFailed:              "ExitCommitFailed",
+		ExitInvalidCommitMessage:      "ExitInvalidCommitMessage",
 	}
 
 	seen := make(map[ExitCode]bool)
@@ -139,3 +170,46 @@ func TestErrorWithoutHint(t *testing.T) {
 		t.Error("Error() should contain the message")
 	}
 }
+
+func TestPrintJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantErr  string
+		wantCode ExitCode
+		wantHint string
+	}{
+		{"AWTError", LockTimeout("global"), "Timeout waiting for lock: global", ExitLockTimeout, LockTimeout("global").Hint},
+		{"wrapped AWTError", fmt.Errorf("failed to update task metadata: %w", TaskConflict("t1", 1, 2)), "failed to update task metadata: Task t1 was modified concurrently (expected revision 1, found 2)", ExitTaskConflict, TaskConflict("t1", 1, 2).Hint},
+		{"generic error", errors.New("boom"), "boom", ExitGeneric, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			Print(&buf, tt.err, true)
+
+			// hint is always present, even when empty
+			var raw map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+				t.Fatalf("failed to parse JSON %q: %v", buf.String(), err)
+			}
+			if _, ok := raw["hint"]; !ok {
+				t.Errorf("JSON has no hint field: %s", buf.String())
+			}
+
+			var je JSONError
+			_ = json.Unmarshal(buf.Bytes(), &je)
+			if je.Error != tt.wantErr || je.Code != tt.wantCode || je.Hint != tt.wantHint {
+				t.Errorf("got %+v, want {%q %d %q}", je, tt.wantErr, tt.wantCode, tt.wantHint)
+			}
+			if got := ExitCodeOf(tt.err); got != tt.wantCode {
+				t.Errorf("ExitCodeOf() = %d, want %d", got, tt.wantCode)
+			}
+		})
+	}
+
+	if got := ExitCodeOf(nil); got != ExitSuccess {
+		t.Errorf("ExitCodeOf(nil) = %d, want %d", got, ExitSuccess)
+	}
+}
@@ -0,0 +1,405 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bitbucketServer implements Forge with the Bitbucket Server (Data Center) REST API
+type bitbucketServer struct {
+	client
+	projectKey string
+	repoSlug   string
+	// repoPath is /rest/api/1.0/projects/<key>/repos/<slug>
+	repoPath string
+}
+
+func newBitbucketServer(opts Options) *bitbucketServer {
+	baseURL := opts.APIURL
+	if baseURL == "" {
+		baseURL = opts.webURL()
+	}
+
+	token := opts.Token
+	key := bitbucketProjectKey(opts.Owner)
+	return &bitbucketServer{
+		client: client{
+			baseURL: strings.TrimSuffix(baseURL, "/"),
+			http:    opts.HTTPClient,
+			auth: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+token)
+			},
+			nextPage: bitbucketNextPage,
+		},
+		projectKey: key,
+		repoSlug:   opts.Repo,
+		repoPath:   fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s", url.PathEscape(key), url.PathEscape(opts.Repo)),
+	}
+}
+
+// bitbucketProjectKey returns the project key for a remote URL's owner. Project
+// keys are upper case; personal projects (~user) keep the user slug as is.
+func bitbucketProjectKey(owner string) string {
+	if strings.HasPrefix(owner, "~") {
+		return owner
+	}
+	return strings.ToUpper(owner)
+}
+
+// Kind implements Forge
+func (bb *bitbucketServer) Kind() Kind {
+	return KindBitbucketServer
+}
+
+// bitbucketUser is a user as embedded in API objects
+type bitbucketUser struct {
+	Name string `json:"name"`
+}
+
+// bitbucketRef is a branch reference in a pull request
+type bitbucketRef struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
+
+// bitbucketPR is a pull request as returned by the API
+type bitbucketPR struct {
+	ID      int          `json:"id"`
+	Version int          `json:"version"`
+	Title   string       `json:"title"`
+	State   string       `json:"state"`
+	Draft   bool         `json:"draft"`
+	FromRef bitbucketRef `json:"fromRef"`
+	ToRef   bitbucketRef `json:"toRef"`
+	// Reviewers carries each reviewer's status: APPROVED, NEEDS_WORK or UNAPPROVED
+	Reviewers []struct {
+		User   bitbucketUser `json:"user"`
+		Status string        `json:"status"`
+	} `json:"reviewers"`
+	Links struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+func (p *bitbucketPR) toPR() *PR {
+	state := PRStateOpen
+	switch p.State {
+	case "MERGED":
+		state = PRStateMerged
+	case "DECLINED":
+		state = PRStateClosed
+	}
+
+	pr := &PR{
+		Number:  p.ID,
+		Title:   p.Title,
+		State:   state,
+		Draft:   p.Draft,
+		Head:    p.FromRef.DisplayID,
+		Base:    p.ToRef.DisplayID,
+		HeadSHA: p.FromRef.LatestCommit,
+	}
+	if len(p.Links.Self) > 0 {
+		pr.URL = p.Links.Self[0].Href
+	}
+	return pr
+}
+
+// ref returns a pull request ref for branch in this repository
+func (bb *bitbucketServer) ref(branch string) map[string]interface{} {
+	return map[string]interface{}{
+		"id": "refs/heads/" + branch,
+		"repository": map[string]interface{}{
+			"slug":    bb.repoSlug,
+			"project": map[string]interface{}{"key": bb.projectKey},
+		},
+	}
+}
+
+// CreatePR implements Forge
+func (bb *bitbucketServer) CreatePR(ctx context.Context, req *PRRequest) (*PR, error) {
+	in := map[string]interface{}{
+		"title":       req.Title,
+		"description": req.Body,
+		"fromRef":     bb.ref(req.Head),
+		"toRef":       bb.ref(req.Base),
+	}
+	if req.Draft {
+		// Only sent when needed, servers before 8.18 have no drafts
+		in["draft"] = true
+	}
+	var out bitbucketPR
+	if err := bb.do(ctx, http.MethodPost, bb.repoPath+"/pull-requests", in, &out); err != nil {
+		return nil, err
+	}
+	return out.toPR(), nil
+}
+
+// getPR returns the pull request as returned by the API
+func (bb *bitbucketServer) getPR(ctx context.Context, number int) (*bitbucketPR, error) {
+	var out bitbucketPR
+	if err := bb.do(ctx, http.MethodGet, fmt.Sprintf("%s/pull-requests/%d", bb.repoPath, number), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetPR implements Forge. The merge status is fetched separately for open pull requests.
+func (bb *bitbucketServer) GetPR(ctx context.Context, number int) (*PR, error) {
+	out, err := bb.getPR(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	pr := out.toPR()
+
+	if pr.State == PRStateOpen {
+		var status struct {
+			Conflicted bool `json:"conflicted"`
+		}
+		if err := bb.do(ctx, http.MethodGet, fmt.Sprintf("%s/pull-requests/%d/merge", bb.repoPath, number), nil, &status); err != nil {
+			return nil, err
+		}
+		mergeable := !status.Conflicted
+		pr.Mergeable = &mergeable
+	}
+	return pr, nil
+}
+
+// fullSHAPattern matches a full commit SHA, which the build status API requires
+var fullSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// ListChecks implements Forge using the build status API. Branch names and
+// abbreviated SHAs are resolved to a commit first.
+func (bb *bitbucketServer) ListChecks(ctx context.Context, ref string) ([]Check, error) {
+	if !fullSHAPattern.MatchString(ref) {
+		// Listing with until avoids encoded slashes in the path, which servers often reject
+		var commits struct {
+			Values []struct {
+				ID string `json:"id"`
+			} `json:"values"`
+		}
+		if err := bb.do(ctx, http.MethodGet, fmt.Sprintf("%s/commits?until=%s&limit=1", bb.repoPath, url.QueryEscape(ref)), nil, &commits); err != nil {
+			return nil, err
+		}
+		if len(commits.Values) == 0 {
+			return nil, fmt.Errorf("no commit found for %s", ref)
+		}
+		ref = commits.Values[0].ID
+	}
+
+	statuses, err := listValues[struct {
+		State string `json:"state"`
+		Key   string `json:"key"`
+		Name  string `json:"name"`
+		URL   string `json:"url"`
+	}](ctx, &bb.client, fmt.Sprintf("/rest/build-status/1.0/commits/%s?limit=%d", ref, pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]Check, 0, len(statuses))
+	for _, s := range statuses {
+		name := s.Name
+		if name == "" {
+			name = s.Key
+		}
+		checks = append(checks, Check{
+			Name:  name,
+			State: bitbucketBuildState(s.State),
+			URL:   s.URL,
+		})
+	}
+	return checks, nil
+}
+
+// bitbucketBuildState maps a build state to a CheckState
+func bitbucketBuildState(state string) CheckState {
+	switch state {
+	case "SUCCESSFUL":
+		return CheckSuccess
+	case "FAILED":
+		return CheckFailure
+	case "INPROGRESS":
+		return CheckRunning
+	case "CANCELLED":
+		return CheckCancelled
+	default:
+		return CheckPending
+	}
+}
+
+// bitbucketStrategies maps merge methods to Bitbucket merge strategy IDs
+var bitbucketStrategies = map[string]string{
+	MergeMethodMerge:  "no-ff",
+	MergeMethodSquash: "squash",
+	MergeMethodRebase: "rebase-no-ff",
+}
+
+// Merge implements Forge. Bitbucket merges a specific version of the pull request,
+// so it is fetched first. Without a method the repository's default strategy is used.
+func (bb *bitbucketServer) Merge(ctx context.Context, number int, opts *MergeOptions) error {
+	pr, err := bb.getPR(ctx, number)
+	if err != nil {
+		return err
+	}
+
+	in := map[string]interface{}{}
+	if opts != nil {
+		if opts.SHA != "" && opts.SHA != pr.FromRef.LatestCommit {
+			return fmt.Errorf("pull request %d head is %s, not %s", number, pr.FromRef.LatestCommit, opts.SHA)
+		}
+		if opts.Method != "" {
+			strategy, ok := bitbucketStrategies[opts.Method]
+			if !ok {
+				return fmt.Errorf("merge method %q is not supported by Bitbucket Server", opts.Method)
+			}
+			in["strategyId"] = strategy
+		}
+	}
+	return bb.do(ctx, http.MethodPost, fmt.Sprintf("%s/pull-requests/%d/merge?version=%d", bb.repoPath, number, pr.Version), in, nil)
+}
+
+// RequestReviewers implements Forge by adding each user as a reviewing participant
+func (bb *bitbucketServer) RequestReviewers(ctx context.Context, number int, reviewers []string) error {
+	for _, name := range reviewers {
+		in := map[string]interface{}{
+			"user": map[string]interface{}{"name": name},
+			"role": "REVIEWER",
+		}
+		if err := bb.do(ctx, http.MethodPost, fmt.Sprintf("%s/pull-requests/%d/participants", bb.repoPath, number), in, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddLabels implements Forge. Bitbucket Server has no pull request labels.
+func (bb *bitbucketServer) AddLabels(ctx context.Context, number int, labels []string) error {
+	return fmt.Errorf("pull request labels are not supported by Bitbucket Server")
+}
+
+// ListReviews implements Forge from the pull request's reviewers, which Bitbucket
+// lists with their current status only
+func (bb *bitbucketServer) ListReviews(ctx context.Context, number int) ([]Review, error) {
+	pr, err := bb.getPR(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]Review, 0, len(pr.Reviewers))
+	for _, r := range pr.Reviewers {
+		state := ReviewPending
+		switch r.Status {
+		case "APPROVED":
+			state = ReviewApproved
+		case "NEEDS_WORK":
+			state = ReviewChangesRequested
+		}
+		reviews = append(reviews, Review{Author: r.User.Name, State: state})
+	}
+	return reviews, nil
+}
+
+// bitbucketComment is a comment as returned by the API, with its replies nested
+type bitbucketComment struct {
+	ID          int64              `json:"id"`
+	Text        string             `json:"text"`
+	Author      bitbucketUser      `json:"author"`
+	CreatedDate int64              `json:"createdDate"`
+	State       string             `json:"state"`
+	Comments    []bitbucketComment `json:"comments"`
+}
+
+// ListComments implements Forge using the pull request's activity stream, newest
+// first. The activity that added a thread's first comment carries the whole thread;
+// those for replies and edits are skipped.
+func (bb *bitbucketServer) ListComments(ctx context.Context, number int) ([]Comment, error) {
+	activities, err := listValues[struct {
+		Action        string            `json:"action"`
+		CommentAction string            `json:"commentAction"`
+		Comment       *bitbucketComment `json:"comment"`
+		CommentAnchor *struct {
+			Path string `json:"path"`
+			Line int    `json:"line"`
+		} `json:"commentAnchor"`
+	}](ctx, &bb.client, fmt.Sprintf("%s/pull-requests/%d/activities?limit=%d", bb.repoPath, number, pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []Comment
+	for i := len(activities) - 1; i >= 0; i-- {
+		a := activities[i]
+		if a.Action != "COMMENTED" || a.CommentAction != "ADDED" || a.Comment == nil {
+			continue
+		}
+		path, line := "", 0
+		if a.CommentAnchor != nil {
+			path, line = a.CommentAnchor.Path, a.CommentAnchor.Line
+		}
+		thread := strconv.FormatInt(a.Comment.ID, 10)
+		resolved := a.Comment.State == "RESOLVED"
+
+		// Walk the reply tree depth first, which keeps each reply after its parent
+		var walk func(c *bitbucketComment)
+		walk = func(c *bitbucketComment) {
+			comments = append(comments, Comment{
+				ID:        strconv.FormatInt(c.ID, 10),
+				ThreadID:  thread,
+				Author:    c.Author.Name,
+				Body:      c.Text,
+				Path:      path,
+				Line:      line,
+				Resolved:  resolved,
+				CreatedAt: time.UnixMilli(c.CreatedDate),
+			})
+			for j := range c.Comments {
+				walk(&c.Comments[j])
+			}
+		}
+		walk(a.Comment)
+	}
+	return comments, nil
+}
+
+// bitbucketPage is a page of a Bitbucket Server list endpoint
+type bitbucketPage[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart *int `json:"nextPageStart"`
+}
+
+// listValues GETs every page of a Bitbucket Server list endpoint
+func listValues[T any](ctx context.Context, c *client, path string) ([]T, error) {
+	var values []T
+	err := getPages(ctx, c, path, func(page bitbucketPage[T]) int {
+		values = append(values, page.Values...)
+		return len(page.Values)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// bitbucketNextPage requests the page starting at nextPageStart until isLastPage is set
+func bitbucketNextPage(reqURL string, _ http.Header, body []byte, _ int) (string, error) {
+	var page bitbucketPage[json.RawMessage]
+	if err := json.Unmarshal(body, &page); err != nil {
+		return "", fmt.Errorf("failed to decode page of %s: %w", reqURL, err)
+	}
+	if page.IsLastPage || page.NextPageStart == nil {
+		return "", nil
+	}
+	return withQuery(reqURL, "start", strconv.Itoa(*page.NextPageStart))
+}
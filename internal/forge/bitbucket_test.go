@@ -0,0 +1,258 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const bitbucketPRJSON = `{"id": 12, "version": 3, "title": "Add feature", "state": "OPEN",
+	"fromRef": {"id": "refs/heads/awt/agent/1", "displayId": "awt/agent/1", "latestCommit": "0123456789abcdef0123456789abcdef01234567"},
+	"toRef": {"id": "refs/heads/main", "displayId": "main"},
+	"links": {"self": [{"href": "https://bitbucket.example.com/projects/PROJ/repos/repo/pull-requests/12"}]}}`
+
+// newTestBitbucket starts a stand-in Bitbucket Server for PROJ/repo and returns a forge using it
+func newTestBitbucket(t *testing.T, mux *http.ServeMux) Forge {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer bb-secret" {
+			t.Errorf("Authorization = %q, want bearer token", got)
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	// The owner comes from the clone URL, where project keys are lower case
+	f, err := New(Options{Kind: KindBitbucketServer, Host: "bitbucket.example.com", Owner: "proj", Repo: "repo", Token: "bb-secret", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return f
+}
+
+func TestBitbucketCreatePR(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /rest/api/1.0/projects/PROJ/repos/repo/pull-requests", func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			Title       string                 `json:"title"`
+			Description string                 `json:"description"`
+			Draft       *bool                  `json:"draft"`
+			FromRef     map[string]interface{} `json:"fromRef"`
+			ToRef       map[string]interface{} `json:"toRef"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if in.Title != "Add feature" || in.Description != "Body" || in.Draft != nil {
+			t.Errorf("unexpected request body: %+v", in)
+		}
+		if in.FromRef["id"] != "refs/heads/awt/agent/1" || in.ToRef["id"] != "refs/heads/main" {
+			t.Errorf("unexpected refs: %v -> %v", in.FromRef, in.ToRef)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(bitbucketPRJSON))
+	})
+	f := newTestBitbucket(t, mux)
+
+	pr, err := f.CreatePR(context.Background(), &PRRequest{Title: "Add feature", Body: "Body", Head: "awt/agent/1", Base: "main"})
+	if err != nil {
+		t.Fatalf("CreatePR() failed: %v", err)
+	}
+	if pr.Number != 12 || pr.URL != "https://bitbucket.example.com/projects/PROJ/repos/repo/pull-requests/12" || pr.State != PRStateOpen {
+		t.Errorf("unexpected PR: %+v", pr)
+	}
+	if pr.Head != "awt/agent/1" || pr.Base != "main" || pr.HeadSHA != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("unexpected PR branches: %+v", pr)
+	}
+}
+
+func TestBitbucketGetPR(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/1.0/projects/PROJ/repos/repo/pull-requests/12", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(bitbucketPRJSON))
+	})
+	mux.HandleFunc("GET /rest/api/1.0/projects/PROJ/repos/repo/pull-requests/12/merge", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"canMerge": false, "conflicted": true, "vetoes": []}`))
+	})
+	f := newTestBitbucket(t, mux)
+
+	pr, err := f.GetPR(context.Background(), 12)
+	if err != nil {
+		t.Fatalf("GetPR() failed: %v", err)
+	}
+	if pr.Mergeable == nil || *pr.Mergeable {
+		t.Errorf("Mergeable = %v, want false for a conflicted PR", pr.Mergeable)
+	}
+}
+
+func TestBitbucketListChecks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/1.0/projects/PROJ/repos/repo/commits", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("until"); got != "awt/agent/1" {
+			t.Errorf("until = %q, want the branch", got)
+		}
+		_, _ = w.Write([]byte(`{"values": [{"id": "0123456789abcdef0123456789abcdef01234567"}]}`))
+	})
+	mux.HandleFunc("GET /rest/build-status/1.0/commits/0123456789abcdef0123456789abcdef01234567", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"values": [
+			{"state": "SUCCESSFUL", "key": "build", "name": "Build", "url": "https://ci/build"},
+			{"state": "FAILED", "key": "test"},
+			{"state": "INPROGRESS", "key": "lint", "name": "Lint"}]}`))
+	})
+	f := newTestBitbucket(t, mux)
+
+	checks, err := f.ListChecks(context.Background(), "awt/agent/1")
+	if err != nil {
+		t.Fatalf("ListChecks() failed: %v", err)
+	}
+	want := []Check{
+		{Name: "Build", State: CheckSuccess, URL: "https://ci/build"},
+		{Name: "test", State: CheckFailure},
+		{Name: "Lint", State: CheckRunning},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("ListChecks() = %+v, want %+v", checks, want)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Errorf("check %d = %+v, want %+v", i, checks[i], want[i])
+		}
+	}
+}
+
+func TestBitbucketMerge(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/1.0/projects/PROJ/repos/repo/pull-requests/12", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(bitbucketPRJSON))
+	})
+	mux.HandleFunc("POST /rest/api/1.0/projects/PROJ/repos/repo/pull-requests/12/merge", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("version"); got != "3" {
+			t.Errorf("version = %q, want 3", got)
+		}
+		var in map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		if in["strategyId"] != "squash" {
+			t.Errorf("unexpected request body: %v", in)
+		}
+		_, _ = w.Write([]byte(bitbucketPRJSON))
+	})
+	f := newTestBitbucket(t, mux)
+
+	ctx := context.Background()
+	if err := f.Merge(ctx, 12, &MergeOptions{Method: MergeMethodSquash}); err != nil {
+		t.Fatalf("Merge() failed: %v", err)
+	}
+	if err := f.Merge(ctx, 12, &MergeOptions{SHA: "fedcba9876543210fedcba9876543210fedcba98"}); err == nil {
+		t.Error("Merge() with a stale SHA should fail")
+	}
+}
+
+func TestBitbucketReviewersAndLabels(t *testing.T) {
+	mux := http.NewServeMux()
+	var added []string
+	mux.HandleFunc("POST /rest/api/1.0/projects/PROJ/repos/repo/pull-requests/12/participants", func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			User struct {
+				Name string `json:"name"`
+			} `json:"user"`
+			Role string `json:"role"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if in.Role != "REVIEWER" {
+			t.Errorf("role = %q, want REVIEWER", in.Role)
+		}
+		added = append(added, in.User.Name)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	f := newTestBitbucket(t, mux)
+
+	if err := f.RequestReviewers(context.Background(), 12, []string{"alice", "bob"}); err != nil {
+		t.Fatalf("RequestReviewers() failed: %v", err)
+	}
+	if len(added) != 2 || added[0] != "alice" || added[1] != "bob" {
+		t.Errorf("participants added = %v, want [alice bob]", added)
+	}
+	if err := f.AddLabels(context.Background(), 12, []string{"agent"}); err == nil {
+		t.Error("AddLabels() should fail on Bitbucket Server")
+	}
+}
+
+func TestBitbucketListReviewsAndComments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/1.0/projects/PROJ/repos/repo/pull-requests/12", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": 12, "version": 3, "state": "OPEN", "reviewers": [
+			{"user": {"name": "alice"}, "status": "NEEDS_WORK"},
+			{"user": {"name": "bob"}, "status": "UNAPPROVED"}]}`))
+	})
+	mux.HandleFunc("GET /rest/api/1.0/projects/PROJ/repos/repo/pull-requests/12/activities", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"values": [
+			{"action": "COMMENTED", "commentAction": "REPLIED", "comment": {"id": 3, "text": "Fixed", "author": {"name": "agent"}}},
+			{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 2, "text": "Please rebase", "author": {"name": "bob"}, "createdDate": 1736510400000}},
+			{"action": "APPROVED"},
+			{"action": "COMMENTED", "commentAction": "ADDED", "commentAnchor": {"path": "main.go", "line": 12},
+				"comment": {"id": 1, "text": "Off by one", "author": {"name": "alice"}, "state": "RESOLVED",
+					"comments": [{"id": 3, "text": "Fixed", "author": {"name": "agent"}}]}}]}`))
+	})
+	f := newTestBitbucket(t, mux)
+
+	reviews, err := f.ListReviews(context.Background(), 12)
+	if err != nil {
+		t.Fatalf("ListReviews() failed: %v", err)
+	}
+	if len(reviews) != 2 || reviews[0].Author != "alice" || reviews[0].State != ReviewChangesRequested || reviews[1].State != ReviewPending {
+		t.Errorf("unexpected reviews: %+v", reviews)
+	}
+
+	comments, err := f.ListComments(context.Background(), 12)
+	if err != nil {
+		t.Fatalf("ListComments() failed: %v", err)
+	}
+	want := []Comment{
+		{ID: "1", ThreadID: "1", Author: "alice", Body: "Off by one", Path: "main.go", Line: 12, Resolved: true},
+		{ID: "3", ThreadID: "1", Author: "agent", Body: "Fixed", Path: "main.go", Line: 12, Resolved: true},
+		{ID: "2", ThreadID: "2", Author: "bob", Body: "Please rebase"},
+	}
+	if len(comments) != len(want) {
+		t.Fatalf("got %d comments, want %d: %+v", len(comments), len(want), comments)
+	}
+	for i := range want {
+		got := comments[i]
+		if i == 2 && !got.CreatedAt.Equal(time.UnixMilli(1736510400000)) {
+			t.Errorf("CreatedAt = %v, want the comment's createdDate", got.CreatedAt)
+		}
+		got.CreatedAt = want[i].CreatedAt
+		if got != want[i] {
+			t.Errorf("comment %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestBitbucketListPaginates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/1.0/projects/PROJ/repos/repo/pull-requests/12/activities", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("limit"); got != "100" {
+			t.Errorf("limit = %q, want 100", got)
+		}
+		if r.URL.Query().Get("start") == "1" {
+			_, _ = w.Write([]byte(`{"isLastPage": true, "values": [
+				{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 1, "text": "Older", "author": {"name": "alice"}}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"isLastPage": false, "nextPageStart": 1, "values": [
+			{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 2, "text": "Newer", "author": {"name": "bob"}}}]}`))
+	})
+	f := newTestBitbucket(t, mux)
+
+	comments, err := f.ListComments(context.Background(), 12)
+	if err != nil {
+		t.Fatalf("ListComments() failed: %v", err)
+	}
+	if len(comments) != 2 || comments[0].ID != "1" || comments[1].ID != "2" {
+		t.Errorf("expected the comments of both pages oldest first, got %+v", comments)
+	}
+}
@@ -0,0 +1,530 @@
+// Package forge talks to the REST APIs of Git hosting services (forges) to open
+// and inspect pull requests for task branches.
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Kind identifies a forge implementation
+type Kind string
+
+const (
+	// KindGitHub is GitHub and GitHub Enterprise Server
+	KindGitHub Kind = "github"
+	// KindGitLab is GitLab, hosted or self-managed
+	KindGitLab Kind = "gitlab"
+	// KindGitea is Gitea
+	KindGitea Kind = "gitea"
+	// KindForgejo is Forgejo, which serves the Gitea API
+	KindForgejo Kind = "forgejo"
+	// KindBitbucketServer is Bitbucket Server and Bitbucket Data Center
+	KindBitbucketServer Kind = "bitbucket-server"
+)
+
+// Kinds lists the supported forge kinds
+var Kinds = []Kind{KindGitHub, KindGitLab, KindGitea, KindForgejo, KindBitbucketServer}
+
+// ParseKind validates a forge kind name
+func ParseKind(s string) (Kind, error) {
+	for _, kind := range Kinds {
+		if string(kind) == strings.ToLower(s) {
+			return kind, nil
+		}
+	}
+	names := make([]string, len(Kinds))
+	for i, kind := range Kinds {
+		names[i] = string(kind)
+	}
+	return "", fmt.Errorf("unknown forge kind %q (must be one of %s)", s, strings.Join(names, ", "))
+}
+
+// PR states, normalized across forges
+const (
+	PRStateOpen   = "open"
+	PRStateClosed = "closed"
+	PRStateMerged = "merged"
+)
+
+// CheckState is the normalized state of a CI check
+type CheckState string
+
+const (
+	CheckPending   CheckState = "pending"
+	CheckRunning   CheckState = "running"
+	CheckSuccess   CheckState = "success"
+	CheckFailure   CheckState = "failure"
+	CheckCancelled CheckState = "cancelled"
+	CheckSkipped   CheckState = "skipped"
+	CheckNeutral   CheckState = "neutral"
+)
+
+// ReviewState is the normalized state of a pull request review
+type ReviewState string
+
+const (
+	ReviewApproved         ReviewState = "approved"
+	ReviewChangesRequested ReviewState = "changes_requested"
+	ReviewCommented        ReviewState = "commented"
+	ReviewDismissed        ReviewState = "dismissed"
+	ReviewPending          ReviewState = "pending"
+)
+
+// Merge methods
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+// ErrNoToken is returned by New when no API token is configured for the host
+var ErrNoToken = errors.New("no forge API token")
+
+// Forge is a Git hosting service that manages pull requests (merge requests on GitLab)
+type Forge interface {
+	// Kind returns which forge this is
+	Kind() Kind
+
+	// CreatePR opens a pull request
+	CreatePR(ctx context.Context, req *PRRequest) (*PR, error)
+
+	// GetPR returns the pull request with the given number
+	GetPR(ctx context.Context, number int) (*PR, error)
+
+	// ListChecks returns the CI checks reported for ref (a commit SHA or branch name)
+	ListChecks(ctx context.Context, ref string) ([]Check, error)
+
+	// Merge merges the pull request with the given number
+	Merge(ctx context.Context, number int, opts *MergeOptions) error
+
+	// RequestReviewers asks the users with the given usernames to review the pull request
+	RequestReviewers(ctx context.Context, number int, reviewers []string) error
+
+	// AddLabels adds the labels with the given names to the pull request
+	AddLabels(ctx context.Context, number int, labels []string) error
+
+	// ListReviews returns the reviews of the pull request, oldest first
+	ListReviews(ctx context.Context, number int) ([]Review, error)
+
+	// ListComments returns the comments on the pull request, both general ones and
+	// those on lines of the diff, oldest first within each thread
+	ListComments(ctx context.Context, number int) ([]Comment, error)
+}
+
+// PRRequest describes a pull request to create
+type PRRequest struct {
+	Title string
+	Body  string
+	// Head is the branch with the changes
+	Head string
+	// Base is the branch the changes should be merged into
+	Base  string
+	Draft bool
+}
+
+// PR is a pull request (or GitLab merge request)
+type PR struct {
+	// Number is the PR number (the IID on GitLab)
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	// State is PRStateOpen, PRStateClosed or PRStateMerged
+	State   string `json:"state"`
+	Draft   bool   `json:"draft,omitempty"`
+	Head    string `json:"head"`
+	Base    string `json:"base"`
+	HeadSHA string `json:"head_sha,omitempty"`
+	// Mergeable is nil while the forge has not computed it yet
+	Mergeable *bool `json:"mergeable,omitempty"`
+}
+
+// Check is one CI check or commit status
+type Check struct {
+	Name  string     `json:"name"`
+	State CheckState `json:"state"`
+	URL   string     `json:"url,omitempty"`
+}
+
+// Review is one reviewer's verdict on a pull request. Forges that only record
+// approvals (GitLab) return one approved review per approver.
+type Review struct {
+	Author      string      `json:"author"`
+	State       ReviewState `json:"state"`
+	Body        string      `json:"body,omitempty"`
+	SubmittedAt *time.Time  `json:"submitted_at,omitempty"`
+}
+
+// Comment is a pull request comment. Comments on the diff have a Path and, unless
+// they are on the whole file, a Line in the new version of the file.
+type Comment struct {
+	ID string `json:"id"`
+	// ThreadID is the ID of the first comment of the thread this comment belongs
+	// to (its own ID if it starts one), or the discussion ID on GitLab
+	ThreadID  string    `json:"thread_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	Path      string    `json:"path,omitempty"`
+	Line      int       `json:"line,omitempty"`
+	Resolved  bool      `json:"resolved,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CombinedCheckState sums up checks the way forges show them on a pull request:
+// failure if any check failed or was cancelled, pending if any has not finished,
+// and success otherwise. It returns "" if there are no checks.
+func CombinedCheckState(checks []Check) CheckState {
+	if len(checks) == 0 {
+		return ""
+	}
+	state := CheckSuccess
+	for _, c := range checks {
+		switch c.State {
+		case CheckFailure, CheckCancelled:
+			return CheckFailure
+		case CheckPending, CheckRunning:
+			state = CheckPending
+		}
+	}
+	return state
+}
+
+// ReviewDecision returns the overall verdict of the reviews, taking each author's
+// latest approval or change request: changes_requested if any author requested
+// changes, approved if any approved, and "" if neither.
+func ReviewDecision(reviews []Review) ReviewState {
+	latest := make(map[string]ReviewState)
+	for _, r := range reviews {
+		switch r.State {
+		case ReviewApproved, ReviewChangesRequested, ReviewDismissed:
+			latest[r.Author] = r.State
+		}
+	}
+
+	decision := ReviewState("")
+	for _, state := range latest {
+		switch state {
+		case ReviewChangesRequested:
+			return ReviewChangesRequested
+		case ReviewApproved:
+			decision = ReviewApproved
+		}
+	}
+	return decision
+}
+
+// MergeOptions controls how a pull request is merged
+type MergeOptions struct {
+	// Method is MergeMethodMerge (default), MergeMethodSquash or MergeMethodRebase
+	Method string
+	// SHA, if set, makes the merge fail unless the PR head is still at this commit
+	SHA string
+}
+
+// Options selects and configures a forge
+type Options struct {
+	Kind Kind
+	// Host is the host name of the remote (e.g. github.com)
+	Host string
+	// Scheme is the scheme of the web UI (default https)
+	Scheme string
+	// Owner is the repository owner: user, organization, GitLab group or Bitbucket project key
+	Owner string
+	Repo  string
+	// Token authenticates API requests
+	Token string
+	// APIURL overrides the API base URL derived from Host. For Bitbucket Server
+	// it is the server's base URL, under which both the core and build status
+	// APIs are found.
+	APIURL string
+	// HTTPClient is used for requests (nil uses a client with a default timeout)
+	HTTPClient *http.Client
+}
+
+// defaultTimeout bounds each API request made with the default HTTP client
+const defaultTimeout = 60 * time.Second
+
+// New returns the forge described by opts. It returns ErrNoToken if opts.Token is empty.
+func New(opts Options) (Forge, error) {
+	if opts.Token == "" {
+		return nil, fmt.Errorf("%w for %s", ErrNoToken, opts.Host)
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	switch opts.Kind {
+	case KindGitHub:
+		return newGitHub(opts), nil
+	case KindGitLab:
+		return newGitLab(opts), nil
+	case KindGitea, KindForgejo:
+		return newGitea(opts), nil
+	case KindBitbucketServer:
+		return newBitbucketServer(opts), nil
+	default:
+		return nil, fmt.Errorf("unsupported forge kind %q for %s", opts.Kind, opts.Host)
+	}
+}
+
+// webURL returns the base URL of the forge's web UI
+func (opts *Options) webURL() string {
+	scheme := opts.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, opts.Host)
+}
+
+// CompareURL returns the web page for opening a pull request from head into base.
+// It needs no token; unknown kinds get the Gitea-style compare page.
+func CompareURL(opts Options, head, base string) string {
+	web := opts.webURL()
+	switch opts.Kind {
+	case KindGitHub:
+		return fmt.Sprintf("%s/%s/%s/compare/%s...%s?expand=1", web, opts.Owner, opts.Repo, base, head)
+	case KindGitLab:
+		return fmt.Sprintf("%s/%s/%s/-/merge_requests/new?merge_request[source_branch]=%s&merge_request[target_branch]=%s",
+			web, opts.Owner, opts.Repo, url.QueryEscape(head), url.QueryEscape(base))
+	case KindBitbucketServer:
+		project := "projects/" + bitbucketProjectKey(opts.Owner)
+		if user, ok := strings.CutPrefix(opts.Owner, "~"); ok {
+			project = "users/" + user
+		}
+		return fmt.Sprintf("%s/%s/repos/%s/pull-requests?create&sourceBranch=%s&targetBranch=%s",
+			web, project, opts.Repo, url.QueryEscape("refs/heads/"+head), url.QueryEscape("refs/heads/"+base))
+	default:
+		return fmt.Sprintf("%s/%s/%s/compare/%s...%s", web, opts.Owner, opts.Repo, base, head)
+	}
+}
+
+// DetectKind guesses the forge kind from a host name, returning "" if it is unknown.
+// It is only used for hosts whose kind is not configured.
+func DetectKind(host string) Kind {
+	host = strings.ToLower(host)
+	switch {
+	case strings.Contains(host, "github"):
+		return KindGitHub
+	case strings.Contains(host, "gitlab"):
+		return KindGitLab
+	default:
+		return ""
+	}
+}
+
+// tokenEnv lists the environment variables read for each kind's token, in order
+var tokenEnv = map[Kind][]string{
+	KindGitHub:          {"GITHUB_TOKEN", "GH_TOKEN"},
+	KindGitLab:          {"GITLAB_TOKEN", "GL_TOKEN"},
+	KindGitea:           {"GITEA_TOKEN"},
+	KindForgejo:         {"FORGEJO_TOKEN", "GITEA_TOKEN"},
+	KindBitbucketServer: {"BITBUCKET_TOKEN"},
+}
+
+// TokenFromEnv returns the API token for kind from the environment, or ""
+func TokenFromEnv(kind Kind) string {
+	for _, name := range tokenEnv[kind] {
+		if val := os.Getenv(name); val != "" {
+			return val
+		}
+	}
+	return ""
+}
+
+// APIError is a non-2xx response from a forge API
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// Message is the error message reported by the forge, if any
+	Message string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// pageSize is the number of items requested per page from list endpoints
+const pageSize = 100
+
+// nextPageFunc returns the URL of the page after the one fetched from reqURL, given
+// its response headers and body and the number of items fetched so far, or "" when
+// it was the last page
+type nextPageFunc func(reqURL string, header http.Header, body []byte, count int) (string, error)
+
+// client sends JSON requests to a forge API
+type client struct {
+	baseURL string
+	http    *http.Client
+	// auth sets the authentication headers on a request
+	auth func(req *http.Request)
+	// nextPage finds the following page of a list endpoint
+	nextPage nextPageFunc
+}
+
+// do sends a request with in (if non-nil) as the JSON body and decodes the JSON
+// response into out (if non-nil)
+func (c *client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	_, _, err := c.send(ctx, method, c.baseURL+path, in, out)
+	return err
+}
+
+// getPages GETs path and the pages of the list after it, decoding each page into a
+// new P and passing it to add, which returns the number of items on the page. The
+// caller sets the page size in path.
+func getPages[P any](ctx context.Context, c *client, path string, add func(page P) int) error {
+	reqURL := c.baseURL + path
+	count := 0
+	for {
+		var page P
+		header, body, err := c.send(ctx, http.MethodGet, reqURL, nil, &page)
+		if err != nil {
+			return err
+		}
+		n := add(page)
+		if n == 0 {
+			return nil
+		}
+		count += n
+
+		next, err := c.nextPage(reqURL, header, body, count)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		// Only follow links into the same API, which gets the token
+		if next == reqURL || !strings.HasPrefix(next, c.baseURL+"/") {
+			return fmt.Errorf("unexpected next page %s after %s", next, reqURL)
+		}
+		reqURL = next
+	}
+}
+
+// listAll GETs every page of a list endpoint whose pages are JSON arrays of T
+func listAll[T any](ctx context.Context, c *client, path string) ([]T, error) {
+	var items []T
+	err := getPages(ctx, c, path, func(page []T) int {
+		items = append(items, page...)
+		return len(page)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// withQuery returns reqURL with the query parameter key set to value
+func withQuery(reqURL, key, value string) (string, error) {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// send sends a request to reqURL like do and returns the response headers and body
+func (c *client) send(ctx context.Context, method, reqURL string, in, out interface{}) (http.Header, []byte, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.auth(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, &APIError{
+			Method:     method,
+			URL:        reqURL,
+			StatusCode: resp.StatusCode,
+			Message:    errorMessage(data),
+		}
+	}
+
+	if out == nil || len(data) == 0 {
+		return resp.Header, data, nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode response from %s %s: %w", method, reqURL, err)
+	}
+	return resp.Header, data, nil
+}
+
+// errorMessage extracts a readable message from an API error body. GitHub sends
+// {"message": "...", "errors": [{"message": "..."}]}, GitLab sends {"message": ...}
+// where message may be a string, list or object, or {"error": "..."}.
+func errorMessage(data []byte) string {
+	var body struct {
+		Message json.RawMessage `json:"message"`
+		Error   string          `json:"error"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return strings.TrimSpace(string(data))
+	}
+
+	var parts []string
+	if len(body.Message) > 0 {
+		var s string
+		if err := json.Unmarshal(body.Message, &s); err == nil {
+			parts = append(parts, s)
+		} else {
+			parts = append(parts, string(body.Message))
+		}
+	}
+	if body.Error != "" {
+		parts = append(parts, body.Error)
+	}
+	for _, e := range body.Errors {
+		if e.Message != "" {
+			parts = append(parts, e.Message)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
@@ -0,0 +1,245 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewRequiresToken(t *testing.T) {
+	_, err := New(Options{Kind: KindGitHub, Host: "github.com", Owner: "o", Repo: "r"})
+	if !errors.Is(err, ErrNoToken) {
+		t.Errorf("New() without a token: error = %v, want ErrNoToken", err)
+	}
+
+	if _, err := New(Options{Kind: "svn", Host: "example.com", Token: "t"}); err == nil {
+		t.Error("New() with an unknown kind should fail")
+	}
+}
+
+func TestDetectKind(t *testing.T) {
+	tests := []struct {
+		host string
+		want Kind
+	}{
+		{"github.com", KindGitHub},
+		{"github.example.com", KindGitHub},
+		{"gitlab.com", KindGitLab},
+		{"GitLab.internal", KindGitLab},
+		{"git.example.com", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectKind(tt.host); got != tt.want {
+			t.Errorf("DetectKind(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	for _, kind := range Kinds {
+		if got, err := ParseKind(string(kind)); err != nil || got != kind {
+			t.Errorf("ParseKind(%q) = %q, %v", kind, got, err)
+		}
+	}
+	if got, err := ParseKind("Forgejo"); err != nil || got != KindForgejo {
+		t.Errorf("ParseKind(Forgejo) = %q, %v, want %q", got, err, KindForgejo)
+	}
+	if _, err := ParseKind("bitbucket-cloud"); err == nil {
+		t.Error("ParseKind(bitbucket-cloud) should fail")
+	}
+}
+
+func TestCompareURL(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "GitHub",
+			opts: Options{Kind: KindGitHub, Host: "github.com", Owner: "owner", Repo: "repo"},
+			want: "https://github.com/owner/repo/compare/main...feature?expand=1",
+		},
+		{
+			name: "GitLab",
+			opts: Options{Kind: KindGitLab, Host: "gitlab.com", Owner: "group", Repo: "project"},
+			want: "https://gitlab.com/group/project/-/merge_requests/new?merge_request[source_branch]=feature&merge_request[target_branch]=main",
+		},
+		{
+			name: "Forgejo over HTTP",
+			opts: Options{Kind: KindForgejo, Host: "localhost:3000", Scheme: "http", Owner: "owner", Repo: "repo"},
+			want: "http://localhost:3000/owner/repo/compare/main...feature",
+		},
+		{
+			name: "Bitbucket Server project",
+			opts: Options{Kind: KindBitbucketServer, Host: "bitbucket.example.com", Owner: "proj", Repo: "repo"},
+			want: "https://bitbucket.example.com/projects/PROJ/repos/repo/pull-requests?create&sourceBranch=refs%2Fheads%2Ffeature&targetBranch=refs%2Fheads%2Fmain",
+		},
+		{
+			name: "Bitbucket Server personal repository",
+			opts: Options{Kind: KindBitbucketServer, Host: "bitbucket.example.com", Owner: "~jdoe", Repo: "repo"},
+			want: "https://bitbucket.example.com/users/jdoe/repos/repo/pull-requests?create&sourceBranch=refs%2Fheads%2Ffeature&targetBranch=refs%2Fheads%2Fmain",
+		},
+		{
+			name: "unknown forge",
+			opts: Options{Host: "git.example.com", Owner: "owner", Repo: "repo"},
+			want: "https://git.example.com/owner/repo/compare/main...feature",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareURL(tt.opts, "feature", "main"); got != tt.want {
+				t.Errorf("CompareURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "gh-token")
+	t.Setenv("GITLAB_TOKEN", "gitlab-token")
+	t.Setenv("GL_TOKEN", "gl-token")
+
+	if got := TokenFromEnv(KindGitHub); got != "gh-token" {
+		t.Errorf("TokenFromEnv(github) = %q, want %q", got, "gh-token")
+	}
+	if got := TokenFromEnv(KindGitLab); got != "gitlab-token" {
+		t.Errorf("TokenFromEnv(gitlab) = %q, want %q", got, "gitlab-token")
+	}
+}
+
+func TestDefaultAPIURLs(t *testing.T) {
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{Kind: KindGitHub, Host: "github.com"}, "https://api.github.com"},
+		{Options{Kind: KindGitHub, Host: "github.example.com"}, "https://github.example.com/api/v3"},
+		{Options{Kind: KindGitLab, Host: "gitlab.com"}, "https://gitlab.com/api/v4"},
+		{Options{Kind: KindGitLab, Host: "gitlab.com", APIURL: "http://localhost:8080/"}, "http://localhost:8080"},
+		{Options{Kind: KindForgejo, Host: "localhost:3000", Scheme: "http"}, "http://localhost:3000/api/v1"},
+		{Options{Kind: KindBitbucketServer, Host: "bitbucket.example.com"}, "https://bitbucket.example.com"},
+	}
+	for _, tt := range tests {
+		tt.opts.Token = "t"
+		f, err := New(tt.opts)
+		if err != nil {
+			t.Fatalf("New(%+v) failed: %v", tt.opts, err)
+		}
+		var got string
+		switch f := f.(type) {
+		case *gitHub:
+			got = f.baseURL
+		case *gitLab:
+			got = f.baseURL
+		case *gitea:
+			got = f.baseURL
+		case *bitbucketServer:
+			got = f.baseURL
+		}
+		if got != tt.want {
+			t.Errorf("API URL for %s on %s = %q, want %q", tt.opts.Kind, tt.opts.Host, got, tt.want)
+		}
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"github", `{"message":"Validation Failed","errors":[{"message":"A pull request already exists"}]}`, "Validation Failed: A pull request already exists"},
+		{"gitlab string", `{"message":"401 Unauthorized"}`, "401 Unauthorized"},
+		{"gitlab list", `{"message":["Another open merge request already exists"]}`, `["Another open merge request already exists"]`},
+		{"gitlab error", `{"error":"insufficient_scope"}`, "insufficient_scope"},
+		{"plain text", "bad gateway\n", "bad gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			f, err := New(Options{Kind: KindGitHub, Host: "github.com", Owner: "o", Repo: "r", Token: "t", APIURL: server.URL})
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			_, err = f.GetPR(context.Background(), 1)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GetPR() error = %v, want *APIError", err)
+			}
+			if apiErr.StatusCode != http.StatusUnprocessableEntity || apiErr.Message != tt.want {
+				t.Errorf("APIError = %d %q, want %d %q", apiErr.StatusCode, apiErr.Message, http.StatusUnprocessableEntity, tt.want)
+			}
+		})
+	}
+}
+
+func TestCombinedCheckState(t *testing.T) {
+	tests := []struct {
+		name   string
+		states []CheckState
+		want   CheckState
+	}{
+		{"no checks", nil, ""},
+		{"all passed", []CheckState{CheckSuccess, CheckSkipped, CheckNeutral}, CheckSuccess},
+		{"one running", []CheckState{CheckSuccess, CheckRunning}, CheckPending},
+		{"one failed", []CheckState{CheckPending, CheckFailure, CheckSuccess}, CheckFailure},
+		{"one cancelled", []CheckState{CheckSuccess, CheckCancelled}, CheckFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checks []Check
+			for _, s := range tt.states {
+				checks = append(checks, Check{Name: string(s), State: s})
+			}
+			if got := CombinedCheckState(checks); got != tt.want {
+				t.Errorf("CombinedCheckState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewDecision(t *testing.T) {
+	tests := []struct {
+		name    string
+		reviews []Review
+		want    ReviewState
+	}{
+		{"no reviews", nil, ""},
+		{"only comments", []Review{{Author: "alice", State: ReviewCommented}}, ""},
+		{"approved", []Review{{Author: "alice", State: ReviewApproved}, {Author: "bob", State: ReviewCommented}}, ReviewApproved},
+		{
+			name:    "changes requested by one reviewer",
+			reviews: []Review{{Author: "alice", State: ReviewApproved}, {Author: "bob", State: ReviewChangesRequested}},
+			want:    ReviewChangesRequested,
+		},
+		{
+			name:    "approved after requesting changes",
+			reviews: []Review{{Author: "bob", State: ReviewChangesRequested}, {Author: "bob", State: ReviewCommented}, {Author: "bob", State: ReviewApproved}},
+			want:    ReviewApproved,
+		},
+		{
+			name:    "approval dismissed",
+			reviews: []Review{{Author: "alice", State: ReviewApproved}, {Author: "alice", State: ReviewDismissed}},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReviewDecision(tt.reviews); got != tt.want {
+				t.Errorf("ReviewDecision() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitea implements Forge with the Gitea REST API, which Forgejo also serves
+type gitea struct {
+	client
+	kind Kind
+	// repoPath is /repos/<owner>/<repo>
+	repoPath string
+}
+
+func newGitea(opts Options) *gitea {
+	baseURL := opts.APIURL
+	if baseURL == "" {
+		baseURL = opts.webURL() + "/api/v1"
+	}
+
+	token := opts.Token
+	return &gitea{
+		client: client{
+			baseURL: strings.TrimSuffix(baseURL, "/"),
+			http:    opts.HTTPClient,
+			auth: func(req *http.Request) {
+				req.Header.Set("Authorization", "token "+token)
+			},
+			nextPage: giteaNextPage,
+		},
+		kind:     opts.Kind,
+		repoPath: fmt.Sprintf("/repos/%s/%s", url.PathEscape(opts.Owner), url.PathEscape(opts.Repo)),
+	}
+}
+
+// Kind implements Forge
+func (gt *gitea) Kind() Kind {
+	return gt.kind
+}
+
+// CreatePR implements Forge. Pull requests are returned in the same shape as on GitHub.
+func (gt *gitea) CreatePR(ctx context.Context, req *PRRequest) (*PR, error) {
+	title := req.Title
+	if req.Draft {
+		// Gitea marks pull requests as work in progress by their title
+		title = "WIP: " + title
+	}
+	in := map[string]interface{}{
+		"title": title,
+		"body":  req.Body,
+		"head":  req.Head,
+		"base":  req.Base,
+	}
+	var out gitHubPR
+	if err := gt.do(ctx, http.MethodPost, gt.repoPath+"/pulls", in, &out); err != nil {
+		return nil, err
+	}
+	pr := out.toPR()
+	pr.Draft = pr.Draft || req.Draft
+	return pr, nil
+}
+
+// GetPR implements Forge
+func (gt *gitea) GetPR(ctx context.Context, number int) (*PR, error) {
+	var out gitHubPR
+	if err := gt.do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d", gt.repoPath, number), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.toPR(), nil
+}
+
+// ListChecks implements Forge using the latest commit status of each context,
+// which Gitea and Forgejo Actions also report
+func (gt *gitea) ListChecks(ctx context.Context, ref string) ([]Check, error) {
+	type commitStatus struct {
+		Context   string `json:"context"`
+		Status    string `json:"status"`
+		TargetURL string `json:"target_url"`
+	}
+	type combinedStatusPage struct {
+		Statuses []commitStatus `json:"statuses"`
+	}
+	var statuses []commitStatus
+	path := fmt.Sprintf("%s/commits/%s/status?limit=%d", gt.repoPath, url.PathEscape(ref), pageSize)
+	err := getPages(ctx, &gt.client, path, func(page combinedStatusPage) int {
+		statuses = append(statuses, page.Statuses...)
+		return len(page.Statuses)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]Check, 0, len(statuses))
+	for _, s := range statuses {
+		checks = append(checks, Check{
+			Name:  s.Context,
+			State: giteaStatusState(s.Status),
+			URL:   s.TargetURL,
+		})
+	}
+	return checks, nil
+}
+
+// giteaStatusState maps a commit status to a CheckState
+func giteaStatusState(status string) CheckState {
+	switch status {
+	case "success":
+		return CheckSuccess
+	case "pending":
+		return CheckPending
+	case "warning":
+		return CheckNeutral
+	case "skipped":
+		return CheckSkipped
+	default:
+		// failure, error
+		return CheckFailure
+	}
+}
+
+// Merge implements Forge
+func (gt *gitea) Merge(ctx context.Context, number int, opts *MergeOptions) error {
+	method := MergeMethodMerge
+	if opts != nil && opts.Method != "" {
+		method = opts.Method
+	}
+	in := map[string]interface{}{"Do": method}
+	if opts != nil && opts.SHA != "" {
+		in["head_commit_id"] = opts.SHA
+	}
+	return gt.do(ctx, http.MethodPost, fmt.Sprintf("%s/pulls/%d/merge", gt.repoPath, number), in, nil)
+}
+
+// RequestReviewers implements Forge
+func (gt *gitea) RequestReviewers(ctx context.Context, number int, reviewers []string) error {
+	in := map[string]interface{}{"reviewers": reviewers}
+	return gt.do(ctx, http.MethodPost, fmt.Sprintf("%s/pulls/%d/requested_reviewers", gt.repoPath, number), in, nil)
+}
+
+// AddLabels implements Forge. Labels are added by ID, so the names are looked up
+// among the repository's labels first.
+func (gt *gitea) AddLabels(ctx context.Context, number int, labels []string) error {
+	repoLabels, err := listAll[struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}](ctx, &gt.client, fmt.Sprintf("%s/labels?limit=%d", gt.repoPath, pageSize))
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(labels))
+	for _, name := range labels {
+		found := false
+		for _, l := range repoLabels {
+			if strings.EqualFold(l.Name, name) {
+				ids = append(ids, l.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("no label %q in the repository", name)
+		}
+	}
+
+	in := map[string]interface{}{"labels": ids}
+	return gt.do(ctx, http.MethodPost, fmt.Sprintf("%s/issues/%d/labels", gt.repoPath, number), in, nil)
+}
+
+// ListReviews implements Forge. Reviews are returned in the same shape as on GitHub,
+// with dismissal as a separate flag.
+func (gt *gitea) ListReviews(ctx context.Context, number int) ([]Review, error) {
+	reviews, err := gt.listReviews(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Review, 0, len(reviews))
+	for _, r := range reviews {
+		state := gitHubReviewState(r.State)
+		if r.Dismissed {
+			state = ReviewDismissed
+		}
+		out = append(out, Review{
+			Author:      r.User.Login,
+			State:       state,
+			Body:        r.Body,
+			SubmittedAt: r.SubmittedAt,
+		})
+	}
+	return out, nil
+}
+
+// giteaReview is a pull request review as returned by the API
+type giteaReview struct {
+	gitHubReview
+	Dismissed     bool `json:"dismissed"`
+	CommentsCount int  `json:"comments_count"`
+}
+
+func (gt *gitea) listReviews(ctx context.Context, number int) ([]giteaReview, error) {
+	return listAll[giteaReview](ctx, &gt.client, fmt.Sprintf("%s/pulls/%d/reviews?limit=%d", gt.repoPath, number, pageSize))
+}
+
+// ListComments implements Forge. Diff comments are fetched per review; the API
+// does not link replies, so each of them starts its own thread.
+func (gt *gitea) ListComments(ctx context.Context, number int) ([]Comment, error) {
+	reviews, err := gt.listReviews(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []Comment
+	for _, r := range reviews {
+		if r.CommentsCount == 0 {
+			continue
+		}
+		var reviewComments []struct {
+			ID        int64      `json:"id"`
+			User      gitHubUser `json:"user"`
+			Body      string     `json:"body"`
+			Path      string     `json:"path"`
+			Position  int        `json:"position"`
+			Original  int        `json:"original_position"`
+			Resolver  *struct{}  `json:"resolver"`
+			HTMLURL   string     `json:"html_url"`
+			CreatedAt time.Time  `json:"created_at"`
+		}
+		path := fmt.Sprintf("%s/pulls/%d/reviews/%d/comments", gt.repoPath, number, r.ID)
+		if err := gt.do(ctx, http.MethodGet, path, nil, &reviewComments); err != nil {
+			return nil, err
+		}
+		for _, c := range reviewComments {
+			line := c.Position
+			if line == 0 {
+				line = c.Original
+			}
+			id := strconv.FormatInt(c.ID, 10)
+			comments = append(comments, Comment{
+				ID:        id,
+				ThreadID:  id,
+				Author:    c.User.Login,
+				Body:      c.Body,
+				Path:      c.Path,
+				Line:      line,
+				Resolved:  c.Resolver != nil,
+				URL:       c.HTMLURL,
+				CreatedAt: c.CreatedAt,
+			})
+		}
+	}
+
+	issueComments, err := listAll[gitHubIssueComment](ctx, &gt.client, fmt.Sprintf("%s/issues/%d/comments?limit=%d", gt.repoPath, number, pageSize))
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range issueComments {
+		comments = append(comments, c.toComment())
+	}
+	return comments, nil
+}
+
+// giteaNextPage requests the next page number until the items fetched reach the
+// X-Total-Count header. Counting items rather than pages keeps working when the
+// server caps the page size below the one requested.
+func giteaNextPage(reqURL string, header http.Header, _ []byte, count int) (string, error) {
+	total, err := strconv.Atoi(header.Get("X-Total-Count"))
+	if err != nil || count >= total {
+		return "", nil
+	}
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return "", err
+	}
+	page, _ := strconv.Atoi(u.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	return withQuery(reqURL, "page", strconv.Itoa(page+1))
+}
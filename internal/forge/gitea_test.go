@@ -0,0 +1,224 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestGitea starts a stand-in Forgejo API for owner/repo and returns a forge using it
+func newTestGitea(t *testing.T, mux *http.ServeMux) Forge {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token fj-secret" {
+			t.Errorf("Authorization = %q, want the token", got)
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	f, err := New(Options{Kind: KindForgejo, Host: "codeberg.org", Owner: "owner", Repo: "repo", Token: "fj-secret", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return f
+}
+
+func TestGiteaCreatePR(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if in["title"] != "WIP: Add feature" || in["head"] != "awt/agent/1" || in["base"] != "main" || in["body"] != "Body" {
+			t.Errorf("unexpected request body: %v", in)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 9, "html_url": "https://codeberg.org/owner/repo/pulls/9", "title": "WIP: Add feature",
+			"state": "open", "merged": false, "head": {"ref": "awt/agent/1", "sha": "abc123"}, "base": {"ref": "main"}, "mergeable": true}`))
+	})
+	f := newTestGitea(t, mux)
+
+	if f.Kind() != KindForgejo {
+		t.Errorf("Kind() = %q, want %q", f.Kind(), KindForgejo)
+	}
+	pr, err := f.CreatePR(context.Background(), &PRRequest{Title: "Add feature", Body: "Body", Head: "awt/agent/1", Base: "main", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePR() failed: %v", err)
+	}
+	if pr.Number != 9 || pr.URL != "https://codeberg.org/owner/repo/pulls/9" || pr.State != PRStateOpen || !pr.Draft {
+		t.Errorf("unexpected PR: %+v", pr)
+	}
+	if pr.Head != "awt/agent/1" || pr.Base != "main" || pr.HeadSHA != "abc123" || pr.Mergeable == nil || !*pr.Mergeable {
+		t.Errorf("unexpected PR branches: %+v", pr)
+	}
+}
+
+func TestGiteaListChecks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/commits/abc123/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"state": "failure", "statuses": [
+			{"context": "ci/build", "status": "success", "target_url": "https://ci/build"},
+			{"context": "ci/test", "status": "error"},
+			{"context": "ci/lint", "status": "pending"},
+			{"context": "ci/vet", "status": "warning"}]}`))
+	})
+	f := newTestGitea(t, mux)
+
+	checks, err := f.ListChecks(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("ListChecks() failed: %v", err)
+	}
+	want := []Check{
+		{Name: "ci/build", State: CheckSuccess, URL: "https://ci/build"},
+		{Name: "ci/test", State: CheckFailure},
+		{Name: "ci/lint", State: CheckPending},
+		{Name: "ci/vet", State: CheckNeutral},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("ListChecks() = %+v, want %+v", checks, want)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Errorf("check %d = %+v, want %+v", i, checks[i], want[i])
+		}
+	}
+}
+
+func TestGiteaMerge(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/owner/repo/pulls/9/merge", func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		if in["Do"] != "rebase" || in["head_commit_id"] != "abc123" {
+			t.Errorf("unexpected request body: %v", in)
+		}
+	})
+	f := newTestGitea(t, mux)
+
+	if err := f.Merge(context.Background(), 9, &MergeOptions{Method: MergeMethodRebase, SHA: "abc123"}); err != nil {
+		t.Fatalf("Merge() failed: %v", err)
+	}
+}
+
+func TestGiteaReviewersAndLabels(t *testing.T) {
+	mux := http.NewServeMux()
+	var gotReviewers []string
+	var gotLabels []int
+	mux.HandleFunc("POST /repos/owner/repo/pulls/9/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			Reviewers []string `json:"reviewers"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		gotReviewers = in.Reviewers
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/labels", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "bug"}, {"id": 4, "name": "Agent"}]`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/issues/9/labels", func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			Labels []int `json:"labels"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		gotLabels = in.Labels
+		_, _ = w.Write([]byte(`[]`))
+	})
+	f := newTestGitea(t, mux)
+
+	if err := f.RequestReviewers(context.Background(), 9, []string{"alice"}); err != nil {
+		t.Fatalf("RequestReviewers() failed: %v", err)
+	}
+	if len(gotReviewers) != 1 || gotReviewers[0] != "alice" {
+		t.Errorf("reviewers = %v, want [alice]", gotReviewers)
+	}
+
+	// Label names are matched case-insensitively and sent as IDs
+	if err := f.AddLabels(context.Background(), 9, []string{"agent"}); err != nil {
+		t.Fatalf("AddLabels() failed: %v", err)
+	}
+	if len(gotLabels) != 1 || gotLabels[0] != 4 {
+		t.Errorf("labels = %v, want [4]", gotLabels)
+	}
+	if err := f.AddLabels(context.Background(), 9, []string{"missing"}); err == nil {
+		t.Error("AddLabels() with an unknown label should fail")
+	}
+}
+
+func TestGiteaListReviewsAndComments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/9/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id": 1, "user": {"login": "alice"}, "state": "REQUEST_CHANGES", "body": "Needs work", "comments_count": 1},
+			{"id": 2, "user": {"login": "bob"}, "state": "APPROVED", "dismissed": true, "comments_count": 0}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/9/reviews/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 5, "user": {"login": "alice"}, "body": "Off by one", "path": "main.go",
+			"position": 12, "original_position": 10, "html_url": "https://codeberg.org/owner/repo/pulls/9#issuecomment-5"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/9/reviews/2/comments", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("comments of a review without comments should not be fetched")
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/9/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 6, "user": {"login": "bob"}, "body": "Thanks"}]`))
+	})
+	f := newTestGitea(t, mux)
+
+	reviews, err := f.ListReviews(context.Background(), 9)
+	if err != nil {
+		t.Fatalf("ListReviews() failed: %v", err)
+	}
+	if len(reviews) != 2 || reviews[0].State != ReviewChangesRequested || reviews[0].Body != "Needs work" || reviews[1].State != ReviewDismissed {
+		t.Errorf("unexpected reviews: %+v", reviews)
+	}
+
+	comments, err := f.ListComments(context.Background(), 9)
+	if err != nil {
+		t.Fatalf("ListComments() failed: %v", err)
+	}
+	want := []Comment{
+		{ID: "5", ThreadID: "5", Author: "alice", Body: "Off by one", Path: "main.go", Line: 12, URL: "https://codeberg.org/owner/repo/pulls/9#issuecomment-5"},
+		{ID: "6", ThreadID: "6", Author: "bob", Body: "Thanks"},
+	}
+	if len(comments) != len(want) {
+		t.Fatalf("got %d comments, want %d: %+v", len(comments), len(want), comments)
+	}
+	for i := range want {
+		if comments[i] != want[i] {
+			t.Errorf("comment %d = %+v, want %+v", i, comments[i], want[i])
+		}
+	}
+}
+
+func TestGiteaListPaginates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/9/reviews", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("limit"); got != "100" {
+			t.Errorf("limit = %q, want 100", got)
+		}
+		// The server caps the page size, so the first page is shorter than the limit
+		w.Header().Set("X-Total-Count", "2")
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`[{"id": 2, "user": {"login": "bob"}, "state": "APPROVED"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": 1, "user": {"login": "alice"}, "state": "REQUEST_CHANGES"}]`))
+	})
+	f := newTestGitea(t, mux)
+
+	reviews, err := f.ListReviews(context.Background(), 9)
+	if err != nil {
+		t.Fatalf("ListReviews() failed: %v", err)
+	}
+	if len(reviews) != 2 || reviews[0].Author != "alice" || reviews[1].Author != "bob" {
+		t.Errorf("expected the reviews of both pages, got %+v", reviews)
+	}
+}
@@ -0,0 +1,376 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitHub implements Forge with the GitHub REST API
+type gitHub struct {
+	client
+	// repoPath is /repos/<owner>/<repo>
+	repoPath string
+}
+
+func newGitHub(opts Options) *gitHub {
+	baseURL := opts.APIURL
+	if baseURL == "" {
+		if strings.EqualFold(opts.Host, "github.com") {
+			baseURL = "https://api.github.com"
+		} else {
+			// GitHub Enterprise Server
+			baseURL = opts.webURL() + "/api/v3"
+		}
+	}
+
+	token := opts.Token
+	return &gitHub{
+		client: client{
+			baseURL: strings.TrimSuffix(baseURL, "/"),
+			http:    opts.HTTPClient,
+			auth: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+token)
+				req.Header.Set("Accept", "application/vnd.github+json")
+				req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+			},
+			nextPage: gitHubNextPage,
+		},
+		repoPath: fmt.Sprintf("/repos/%s/%s", url.PathEscape(opts.Owner), url.PathEscape(opts.Repo)),
+	}
+}
+
+// Kind implements Forge
+func (gh *gitHub) Kind() Kind {
+	return KindGitHub
+}
+
+// gitHubPR is a pull request as returned by the API
+type gitHubPR struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	Draft   bool   `json:"draft"`
+	Merged  bool   `json:"merged"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Mergeable *bool `json:"mergeable"`
+}
+
+func (p *gitHubPR) toPR() *PR {
+	state := PRStateOpen
+	switch {
+	case p.Merged:
+		state = PRStateMerged
+	case p.State == "closed":
+		state = PRStateClosed
+	}
+	return &PR{
+		Number:    p.Number,
+		URL:       p.HTMLURL,
+		Title:     p.Title,
+		State:     state,
+		Draft:     p.Draft,
+		Head:      p.Head.Ref,
+		Base:      p.Base.Ref,
+		HeadSHA:   p.Head.SHA,
+		Mergeable: p.Mergeable,
+	}
+}
+
+// CreatePR implements Forge
+func (gh *gitHub) CreatePR(ctx context.Context, req *PRRequest) (*PR, error) {
+	in := map[string]interface{}{
+		"title": req.Title,
+		"body":  req.Body,
+		"head":  req.Head,
+		"base":  req.Base,
+		"draft": req.Draft,
+	}
+	var out gitHubPR
+	if err := gh.do(ctx, http.MethodPost, gh.repoPath+"/pulls", in, &out); err != nil {
+		return nil, err
+	}
+	return out.toPR(), nil
+}
+
+// GetPR implements Forge
+func (gh *gitHub) GetPR(ctx context.Context, number int) (*PR, error) {
+	var out gitHubPR
+	if err := gh.do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d", gh.repoPath, number), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.toPR(), nil
+}
+
+// ListChecks implements Forge. It combines check runs (GitHub Actions and apps)
+// with commit statuses (older integrations).
+func (gh *gitHub) ListChecks(ctx context.Context, ref string) ([]Check, error) {
+	type checkRun struct {
+		Name       string `json:"name"`
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+		HTMLURL    string `json:"html_url"`
+	}
+	type checkRunsPage struct {
+		CheckRuns []checkRun `json:"check_runs"`
+	}
+	var runs []checkRun
+	ref = url.PathEscape(ref)
+	path := fmt.Sprintf("%s/commits/%s/check-runs?per_page=%d", gh.repoPath, ref, pageSize)
+	err := getPages(ctx, &gh.client, path, func(page checkRunsPage) int {
+		runs = append(runs, page.CheckRuns...)
+		return len(page.CheckRuns)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type commitStatus struct {
+		Context   string `json:"context"`
+		State     string `json:"state"`
+		TargetURL string `json:"target_url"`
+	}
+	type combinedStatusPage struct {
+		Statuses []commitStatus `json:"statuses"`
+	}
+	var statuses []commitStatus
+	path = fmt.Sprintf("%s/commits/%s/status?per_page=%d", gh.repoPath, ref, pageSize)
+	err = getPages(ctx, &gh.client, path, func(page combinedStatusPage) int {
+		statuses = append(statuses, page.Statuses...)
+		return len(page.Statuses)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]Check, 0, len(runs)+len(statuses))
+	for _, run := range runs {
+		checks = append(checks, Check{
+			Name:  run.Name,
+			State: gitHubCheckRunState(run.Status, run.Conclusion),
+			URL:   run.HTMLURL,
+		})
+	}
+	for _, s := range statuses {
+		checks = append(checks, Check{
+			Name:  s.Context,
+			State: gitHubStatusState(s.State),
+			URL:   s.TargetURL,
+		})
+	}
+	return checks, nil
+}
+
+// gitHubCheckRunState maps a check run's status and conclusion to a CheckState
+func gitHubCheckRunState(status, conclusion string) CheckState {
+	switch status {
+	case "completed":
+	case "in_progress":
+		return CheckRunning
+	default:
+		// queued, requested, waiting, pending
+		return CheckPending
+	}
+
+	switch conclusion {
+	case "success":
+		return CheckSuccess
+	case "cancelled":
+		return CheckCancelled
+	case "skipped":
+		return CheckSkipped
+	case "neutral":
+		return CheckNeutral
+	default:
+		// failure, timed_out, action_required, startup_failure, stale
+		return CheckFailure
+	}
+}
+
+// gitHubStatusState maps a commit status state to a CheckState
+func gitHubStatusState(state string) CheckState {
+	switch state {
+	case "success":
+		return CheckSuccess
+	case "pending":
+		return CheckPending
+	default:
+		// failure, error
+		return CheckFailure
+	}
+}
+
+// Merge implements Forge
+func (gh *gitHub) Merge(ctx context.Context, number int, opts *MergeOptions) error {
+	method := MergeMethodMerge
+	if opts != nil && opts.Method != "" {
+		method = opts.Method
+	}
+	in := map[string]interface{}{"merge_method": method}
+	if opts != nil && opts.SHA != "" {
+		in["sha"] = opts.SHA
+	}
+	return gh.do(ctx, http.MethodPut, fmt.Sprintf("%s/pulls/%d/merge", gh.repoPath, number), in, nil)
+}
+
+// RequestReviewers implements Forge
+func (gh *gitHub) RequestReviewers(ctx context.Context, number int, reviewers []string) error {
+	in := map[string]interface{}{"reviewers": reviewers}
+	return gh.do(ctx, http.MethodPost, fmt.Sprintf("%s/pulls/%d/requested_reviewers", gh.repoPath, number), in, nil)
+}
+
+// AddLabels implements Forge. Pull requests share their labels with the issue of the same number.
+func (gh *gitHub) AddLabels(ctx context.Context, number int, labels []string) error {
+	in := map[string]interface{}{"labels": labels}
+	return gh.do(ctx, http.MethodPost, fmt.Sprintf("%s/issues/%d/labels", gh.repoPath, number), in, nil)
+}
+
+// gitHubUser is a user as embedded in API objects
+type gitHubUser struct {
+	Login string `json:"login"`
+}
+
+// gitHubReview is a pull request review as returned by the API, which Gitea shares
+type gitHubReview struct {
+	ID          int64      `json:"id"`
+	User        gitHubUser `json:"user"`
+	State       string     `json:"state"`
+	Body        string     `json:"body"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+}
+
+// ListReviews implements Forge
+func (gh *gitHub) ListReviews(ctx context.Context, number int) ([]Review, error) {
+	out, err := listAll[gitHubReview](ctx, &gh.client, fmt.Sprintf("%s/pulls/%d/reviews?per_page=%d", gh.repoPath, number, pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]Review, 0, len(out))
+	for _, r := range out {
+		reviews = append(reviews, Review{
+			Author:      r.User.Login,
+			State:       gitHubReviewState(r.State),
+			Body:        r.Body,
+			SubmittedAt: r.SubmittedAt,
+		})
+	}
+	return reviews, nil
+}
+
+// gitHubReviewState maps a review state to a ReviewState. Gitea uses the same
+// names except for REQUEST_CHANGES and COMMENT.
+func gitHubReviewState(state string) ReviewState {
+	switch state {
+	case "APPROVED":
+		return ReviewApproved
+	case "CHANGES_REQUESTED", "REQUEST_CHANGES":
+		return ReviewChangesRequested
+	case "DISMISSED":
+		return ReviewDismissed
+	case "PENDING", "REQUEST_REVIEW":
+		return ReviewPending
+	default:
+		// COMMENTED, COMMENT
+		return ReviewCommented
+	}
+}
+
+// gitHubIssueComment is a general pull request comment as returned by the API,
+// which Gitea shares
+type gitHubIssueComment struct {
+	ID        int64      `json:"id"`
+	User      gitHubUser `json:"user"`
+	Body      string     `json:"body"`
+	HTMLURL   string     `json:"html_url"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (c *gitHubIssueComment) toComment() Comment {
+	id := strconv.FormatInt(c.ID, 10)
+	return Comment{
+		ID:        id,
+		ThreadID:  id,
+		Author:    c.User.Login,
+		Body:      c.Body,
+		URL:       c.HTMLURL,
+		CreatedAt: c.CreatedAt,
+	}
+}
+
+// ListComments implements Forge. Comments on the diff come before the general
+// comments of the conversation. Replies on GitHub always point at the first comment
+// of their thread, and outdated comments keep the line they were made on.
+func (gh *gitHub) ListComments(ctx context.Context, number int) ([]Comment, error) {
+	reviewComments, err := listAll[struct {
+		ID           int64      `json:"id"`
+		InReplyToID  int64      `json:"in_reply_to_id"`
+		User         gitHubUser `json:"user"`
+		Body         string     `json:"body"`
+		Path         string     `json:"path"`
+		Line         *int       `json:"line"`
+		OriginalLine *int       `json:"original_line"`
+		HTMLURL      string     `json:"html_url"`
+		CreatedAt    time.Time  `json:"created_at"`
+	}](ctx, &gh.client, fmt.Sprintf("%s/pulls/%d/comments?per_page=%d", gh.repoPath, number, pageSize))
+	if err != nil {
+		return nil, err
+	}
+	issueComments, err := listAll[gitHubIssueComment](ctx, &gh.client, fmt.Sprintf("%s/issues/%d/comments?per_page=%d", gh.repoPath, number, pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]Comment, 0, len(reviewComments)+len(issueComments))
+	for _, c := range reviewComments {
+		thread := c.ID
+		if c.InReplyToID != 0 {
+			thread = c.InReplyToID
+		}
+		line := 0
+		if c.Line != nil {
+			line = *c.Line
+		} else if c.OriginalLine != nil {
+			line = *c.OriginalLine
+		}
+		comments = append(comments, Comment{
+			ID:        strconv.FormatInt(c.ID, 10),
+			ThreadID:  strconv.FormatInt(thread, 10),
+			Author:    c.User.Login,
+			Body:      c.Body,
+			Path:      c.Path,
+			Line:      line,
+			URL:       c.HTMLURL,
+			CreatedAt: c.CreatedAt,
+		})
+	}
+	for _, c := range issueComments {
+		comments = append(comments, c.toComment())
+	}
+	return comments, nil
+}
+
+// gitHubNextPage follows the rel="next" link of the Link header, which is missing
+// on the last page
+func gitHubNextPage(_ string, header http.Header, _ []byte, _ int) (string, error) {
+	for _, link := range strings.Split(strings.Join(header.Values("Link"), ","), ",") {
+		parts := strings.Split(link, ";")
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(parts[0]), "<>"), nil
+			}
+		}
+	}
+	return "", nil
+}
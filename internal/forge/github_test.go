@@ -0,0 +1,246 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestGitHub starts a stand-in GitHub API for owner/repo and returns a forge using it
+func newTestGitHub(t *testing.T, mux *http.ServeMux) Forge {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer gh-secret" {
+			t.Errorf("Authorization = %q, want bearer token", got)
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	f, err := New(Options{Kind: KindGitHub, Host: "github.com", Owner: "owner", Repo: "repo", Token: "gh-secret", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return f
+}
+
+func TestGitHubCreatePR(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if in["title"] != "Add feature" || in["head"] != "awt/agent/1" || in["base"] != "main" || in["body"] != "Body" || in["draft"] != true {
+			t.Errorf("unexpected request body: %v", in)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 42, "html_url": "https://github.com/owner/repo/pull/42", "title": "Add feature",
+			"state": "open", "draft": true, "head": {"ref": "awt/agent/1", "sha": "abc123"}, "base": {"ref": "main"}, "mergeable": null}`))
+	})
+	f := newTestGitHub(t, mux)
+
+	pr, err := f.CreatePR(context.Background(), &PRRequest{Title: "Add feature", Body: "Body", Head: "awt/agent/1", Base: "main", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePR() failed: %v", err)
+	}
+	if pr.Number != 42 || pr.URL != "https://github.com/owner/repo/pull/42" || pr.State != PRStateOpen || !pr.Draft {
+		t.Errorf("unexpected PR: %+v", pr)
+	}
+	if pr.Head != "awt/agent/1" || pr.Base != "main" || pr.HeadSHA != "abc123" || pr.Mergeable != nil {
+		t.Errorf("unexpected PR branches: %+v", pr)
+	}
+}
+
+func TestGitHubGetPRMerged(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/7", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"number": 7, "state": "closed", "merged": true, "mergeable": false}`))
+	})
+	f := newTestGitHub(t, mux)
+
+	pr, err := f.GetPR(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetPR() failed: %v", err)
+	}
+	if pr.State != PRStateMerged {
+		t.Errorf("State = %q, want %q", pr.State, PRStateMerged)
+	}
+	if pr.Mergeable == nil || *pr.Mergeable {
+		t.Errorf("Mergeable = %v, want false", pr.Mergeable)
+	}
+}
+
+func TestGitHubListChecks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/commits/abc123/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"check_runs": [
+			{"name": "build", "status": "completed", "conclusion": "success", "html_url": "https://ci/build"},
+			{"name": "test", "status": "completed", "conclusion": "timed_out"},
+			{"name": "lint", "status": "in_progress"},
+			{"name": "deploy", "status": "queued"}]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/commits/abc123/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"statuses": [{"context": "ci/legacy", "state": "error", "target_url": "https://ci/legacy"}]}`))
+	})
+	f := newTestGitHub(t, mux)
+
+	checks, err := f.ListChecks(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("ListChecks() failed: %v", err)
+	}
+	want := []Check{
+		{Name: "build", State: CheckSuccess, URL: "https://ci/build"},
+		{Name: "test", State: CheckFailure},
+		{Name: "lint", State: CheckRunning},
+		{Name: "deploy", State: CheckPending},
+		{Name: "ci/legacy", State: CheckFailure, URL: "https://ci/legacy"},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("ListChecks() = %+v, want %+v", checks, want)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Errorf("check %d = %+v, want %+v", i, checks[i], want[i])
+		}
+	}
+}
+
+func TestGitHubMerge(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /repos/owner/repo/pulls/42/merge", func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		if in["merge_method"] != "squash" || in["sha"] != "abc123" {
+			t.Errorf("unexpected request body: %v", in)
+		}
+		_, _ = w.Write([]byte(`{"merged": true}`))
+	})
+	f := newTestGitHub(t, mux)
+
+	if err := f.Merge(context.Background(), 42, &MergeOptions{Method: MergeMethodSquash, SHA: "abc123"}); err != nil {
+		t.Fatalf("Merge() failed: %v", err)
+	}
+}
+
+func TestGitHubReviewersAndLabels(t *testing.T) {
+	mux := http.NewServeMux()
+	var gotReviewers, gotLabels []string
+	mux.HandleFunc("POST /repos/owner/repo/pulls/42/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			Reviewers []string `json:"reviewers"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		gotReviewers = in.Reviewers
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 42}`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/issues/42/labels", func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			Labels []string `json:"labels"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		gotLabels = in.Labels
+		_, _ = w.Write([]byte(`[]`))
+	})
+	f := newTestGitHub(t, mux)
+
+	if err := f.RequestReviewers(context.Background(), 42, []string{"alice", "bob"}); err != nil {
+		t.Fatalf("RequestReviewers() failed: %v", err)
+	}
+	if len(gotReviewers) != 2 || gotReviewers[0] != "alice" || gotReviewers[1] != "bob" {
+		t.Errorf("reviewers = %v, want [alice bob]", gotReviewers)
+	}
+	if err := f.AddLabels(context.Background(), 42, []string{"agent"}); err != nil {
+		t.Fatalf("AddLabels() failed: %v", err)
+	}
+	if len(gotLabels) != 1 || gotLabels[0] != "agent" {
+		t.Errorf("labels = %v, want [agent]", gotLabels)
+	}
+}
+
+func TestGitHubListReviewsAndComments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls/42/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id": 1, "user": {"login": "alice"}, "state": "CHANGES_REQUESTED", "body": "Needs tests", "submitted_at": "2025-01-10T12:00:00Z"},
+			{"id": 2, "user": {"login": "bob"}, "state": "COMMENTED"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/42/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id": 10, "user": {"login": "alice"}, "body": "Off by one", "path": "main.go", "line": 12, "original_line": 10,
+				"html_url": "https://github.com/owner/repo/pull/42#discussion_r10", "created_at": "2025-01-10T12:00:00Z"},
+			{"id": 11, "in_reply_to_id": 10, "user": {"login": "carol"}, "body": "Agreed", "path": "main.go", "line": 12},
+			{"id": 12, "user": {"login": "alice"}, "body": "Outdated", "path": "util.go", "line": null, "original_line": 3}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/issues/42/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 20, "user": {"login": "bob"}, "body": "Looks good overall"}]`))
+	})
+	f := newTestGitHub(t, mux)
+
+	reviews, err := f.ListReviews(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("ListReviews() failed: %v", err)
+	}
+	if len(reviews) != 2 || reviews[0].Author != "alice" || reviews[0].State != ReviewChangesRequested || reviews[0].Body != "Needs tests" || reviews[0].SubmittedAt == nil {
+		t.Errorf("unexpected reviews: %+v", reviews)
+	}
+	if reviews[1].State != ReviewCommented || reviews[1].SubmittedAt != nil {
+		t.Errorf("unexpected second review: %+v", reviews[1])
+	}
+
+	comments, err := f.ListComments(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("ListComments() failed: %v", err)
+	}
+	want := []Comment{
+		{ID: "10", ThreadID: "10", Author: "alice", Body: "Off by one", Path: "main.go", Line: 12, URL: "https://github.com/owner/repo/pull/42#discussion_r10"},
+		{ID: "11", ThreadID: "10", Author: "carol", Body: "Agreed", Path: "main.go", Line: 12},
+		{ID: "12", ThreadID: "12", Author: "alice", Body: "Outdated", Path: "util.go", Line: 3},
+		{ID: "20", ThreadID: "20", Author: "bob", Body: "Looks good overall"},
+	}
+	if len(comments) != len(want) {
+		t.Fatalf("got %d comments, want %d: %+v", len(comments), len(want), comments)
+	}
+	for i := range want {
+		got := comments[i]
+		got.CreatedAt = want[i].CreatedAt
+		if got != want[i] {
+			t.Errorf("comment %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestGitHubListPaginates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/issues/42/comments", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("per_page"); got != "100" {
+			t.Errorf("per_page = %q, want 100", got)
+		}
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`[{"id": 21, "user": {"login": "carol"}, "body": "Second page"}]`))
+			return
+		}
+		next := "http://" + r.Host + "/repos/owner/repo/issues/42/comments?per_page=100&page=2"
+		w.Header().Set("Link", `<`+next+`>; rel="next", <`+next+`>; rel="last"`)
+		_, _ = w.Write([]byte(`[{"id": 20, "user": {"login": "bob"}, "body": "First page"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/pulls/42/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	f := newTestGitHub(t, mux)
+
+	comments, err := f.ListComments(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("ListComments() failed: %v", err)
+	}
+	if len(comments) != 2 || comments[0].ID != "20" || comments[1].ID != "21" {
+		t.Errorf("expected the comments of both pages, got %+v", comments)
+	}
+}
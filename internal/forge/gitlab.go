@@ -0,0 +1,310 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// gitLab implements Forge with the GitLab REST API. Pull requests are merge
+// requests there and are numbered by their project-scoped IID.
+type gitLab struct {
+	client
+	// projectPath is /projects/<url-encoded owner/repo>
+	projectPath string
+}
+
+func newGitLab(opts Options) *gitLab {
+	baseURL := opts.APIURL
+	if baseURL == "" {
+		baseURL = opts.webURL() + "/api/v4"
+	}
+
+	token := opts.Token
+	return &gitLab{
+		client: client{
+			baseURL: strings.TrimSuffix(baseURL, "/"),
+			http:    opts.HTTPClient,
+			auth: func(req *http.Request) {
+				req.Header.Set("PRIVATE-TOKEN", token)
+			},
+			nextPage: gitLabNextPage,
+		},
+		projectPath: "/projects/" + url.PathEscape(opts.Owner+"/"+opts.Repo),
+	}
+}
+
+// Kind implements Forge
+func (gl *gitLab) Kind() Kind {
+	return KindGitLab
+}
+
+// gitLabMR is a merge request as returned by the API
+type gitLabMR struct {
+	IID          int    `json:"iid"`
+	WebURL       string `json:"web_url"`
+	Title        string `json:"title"`
+	State        string `json:"state"`
+	Draft        bool   `json:"draft"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	SHA          string `json:"sha"`
+	MergeStatus  string `json:"merge_status"`
+}
+
+func (mr *gitLabMR) toPR() *PR {
+	state := PRStateOpen
+	switch mr.State {
+	case "merged":
+		state = PRStateMerged
+	case "closed", "locked":
+		state = PRStateClosed
+	}
+
+	var mergeable *bool
+	switch mr.MergeStatus {
+	case "can_be_merged":
+		v := true
+		mergeable = &v
+	case "cannot_be_merged", "cannot_be_merged_recheck":
+		v := false
+		mergeable = &v
+	}
+
+	return &PR{
+		Number:    mr.IID,
+		URL:       mr.WebURL,
+		Title:     mr.Title,
+		State:     state,
+		Draft:     mr.Draft,
+		Head:      mr.SourceBranch,
+		Base:      mr.TargetBranch,
+		HeadSHA:   mr.SHA,
+		Mergeable: mergeable,
+	}
+}
+
+// CreatePR implements Forge
+func (gl *gitLab) CreatePR(ctx context.Context, req *PRRequest) (*PR, error) {
+	title := req.Title
+	if req.Draft {
+		// GitLab marks merge requests as drafts by their title
+		title = "Draft: " + title
+	}
+	in := map[string]interface{}{
+		"title":         title,
+		"description":   req.Body,
+		"source_branch": req.Head,
+		"target_branch": req.Base,
+	}
+	var out gitLabMR
+	if err := gl.do(ctx, http.MethodPost, gl.projectPath+"/merge_requests", in, &out); err != nil {
+		return nil, err
+	}
+	return out.toPR(), nil
+}
+
+// GetPR implements Forge
+func (gl *gitLab) GetPR(ctx context.Context, number int) (*PR, error) {
+	var out gitLabMR
+	if err := gl.do(ctx, http.MethodGet, fmt.Sprintf("%s/merge_requests/%d", gl.projectPath, number), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.toPR(), nil
+}
+
+// ListChecks implements Forge using the commit statuses set by pipelines and
+// external CI
+func (gl *gitLab) ListChecks(ctx context.Context, ref string) ([]Check, error) {
+	path := fmt.Sprintf("%s/repository/commits/%s/statuses?per_page=%d", gl.projectPath, url.PathEscape(ref), pageSize)
+	statuses, err := listAll[struct {
+		Name      string `json:"name"`
+		Status    string `json:"status"`
+		TargetURL string `json:"target_url"`
+	}](ctx, &gl.client, path)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]Check, 0, len(statuses))
+	for _, s := range statuses {
+		checks = append(checks, Check{
+			Name:  s.Name,
+			State: gitLabStatusState(s.Status),
+			URL:   s.TargetURL,
+		})
+	}
+	return checks, nil
+}
+
+// gitLabStatusState maps a commit status to a CheckState
+func gitLabStatusState(status string) CheckState {
+	switch status {
+	case "running":
+		return CheckRunning
+	case "success":
+		return CheckSuccess
+	case "failed":
+		return CheckFailure
+	case "canceled":
+		return CheckCancelled
+	case "skipped":
+		return CheckSkipped
+	default:
+		// created, waiting_for_resource, preparing, pending, scheduled, manual
+		return CheckPending
+	}
+}
+
+// Merge implements Forge. GitLab applies the project's merge method, so only
+// MergeMethodMerge and MergeMethodSquash can be requested.
+func (gl *gitLab) Merge(ctx context.Context, number int, opts *MergeOptions) error {
+	in := map[string]interface{}{}
+	if opts != nil {
+		switch opts.Method {
+		case "", MergeMethodMerge:
+		case MergeMethodSquash:
+			in["squash"] = true
+		default:
+			return fmt.Errorf("merge method %q is not supported by GitLab", opts.Method)
+		}
+		if opts.SHA != "" {
+			in["sha"] = opts.SHA
+		}
+	}
+	return gl.do(ctx, http.MethodPut, fmt.Sprintf("%s/merge_requests/%d/merge", gl.projectPath, number), in, nil)
+}
+
+// RequestReviewers implements Forge. GitLab assigns reviewers by user ID, so the
+// usernames are looked up first; reviewers already on the merge request are kept.
+func (gl *gitLab) RequestReviewers(ctx context.Context, number int, reviewers []string) error {
+	var mr struct {
+		Reviewers []struct {
+			ID int `json:"id"`
+		} `json:"reviewers"`
+	}
+	mrPath := fmt.Sprintf("%s/merge_requests/%d", gl.projectPath, number)
+	if err := gl.do(ctx, http.MethodGet, mrPath, nil, &mr); err != nil {
+		return err
+	}
+	ids := make([]int, 0, len(mr.Reviewers)+len(reviewers))
+	for _, r := range mr.Reviewers {
+		ids = append(ids, r.ID)
+	}
+
+	for _, username := range reviewers {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		if err := gl.do(ctx, http.MethodGet, "/users?username="+url.QueryEscape(username), nil, &users); err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return fmt.Errorf("no GitLab user %q", username)
+		}
+		ids = append(ids, users[0].ID)
+	}
+
+	in := map[string]interface{}{"reviewer_ids": ids}
+	return gl.do(ctx, http.MethodPut, mrPath, in, nil)
+}
+
+// AddLabels implements Forge. GitLab creates labels that do not exist yet.
+func (gl *gitLab) AddLabels(ctx context.Context, number int, labels []string) error {
+	in := map[string]interface{}{"add_labels": strings.Join(labels, ",")}
+	return gl.do(ctx, http.MethodPut, fmt.Sprintf("%s/merge_requests/%d", gl.projectPath, number), in, nil)
+}
+
+// ListReviews implements Forge. GitLab only records approvals, so each approver
+// is returned as an approved review.
+func (gl *gitLab) ListReviews(ctx context.Context, number int) ([]Review, error) {
+	var approvals struct {
+		ApprovedBy []struct {
+			User struct {
+				Username string `json:"username"`
+			} `json:"user"`
+		} `json:"approved_by"`
+	}
+	if err := gl.do(ctx, http.MethodGet, fmt.Sprintf("%s/merge_requests/%d/approvals", gl.projectPath, number), nil, &approvals); err != nil {
+		return nil, err
+	}
+
+	reviews := make([]Review, 0, len(approvals.ApprovedBy))
+	for _, a := range approvals.ApprovedBy {
+		reviews = append(reviews, Review{Author: a.User.Username, State: ReviewApproved})
+	}
+	return reviews, nil
+}
+
+// ListComments implements Forge using the merge request's discussions. System
+// notes (such as "added 1 commit") are left out.
+func (gl *gitLab) ListComments(ctx context.Context, number int) ([]Comment, error) {
+	discussions, err := listAll[struct {
+		ID    string `json:"id"`
+		Notes []struct {
+			ID     int    `json:"id"`
+			Body   string `json:"body"`
+			System bool   `json:"system"`
+			Author struct {
+				Username string `json:"username"`
+			} `json:"author"`
+			CreatedAt time.Time `json:"created_at"`
+			Resolved  bool      `json:"resolved"`
+			Position  *struct {
+				NewPath string `json:"new_path"`
+				OldPath string `json:"old_path"`
+				NewLine *int   `json:"new_line"`
+				OldLine *int   `json:"old_line"`
+			} `json:"position"`
+		} `json:"notes"`
+	}](ctx, &gl.client, fmt.Sprintf("%s/merge_requests/%d/discussions?per_page=%d", gl.projectPath, number, pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []Comment
+	for _, d := range discussions {
+		// Replies carry no position, so they take the thread's
+		path, line := "", 0
+		for _, n := range d.Notes {
+			if n.System {
+				continue
+			}
+			if p := n.Position; p != nil {
+				path = p.NewPath
+				if path == "" {
+					path = p.OldPath
+				}
+				if p.NewLine != nil {
+					line = *p.NewLine
+				} else if p.OldLine != nil {
+					line = *p.OldLine
+				}
+			}
+			comments = append(comments, Comment{
+				ID:        fmt.Sprintf("%d", n.ID),
+				ThreadID:  d.ID,
+				Author:    n.Author.Username,
+				Body:      n.Body,
+				Path:      path,
+				Line:      line,
+				Resolved:  n.Resolved,
+				CreatedAt: n.CreatedAt,
+			})
+		}
+	}
+	return comments, nil
+}
+
+// gitLabNextPage requests the page named by the X-Next-Page header, which is empty
+// on the last page
+func gitLabNextPage(reqURL string, header http.Header, _ []byte, _ int) (string, error) {
+	next := header.Get("X-Next-Page")
+	if next == "" {
+		return "", nil
+	}
+	return withQuery(reqURL, "page", next)
+}
@@ -0,0 +1,247 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestGitLab starts a stand-in GitLab API for group/project and returns a forge using it
+func newTestGitLab(t *testing.T, mux *http.ServeMux) Forge {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "gl-secret" {
+			t.Errorf("PRIVATE-TOKEN = %q, want the token", got)
+		}
+		// The project ID is the URL-encoded path
+		if r.URL.Path != "/users" && !strings.HasPrefix(r.URL.EscapedPath(), "/projects/group%2Fproject/") {
+			t.Errorf("path %q does not start with the encoded project ID", r.URL.EscapedPath())
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	f, err := New(Options{Kind: KindGitLab, Host: "gitlab.com", Owner: "group", Repo: "project", Token: "gl-secret", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return f
+}
+
+func TestGitLabCreatePR(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /projects/group%2Fproject/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if in["title"] != "Draft: Add feature" || in["source_branch"] != "awt/agent/1" || in["target_branch"] != "main" || in["description"] != "Body" {
+			t.Errorf("unexpected request body: %v", in)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"iid": 3, "web_url": "https://gitlab.com/group/project/-/merge_requests/3", "title": "Draft: Add feature",
+			"state": "opened", "draft": true, "source_branch": "awt/agent/1", "target_branch": "main", "sha": "abc123", "merge_status": "checking"}`))
+	})
+	f := newTestGitLab(t, mux)
+
+	pr, err := f.CreatePR(context.Background(), &PRRequest{Title: "Add feature", Body: "Body", Head: "awt/agent/1", Base: "main", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePR() failed: %v", err)
+	}
+	if pr.Number != 3 || pr.URL != "https://gitlab.com/group/project/-/merge_requests/3" || pr.State != PRStateOpen || !pr.Draft {
+		t.Errorf("unexpected PR: %+v", pr)
+	}
+	if pr.Head != "awt/agent/1" || pr.Base != "main" || pr.HeadSHA != "abc123" || pr.Mergeable != nil {
+		t.Errorf("unexpected PR branches: %+v", pr)
+	}
+}
+
+func TestGitLabGetPR(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/group%2Fproject/merge_requests/3", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"iid": 3, "state": "merged", "merge_status": "can_be_merged"}`))
+	})
+	f := newTestGitLab(t, mux)
+
+	pr, err := f.GetPR(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("GetPR() failed: %v", err)
+	}
+	if pr.State != PRStateMerged {
+		t.Errorf("State = %q, want %q", pr.State, PRStateMerged)
+	}
+	if pr.Mergeable == nil || !*pr.Mergeable {
+		t.Errorf("Mergeable = %v, want true", pr.Mergeable)
+	}
+}
+
+func TestGitLabListChecks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/group%2Fproject/repository/commits/abc123/statuses", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"name": "build", "status": "success", "target_url": "https://ci/build"},
+			{"name": "test", "status": "failed"},
+			{"name": "lint", "status": "running"},
+			{"name": "deploy", "status": "manual"}]`))
+	})
+	f := newTestGitLab(t, mux)
+
+	checks, err := f.ListChecks(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("ListChecks() failed: %v", err)
+	}
+	want := []Check{
+		{Name: "build", State: CheckSuccess, URL: "https://ci/build"},
+		{Name: "test", State: CheckFailure},
+		{Name: "lint", State: CheckRunning},
+		{Name: "deploy", State: CheckPending},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("ListChecks() = %+v, want %+v", checks, want)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Errorf("check %d = %+v, want %+v", i, checks[i], want[i])
+		}
+	}
+}
+
+func TestGitLabMerge(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /projects/group%2Fproject/merge_requests/3/merge", func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		if in["squash"] != true {
+			t.Errorf("unexpected request body: %v", in)
+		}
+		_, _ = w.Write([]byte(`{"iid": 3, "state": "merged"}`))
+	})
+	f := newTestGitLab(t, mux)
+
+	if err := f.Merge(context.Background(), 3, &MergeOptions{Method: MergeMethodSquash}); err != nil {
+		t.Fatalf("Merge() failed: %v", err)
+	}
+	if err := f.Merge(context.Background(), 3, &MergeOptions{Method: MergeMethodRebase}); err == nil {
+		t.Error("Merge() with the rebase method should fail on GitLab")
+	}
+}
+
+func TestGitLabReviewersAndLabels(t *testing.T) {
+	mux := http.NewServeMux()
+	var updates []map[string]interface{}
+	mux.HandleFunc("GET /projects/group%2Fproject/merge_requests/3", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"iid": 3, "reviewers": [{"id": 7}]}`))
+	})
+	mux.HandleFunc("GET /users", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("username") == "alice" {
+			_, _ = w.Write([]byte(`[{"id": 11, "username": "alice"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("PUT /projects/group%2Fproject/merge_requests/3", func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		updates = append(updates, in)
+		_, _ = w.Write([]byte(`{"iid": 3}`))
+	})
+	f := newTestGitLab(t, mux)
+
+	if err := f.RequestReviewers(context.Background(), 3, []string{"alice"}); err != nil {
+		t.Fatalf("RequestReviewers() failed: %v", err)
+	}
+	if err := f.RequestReviewers(context.Background(), 3, []string{"nobody"}); err == nil {
+		t.Error("RequestReviewers() with an unknown user should fail")
+	}
+	if err := f.AddLabels(context.Background(), 3, []string{"agent", "needs-review"}); err != nil {
+		t.Fatalf("AddLabels() failed: %v", err)
+	}
+
+	if len(updates) != 2 {
+		t.Fatalf("got %d updates, want 2", len(updates))
+	}
+	// Existing reviewers are kept
+	ids, _ := updates[0]["reviewer_ids"].([]interface{})
+	if len(ids) != 2 || ids[0] != float64(7) || ids[1] != float64(11) {
+		t.Errorf("reviewer_ids = %v, want [7 11]", updates[0]["reviewer_ids"])
+	}
+	if updates[1]["add_labels"] != "agent,needs-review" {
+		t.Errorf("add_labels = %v, want agent,needs-review", updates[1]["add_labels"])
+	}
+}
+
+func TestGitLabListReviewsAndComments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/group%2Fproject/merge_requests/3/approvals", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"approved": true, "approved_by": [{"user": {"username": "alice"}}]}`))
+	})
+	mux.HandleFunc("GET /projects/group%2Fproject/merge_requests/3/discussions", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id": "d1", "notes": [
+				{"id": 1, "body": "Off by one", "author": {"username": "alice"}, "resolved": false,
+					"position": {"new_path": "main.go", "old_path": "main.go", "new_line": 12, "old_line": null}},
+				{"id": 2, "body": "Fixed", "author": {"username": "agent"}}]},
+			{"id": "d2", "notes": [{"id": 3, "body": "added 1 commit", "system": true, "author": {"username": "agent"}}]},
+			{"id": "d3", "notes": [{"id": 4, "body": "Removed line", "author": {"username": "bob"}, "resolved": true,
+				"position": {"new_path": "util.go", "old_path": "util.go", "new_line": null, "old_line": 7}}]},
+			{"id": "d4", "notes": [{"id": 5, "body": "Thanks!", "author": {"username": "bob"}}]}]`))
+	})
+	f := newTestGitLab(t, mux)
+
+	reviews, err := f.ListReviews(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("ListReviews() failed: %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].Author != "alice" || reviews[0].State != ReviewApproved {
+		t.Errorf("unexpected reviews: %+v", reviews)
+	}
+
+	comments, err := f.ListComments(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("ListComments() failed: %v", err)
+	}
+	want := []Comment{
+		{ID: "1", ThreadID: "d1", Author: "alice", Body: "Off by one", Path: "main.go", Line: 12},
+		{ID: "2", ThreadID: "d1", Author: "agent", Body: "Fixed", Path: "main.go", Line: 12},
+		{ID: "4", ThreadID: "d3", Author: "bob", Body: "Removed line", Path: "util.go", Line: 7, Resolved: true},
+		{ID: "5", ThreadID: "d4", Author: "bob", Body: "Thanks!"},
+	}
+	if len(comments) != len(want) {
+		t.Fatalf("got %d comments, want %d: %+v", len(comments), len(want), comments)
+	}
+	for i := range want {
+		if comments[i] != want[i] {
+			t.Errorf("comment %d = %+v, want %+v", i, comments[i], want[i])
+		}
+	}
+}
+
+func TestGitLabListPaginates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/group%2Fproject/merge_requests/3/discussions", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("per_page"); got != "100" {
+			t.Errorf("per_page = %q, want 100", got)
+		}
+		if r.URL.Query().Get("page") == "2" {
+			w.Header().Set("X-Next-Page", "")
+			_, _ = w.Write([]byte(`[{"id": "d2", "notes": [{"id": 2, "body": "Second page", "author": {"username": "bob"}}]}]`))
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		_, _ = w.Write([]byte(`[{"id": "d1", "notes": [{"id": 1, "body": "First page", "author": {"username": "alice"}}]}]`))
+	})
+	f := newTestGitLab(t, mux)
+
+	comments, err := f.ListComments(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("ListComments() failed: %v", err)
+	}
+	if len(comments) != 2 || comments[0].ThreadID != "d1" || comments[1].ThreadID != "d2" {
+		t.Errorf("expected the comments of both pages, got %+v", comments)
+	}
+}
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RefCommit describes a ref pointing at a commit
+type RefCommit struct {
+	// Ref is the full ref name
+	Ref string
+	// Commit is the commit the ref points at
+	Commit string
+	// Created is the commit's committer date
+	Created time.Time
+	// Subject is the first line of the commit message
+	Subject string
+}
+
+// ListRefCommits returns the refs under prefix with their commit date and subject, sorted by ref name
+func (g *Git) ListRefCommits(ctx context.Context, prefix string) ([]RefCommit, error) {
+	result, err := g.run(ctx, "for-each-ref", "--format=%(refname)%00%(objectname)%00%(committerdate:iso-strict)%00%(contents:subject)", prefix)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("git for-each-ref failed: %s", result.Stderr)
+	}
+
+	refs := []RefCommit{}
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		created, _ := time.Parse(time.RFC3339, fields[2])
+		refs = append(refs, RefCommit{Ref: fields[0], Commit: fields[1], Created: created, Subject: fields[3]})
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Ref < refs[j].Ref
+	})
+	return refs, nil
+}
+
+// SnapshotWorktree records the full worktree, including untracked (but not ignored) files,
+// as a commit whose parent is HEAD. A temporary index is used, so neither the index nor
+// any branch is touched. Returns the commit ID.
+func (g *Git) SnapshotWorktree(ctx context.Context, message string) (string, error) {
+	indexFile, cleanup, err := g.tempIndex(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	tg := g.withEnv("GIT_INDEX_FILE=" + indexFile)
+
+	if result, err := tg.run(ctx, "add", "-A"); err != nil || result.ExitCode != 0 {
+		return "", fmt.Errorf("failed to stage worktree snapshot: %s", stderrOf(result, err))
+	}
+
+	tree, err := tg.run(ctx, "write-tree")
+	if err != nil || tree.ExitCode != 0 {
+		return "", fmt.Errorf("failed to write snapshot tree: %s", stderrOf(tree, err))
+	}
+
+	args := []string{"commit-tree", tree.Stdout, "-m", message}
+	if head, err := g.RevParse(ctx, "HEAD"); err == nil && head != "" {
+		args = append(args, "-p", head)
+	}
+	commit, err := g.run(ctx, args...)
+	if err != nil || commit.ExitCode != 0 {
+		return "", fmt.Errorf("failed to create snapshot commit: %s", stderrOf(commit, err))
+	}
+	return commit.Stdout, nil
+}
+
+// RestoreWorktree makes the worktree match the tree of commit: changed files are
+// overwritten and files that are not in it are removed (ignored files are left alone).
+// HEAD does not move; the index is reset to HEAD, so restored changes are unstaged.
+func (g *Git) RestoreWorktree(ctx context.Context, commit string) error {
+	indexFile, cleanup, err := g.tempIndex(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	tg := g.withEnv("GIT_INDEX_FILE=" + indexFile)
+
+	// Track every current file in the temporary index so read-tree removes those not in the target
+	if result, err := tg.run(ctx, "add", "-A"); err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to scan worktree: %s", stderrOf(result, err))
+	}
+	if result, err := tg.run(ctx, "read-tree", "--reset", "-u", commit+"^{tree}"); err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to restore worktree: %s", stderrOf(result, err))
+	}
+
+	if result, err := g.run(ctx, "reset", "-q"); err != nil || result.ExitCode != 0 {
+		return fmt.Errorf("failed to reset index: %s", stderrOf(result, err))
+	}
+	return nil
+}
+
+// tempIndex creates a temporary index file for the worktree, seeded with a copy of the
+// real index so unchanged files need not be rehashed. The caller must call cleanup.
+func (g *Git) tempIndex(ctx context.Context) (path string, cleanup func(), err error) {
+	f, err := os.CreateTemp("", "awt-index-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	path = f.Name()
+	cleanup = func() {
+		_ = os.Remove(path)
+	}
+
+	if src, err := g.gitPath(ctx, "index"); err == nil {
+		if in, err := os.Open(src); err == nil {
+			_, err = io.Copy(f, in)
+			_ = in.Close()
+			if err != nil {
+				_ = f.Close()
+				cleanup()
+				return "", nil, fmt.Errorf("failed to copy index: %w", err)
+			}
+			_ = f.Close()
+			return path, cleanup, nil
+		}
+	}
+
+	// Git rejects an empty index file, so start without one
+	_ = f.Close()
+	_ = os.Remove(path)
+	return path, cleanup, nil
+}
+
+// gitPath resolves a path inside the worktree's git directory (git rev-parse --git-path)
+func (g *Git) gitPath(ctx context.Context, name string) (string, error) {
+	result, err := g.run(ctx, "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("git rev-parse --git-path failed: %s", result.Stderr)
+	}
+	path := result.Stdout
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(g.workTreeRoot, path)
+	}
+	return path, nil
+}
+
+// stderrOf describes why a git command failed
+func stderrOf(result *Result, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return result.Stderr
+}
@@ -2,12 +2,18 @@ package git
 
 import (
 	"bytes"
+	"context"
+	stderrors "errors"
 	"fmt"
 	"net/url"
+	"os"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/kernel-labs-ai/awt/internal/errors"
 	"github.com/kernel-labs-ai/awt/internal/logger"
 )
 
@@ -17,8 +23,28 @@ type Git struct {
 	workTreeRoot string
 	// verbose enables command logging
 	verbose bool
+	// env holds extra environment variables for git commands (e.g. GIT_INDEX_FILE)
+	env []string
+	// timeouts bounds the git commands that talk to remotes or rewrite history
+	timeouts Timeouts
 }
 
+// Timeouts bounds long-running git commands; a zero duration means no limit.
+// When a timeout fires the git process is interrupted (then killed if it does not
+// exit) and a GIT_TIMEOUT error is returned.
+type Timeouts struct {
+	// Fetch bounds Fetch and FetchUnshallow
+	Fetch time.Duration
+	// Push bounds Push, PushRefs and DeleteRemoteBranch
+	Push time.Duration
+	// Rebase bounds Rebase and Merge
+	Rebase time.Duration
+}
+
+// killDelay is how long a canceled git process gets to exit after being interrupted
+// before it is killed
+const killDelay = 5 * time.Second
+
 // New creates a new Git wrapper
 func New(workTreeRoot string, verbose bool) *Git {
 	return &Git{
@@ -27,6 +53,20 @@ func New(workTreeRoot string, verbose bool) *Git {
 	}
 }
 
+// withEnv returns a copy of the wrapper that adds the given KEY=value variables to every git command
+func (g *Git) withEnv(env ...string) *Git {
+	copied := *g
+	copied.env = append(append([]string{}, g.env...), env...)
+	return &copied
+}
+
+// WithTimeouts returns a copy of the wrapper that applies the given timeouts
+func (g *Git) WithTimeouts(timeouts Timeouts) *Git {
+	copied := *g
+	copied.timeouts = timeouts
+	return &copied
+}
+
 // Result represents the result of a Git command execution
 type Result struct {
 	Stdout   string
@@ -35,7 +75,12 @@ type Result struct {
 }
 
 // run executes a git command with -C workTreeRoot
-func (g *Git) run(args ...string) (*Result, error) {
+func (g *Git) run(ctx context.Context, args ...string) (*Result, error) {
+	return g.runWithInput(ctx, "", args...)
+}
+
+// runWithInput executes a git command with -C workTreeRoot, feeding input to its stdin
+func (g *Git) runWithInput(ctx context.Context, input string, args ...string) (*Result, error) {
 	// Prepend -C workTreeRoot to run from the worktree root
 	fullArgs := append([]string{"-C", g.workTreeRoot}, args...)
 
@@ -43,7 +88,14 @@ func (g *Git) run(args ...string) (*Result, error) {
 		logger.Debug("git %s", strings.Join(fullArgs, " "))
 	}
 
-	cmd := exec.Command("git", fullArgs...)
+	cmd := exec.CommandContext(ctx, "git", fullArgs...)
+	interruptOnCancel(cmd)
+	if len(g.env) > 0 {
+		cmd.Env = append(os.Environ(), g.env...)
+	}
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -58,6 +110,11 @@ func (g *Git) run(args ...string) (*Result, error) {
 	}
 
 	if err != nil {
+		if ctx.Err() != nil {
+			// The process was stopped, so its exit status says nothing about the command
+			result.ExitCode = -1
+			return result, fmt.Errorf("git %s: %w", args[0], ctx.Err())
+		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			result.ExitCode = exitErr.ExitCode()
 		} else {
@@ -68,66 +125,64 @@ func (g *Git) run(args ...string) (*Result, error) {
 	return result, nil
 }
 
-// runExternal executes a non-git command (e.g., gh, glab) with the working directory set to the worktree root
-func (g *Git) runExternal(name string, args ...string) (*Result, error) {
-	if g.verbose {
-		logger.Debug("%s %s", name, strings.Join(args, " "))
+// runTimed runs a git command under a timeout taken from the given setting, returning
+// a GIT_TIMEOUT error if it runs out
+func (g *Git) runTimed(ctx context.Context, timeout time.Duration, setting string, args ...string) (*Result, error) {
+	if timeout <= 0 {
+		return g.run(ctx, args...)
 	}
 
-	cmd := exec.Command(name, args...)
-	cmd.Dir = g.workTreeRoot
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
+	timedCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	result := &Result{
-		Stdout:   strings.TrimSpace(stdout.String()),
-		Stderr:   strings.TrimSpace(stderr.String()),
-		ExitCode: 0,
+	result, err := g.run(timedCtx, args...)
+	if err != nil && ctx.Err() == nil && timedCtx.Err() == context.DeadlineExceeded {
+		return result, errors.GitTimeout(args[0], setting, timeout)
 	}
+	return result, err
+}
 
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			result.ExitCode = exitErr.ExitCode()
-		} else {
-			return result, fmt.Errorf("failed to execute %s: %w", name, err)
+// interruptOnCancel makes a canceled command get an interrupt first, so git can clean
+// up its lock files, and only be killed if it has not exited after killDelay
+func interruptOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			// Interrupts are not supported on every platform
+			return cmd.Process.Kill()
 		}
+		return nil
 	}
-
-	return result, nil
+	cmd.WaitDelay = killDelay
 }
 
 // WorktreeAdd creates a new worktree with a new branch
-func (g *Git) WorktreeAdd(path, branch, baseBranch string) (*Result, error) {
-	return g.run("worktree", "add", "-b", branch, path, baseBranch)
+func (g *Git) WorktreeAdd(ctx context.Context, path, branch, baseBranch string) (*Result, error) {
+	return g.run(ctx, "worktree", "add", "-b", branch, path, baseBranch)
 }
 
 // WorktreeAddExisting creates a worktree for an existing branch
-func (g *Git) WorktreeAddExisting(path, branch string) (*Result, error) {
-	return g.run("worktree", "add", path, branch)
+func (g *Git) WorktreeAddExisting(ctx context.Context, path, branch string) (*Result, error) {
+	return g.run(ctx, "worktree", "add", path, branch)
 }
 
 // WorktreeRemove removes a worktree
-func (g *Git) WorktreeRemove(path string, force bool) (*Result, error) {
+func (g *Git) WorktreeRemove(ctx context.Context, path string, force bool) (*Result, error) {
 	args := []string{"worktree", "remove"}
 	if force {
 		args = append(args, "--force")
 	}
 	args = append(args, path)
-	return g.run(args...)
+	return g.run(ctx, args...)
 }
 
 // WorktreePrune prunes worktree information
-func (g *Git) WorktreePrune() (*Result, error) {
-	return g.run("worktree", "prune")
+func (g *Git) WorktreePrune(ctx context.Context) (*Result, error) {
+	return g.run(ctx, "worktree", "prune")
 }
 
 // WorktreeList lists all worktrees
-func (g *Git) WorktreeList() ([]*Worktree, error) {
-	result, err := g.run("worktree", "list", "--porcelain")
+func (g *Git) WorktreeList(ctx context.Context) ([]*Worktree, error) {
+	result, err := g.run(ctx, "worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, err
 	}
@@ -181,7 +236,7 @@ func parseWorktreeList(output string) []*Worktree {
 }
 
 // Fetch fetches from remote
-func (g *Git) Fetch(remote string, refspec string) (*Result, error) {
+func (g *Git) Fetch(ctx context.Context, remote string, refspec string) (*Result, error) {
 	args := []string{"fetch"}
 	if remote != "" {
 		args = append(args, remote)
@@ -189,58 +244,106 @@ func (g *Git) Fetch(remote string, refspec string) (*Result, error) {
 			args = append(args, refspec)
 		}
 	}
-	return g.run(args...)
+	return g.runTimed(ctx, g.timeouts.Fetch, "fetch_timeout", args...)
 }
 
 // FetchUnshallow converts a shallow clone to a full clone
-func (g *Git) FetchUnshallow() (*Result, error) {
-	return g.run("fetch", "--unshallow")
+func (g *Git) FetchUnshallow(ctx context.Context) (*Result, error) {
+	return g.runTimed(ctx, g.timeouts.Fetch, "fetch_timeout", "fetch", "--unshallow")
 }
 
 // SubmoduleUpdate updates submodules
-func (g *Git) SubmoduleUpdate() (*Result, error) {
-	return g.run("submodule", "update", "--init", "--recursive")
+func (g *Git) SubmoduleUpdate(ctx context.Context) (*Result, error) {
+	return g.run(ctx, "submodule", "update", "--init", "--recursive")
 }
 
 // Rebase performs a rebase
-func (g *Git) Rebase(branch string) (*Result, error) {
-	return g.run("rebase", branch)
+func (g *Git) Rebase(ctx context.Context, branch string) (*Result, error) {
+	return g.runTimed(ctx, g.timeouts.Rebase, "rebase_timeout", "rebase", branch)
 }
 
 // Merge performs a merge
-func (g *Git) Merge(branch string) (*Result, error) {
-	return g.run("merge", branch)
+func (g *Git) Merge(ctx context.Context, branch string) (*Result, error) {
+	return g.runTimed(ctx, g.timeouts.Rebase, "rebase_timeout", "merge", branch)
 }
 
 // Switch switches to a branch or detaches HEAD
-func (g *Git) Switch(ref string, detach bool) (*Result, error) {
+func (g *Git) Switch(ctx context.Context, ref string, detach bool) (*Result, error) {
 	args := []string{"switch"}
 	if detach {
 		args = append(args, "--detach")
 	}
 	args = append(args, ref)
-	return g.run(args...)
+	return g.run(ctx, args...)
 }
 
 // SwitchInWorktree switches branches in a specific worktree
-func (g *Git) SwitchInWorktree(worktreePath, ref string, detach bool) (*Result, error) {
+func (g *Git) SwitchInWorktree(ctx context.Context, worktreePath, ref string, detach bool) (*Result, error) {
 	// Create a new Git instance for the worktree
 	wtGit := New(worktreePath, g.verbose)
-	return wtGit.Switch(ref, detach)
+	return wtGit.Switch(ctx, ref, detach)
+}
+
+// Reset moves HEAD, and the branch it points at, to ref.
+// mode is a git reset mode such as "--keep" or "--soft".
+func (g *Git) Reset(ctx context.Context, mode, ref string) (*Result, error) {
+	return g.run(ctx, "reset", mode, ref)
+}
+
+// OperationInProgress returns "rebase" or "merge" if the worktree has an interrupted
+// rebase or merge (e.g. stopped on conflicts), or "" if there is none
+func (g *Git) OperationInProgress(ctx context.Context) (string, error) {
+	checks := []struct {
+		path string
+		op   string
+	}{
+		{"rebase-merge", "rebase"},
+		{"rebase-apply", "rebase"},
+		{"MERGE_HEAD", "merge"},
+	}
+	for _, c := range checks {
+		path, err := g.gitPath(ctx, c.path)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(path); err == nil {
+			return c.op, nil
+		}
+	}
+	return "", nil
+}
+
+// AbortOperation aborts an interrupted rebase or merge (see OperationInProgress)
+func (g *Git) AbortOperation(ctx context.Context, op string) (*Result, error) {
+	return g.run(ctx, op, "--abort")
 }
 
 // BranchExists checks if a branch exists
-func (g *Git) BranchExists(branch string) (bool, error) {
-	result, err := g.run("rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+func (g *Git) BranchExists(ctx context.Context, branch string) (bool, error) {
+	result, err := g.run(ctx, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
 	if err != nil {
 		return false, err
 	}
 	return result.ExitCode == 0, nil
 }
 
+// DeleteBranch deletes a local branch
+func (g *Git) DeleteBranch(ctx context.Context, branch string, force bool) (*Result, error) {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	return g.run(ctx, "branch", flag, branch)
+}
+
+// DeleteRemoteBranch deletes a branch on the remote
+func (g *Git) DeleteRemoteBranch(ctx context.Context, remote, branch string) (*Result, error) {
+	return g.runTimed(ctx, g.timeouts.Push, "push_timeout", "push", remote, "--delete", branch)
+}
+
 // IsBranchCheckedOut checks if a branch is checked out in any worktree
-func (g *Git) IsBranchCheckedOut(branch string) (bool, string, error) {
-	worktrees, err := g.WorktreeList()
+func (g *Git) IsBranchCheckedOut(ctx context.Context, branch string) (bool, string, error) {
+	worktrees, err := g.WorktreeList(ctx)
 	if err != nil {
 		return false, "", err
 	}
@@ -254,13 +357,13 @@ func (g *Git) IsBranchCheckedOut(branch string) (bool, string, error) {
 	return false, "", nil
 }
 
-// Add stages files
-func (g *Git) Add(pathspec string) (*Result, error) {
-	return g.run("add", pathspec)
+// Add stages the files matching the pathspecs, which may use pathspec magic such as :(exclude)
+func (g *Git) Add(ctx context.Context, pathspecs ...string) (*Result, error) {
+	return g.run(ctx, append([]string{"add", "--"}, pathspecs...)...)
 }
 
 // Commit creates a commit
-func (g *Git) Commit(message string, all bool, signoff bool, gpgSign bool) (*Result, error) {
+func (g *Git) Commit(ctx context.Context, message string, all bool, signoff bool, gpgSign bool) (*Result, error) {
 	args := []string{"commit", "-m", message}
 	if all {
 		args = append(args, "--all")
@@ -271,11 +374,11 @@ func (g *Git) Commit(message string, all bool, signoff bool, gpgSign bool) (*Res
 	if gpgSign {
 		args = append(args, "--gpg-sign")
 	}
-	return g.run(args...)
+	return g.run(ctx, args...)
 }
 
 // Push pushes to remote
-func (g *Git) Push(remote, branch string, setUpstream bool, force bool) (*Result, error) {
+func (g *Git) Push(ctx context.Context, remote, branch string, setUpstream bool, force bool) (*Result, error) {
 	args := []string{"push"}
 	if setUpstream {
 		args = append(args, "-u")
@@ -284,12 +387,12 @@ func (g *Git) Push(remote, branch string, setUpstream bool, force bool) (*Result
 		args = append(args, "--force")
 	}
 	args = append(args, remote, branch)
-	return g.run(args...)
+	return g.runTimed(ctx, g.timeouts.Push, "push_timeout", args...)
 }
 
 // RevParse runs git rev-parse
-func (g *Git) RevParse(ref string) (string, error) {
-	result, err := g.run("rev-parse", ref)
+func (g *Git) RevParse(ctx context.Context, ref string) (string, error) {
+	result, err := g.run(ctx, "rev-parse", ref)
 	if err != nil {
 		return "", err
 	}
@@ -299,24 +402,290 @@ func (g *Git) RevParse(ref string) (string, error) {
 	return strings.TrimSpace(result.Stdout), nil
 }
 
-// Status returns git status output
-func (g *Git) Status() (*Result, error) {
-	return g.run("status")
+// RefExists checks if a fully-qualified ref (e.g. refs/remotes/origin/main) exists
+func (g *Git) RefExists(ctx context.Context, ref string) (bool, error) {
+	result, err := g.run(ctx, "rev-parse", "--verify", "--quiet", ref)
+	if err != nil {
+		return false, err
+	}
+	return result.ExitCode == 0, nil
+}
+
+// IsAncestor checks if ancestor is reachable from descendant
+func (g *Git) IsAncestor(ctx context.Context, ancestor, descendant string) (bool, error) {
+	result, err := g.run(ctx, "merge-base", "--is-ancestor", ancestor, descendant)
+	if err != nil {
+		return false, err
+	}
+	switch result.ExitCode {
+	case 0:
+		return true, nil
+	case 1:
+		return false, nil
+	default:
+		return false, fmt.Errorf("git merge-base --is-ancestor failed: %s", result.Stderr)
+	}
+}
+
+// MergeBase returns the best common ancestor of two commits
+func (g *Git) MergeBase(ctx context.Context, a, b string) (string, error) {
+	result, err := g.run(ctx, "merge-base", a, b)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("git merge-base failed: %s", result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+// MergeBaseOctopus returns the best common ancestor of all the given commits
+func (g *Git) MergeBaseOctopus(ctx context.Context, refs ...string) (string, error) {
+	result, err := g.run(ctx, append([]string{"merge-base", "--octopus"}, refs...)...)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("git merge-base --octopus failed: %s", result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+// RangeDiff returns the output of git range-diff between two revision ranges
+func (g *Git) RangeDiff(ctx context.Context, range1, range2 string) (string, error) {
+	result, err := g.run(ctx, "range-diff", "--no-color", range1, range2)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("git range-diff failed: %s", result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+// RevList returns the commit SHAs selected by git rev-list with the given arguments
+func (g *Git) RevList(ctx context.Context, args ...string) ([]string, error) {
+	result, err := g.run(ctx, append([]string{"rev-list"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("git rev-list failed: %s", result.Stderr)
+	}
+	if result.Stdout == "" {
+		return nil, nil
+	}
+	return strings.Split(result.Stdout, "\n"), nil
+}
+
+// CountCommits returns the number of commits in a revision range (e.g. base..branch)
+func (g *Git) CountCommits(ctx context.Context, revRange string) (int, error) {
+	result, err := g.run(ctx, "rev-list", "--count", revRange)
+	if err != nil {
+		return 0, err
+	}
+	if result.ExitCode != 0 {
+		return 0, fmt.Errorf("git rev-list --count failed: %s", result.Stderr)
+	}
+	count, err := strconv.Atoi(result.Stdout)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected git rev-list --count output: %q", result.Stdout)
+	}
+	return count, nil
+}
+
+// Commit is a commit listed by Log
+type Commit struct {
+	SHA     string
+	Author  string
+	Subject string
+	// Body is the commit message after the subject line
+	Body string
+}
+
+// Log returns the commits in a revision range (e.g. base..HEAD), oldest first
+func (g *Git) Log(ctx context.Context, revRange string) ([]Commit, error) {
+	// Fields are separated by US and commits terminated by RS, which cannot appear in messages
+	result, err := g.run(ctx, "log", "--reverse", "--no-color", "--format=%H%x1f%an%x1f%s%x1f%b%x1e", revRange)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("git log failed: %s", result.Stderr)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(result.Stdout, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		commits = append(commits, Commit{
+			SHA:     fields[0],
+			Author:  fields[1],
+			Subject: fields[2],
+			Body:    strings.TrimSpace(fields[3]),
+		})
+	}
+	return commits, nil
+}
+
+// DiffStat returns the git diff --stat summary of the changes between two commits
+func (g *Git) DiffStat(ctx context.Context, from, to string) (string, error) {
+	result, err := g.run(ctx, "diff", "--stat", "--no-color", from, to)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("git diff --stat failed: %s", result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+// FileStat represents the line changes for a single file in a diff
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	// Binary is true for binary files, which have no line counts
+	Binary bool
+}
+
+// DiffNumStat returns per-file line changes between two commits (git diff --numstat)
+func (g *Git) DiffNumStat(ctx context.Context, from, to string) ([]FileStat, error) {
+	result, err := g.run(ctx, "diff", "--numstat", from, to)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("git diff --numstat failed: %s", result.Stderr)
+	}
+	return parseNumStat(result.Stdout), nil
+}
+
+// parseNumStat parses the output of git diff --numstat
+func parseNumStat(output string) []FileStat {
+	var stats []FileStat
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		stat := FileStat{Path: fields[2]}
+		if fields[0] == "-" && fields[1] == "-" {
+			stat.Binary = true
+		} else {
+			stat.Added, _ = strconv.Atoi(fields[0])
+			stat.Deleted, _ = strconv.Atoi(fields[1])
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// PatchID pairs a stable patch ID with the commit it was computed from
+type PatchID struct {
+	ID     string
+	Commit string
 }
 
-// CreatePRWithGH creates a pull request using gh CLI
-func (g *Git) CreatePRWithGH(title, body, base string) (*Result, error) {
-	return g.runExternal("gh", "pr", "create", "--title", title, "--body", body, "--base", base)
+// PatchIDs returns the stable patch IDs of the non-merge commits in a revision range (newest first).
+// Commits with an empty diff have no patch ID and are omitted.
+func (g *Git) PatchIDs(ctx context.Context, revRange string) ([]PatchID, error) {
+	logResult, err := g.run(ctx, "log", "-p", "--no-merges", "--no-color", revRange)
+	if err != nil {
+		return nil, err
+	}
+	if logResult.ExitCode != 0 {
+		return nil, fmt.Errorf("git log failed: %s", logResult.Stderr)
+	}
+	if logResult.Stdout == "" {
+		return nil, nil
+	}
+
+	return g.patchIDs(ctx, logResult.Stdout)
 }
 
-// CreateMRWithGLab creates a merge request using glab CLI
-func (g *Git) CreateMRWithGLab(title, description, targetBranch string) (*Result, error) {
-	return g.runExternal("glab", "mr", "create", "--title", title, "--description", description, "--target-branch", targetBranch)
+// DiffPatchID returns the stable patch ID of the combined diff between two commits.
+// Returns an empty string if there is no difference.
+func (g *Git) DiffPatchID(ctx context.Context, from, to string) (string, error) {
+	diffResult, err := g.run(ctx, "diff", "--no-color", from, to)
+	if err != nil {
+		return "", err
+	}
+	if diffResult.ExitCode != 0 {
+		return "", fmt.Errorf("git diff failed: %s", diffResult.Stderr)
+	}
+	if diffResult.Stdout == "" {
+		return "", nil
+	}
+
+	ids, err := g.patchIDs(ctx, diffResult.Stdout)
+	if err != nil || len(ids) == 0 {
+		return "", err
+	}
+	return ids[0].ID, nil
+}
+
+// patchIDs feeds a patch series to git patch-id --stable and parses the result
+func (g *Git) patchIDs(ctx context.Context, patch string) ([]PatchID, error) {
+	result, err := g.runWithInput(ctx, patch+"\n", "patch-id", "--stable")
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("git patch-id failed: %s", result.Stderr)
+	}
+
+	var ids []PatchID
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		ids = append(ids, PatchID{ID: fields[0], Commit: fields[1]})
+	}
+	return ids, nil
+}
+
+// RemoteBranchExists checks if a branch exists on the remote (queries the remote with ls-remote)
+func (g *Git) RemoteBranchExists(ctx context.Context, remote, branch string) (bool, error) {
+	result, err := g.run(ctx, "ls-remote", "--exit-code", "--heads", remote, "refs/heads/"+branch)
+	if err != nil {
+		return false, err
+	}
+	switch result.ExitCode {
+	case 0:
+		return true, nil
+	case 2:
+		// --exit-code returns 2 when no matching refs were found
+		return false, nil
+	default:
+		return false, fmt.Errorf("git ls-remote failed: %s", result.Stderr)
+	}
+}
+
+// Status returns git status output
+func (g *Git) Status(ctx context.Context) (*Result, error) {
+	return g.run(ctx, "status")
+}
+
+// IsDirty checks if the worktree has uncommitted changes (including untracked files)
+func (g *Git) IsDirty(ctx context.Context) (bool, error) {
+	result, err := g.run(ctx, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	if result.ExitCode != 0 {
+		return false, fmt.Errorf("git status failed: %s", result.Stderr)
+	}
+	return result.Stdout != "", nil
 }
 
 // GetRemoteURL returns the URL for a given remote
-func (g *Git) GetRemoteURL(remote string) (string, error) {
-	result, err := g.run("remote", "get-url", remote)
+func (g *Git) GetRemoteURL(ctx context.Context, remote string) (string, error) {
+	result, err := g.run(ctx, "remote", "get-url", remote)
 	if err != nil {
 		return "", err
 	}
@@ -326,8 +695,11 @@ func (g *Git) GetRemoteURL(remote string) (string, error) {
 	return result.Stdout, nil
 }
 
-// remoteInfo holds the parsed host, owner, and repo from a remote URL
-type remoteInfo struct {
+// RemoteInfo holds the parsed host, owner, and repo from a remote URL
+type RemoteInfo struct {
+	// Scheme is the scheme of the forge's web UI: the remote's for HTTP(S) URLs, https otherwise
+	Scheme string
+	// Host is the host of the forge's web UI (SSH ports are dropped)
 	Host  string
 	Owner string
 	Repo  string
@@ -337,64 +709,63 @@ type remoteInfo struct {
 var sshRemotePattern = regexp.MustCompile(`^[\w.-]+@([\w.-]+):([\w._-]+)/([\w._-]+?)(?:\.git)?$`)
 
 // parseRemoteURL parses a git remote URL (SSH or HTTPS) into host, owner, and repo
-func parseRemoteURL(rawURL string) (*remoteInfo, error) {
+func parseRemoteURL(rawURL string) (*RemoteInfo, error) {
 	rawURL = strings.TrimSpace(rawURL)
 
 	// Try SSH format: git@github.com:owner/repo.git
 	if matches := sshRemotePattern.FindStringSubmatch(rawURL); matches != nil {
-		return &remoteInfo{
-			Host:  matches[1],
-			Owner: matches[2],
-			Repo:  matches[3],
+		return &RemoteInfo{
+			Scheme: "https",
+			Host:   matches[1],
+			Owner:  matches[2],
+			Repo:   matches[3],
 		}, nil
 	}
 
-	// Try HTTPS format: https://github.com/owner/repo.git
+	// Try URL format: https://github.com/owner/repo.git or ssh://git@host:7999/owner/repo.git
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse remote URL %q: %w", rawURL, err)
 	}
 
 	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
-	if len(parts) < 2 {
+	// Bitbucket Server serves HTTP clones under /scm/<project>/<repo>
+	if len(parts) > 2 && parts[0] == "scm" {
+		parts = parts[1:]
+	}
+	if len(parts) < 2 || parsed.Host == "" {
 		return nil, fmt.Errorf("could not parse owner/repo from URL %q", rawURL)
 	}
 
 	repo := parts[1]
 	repo = strings.TrimSuffix(repo, ".git")
 
-	return &remoteInfo{
-		Host:  parsed.Host,
-		Owner: parts[0],
-		Repo:  repo,
-	}, nil
-}
-
-// CompareURL constructs a browser-openable compare URL for creating a PR/MR
-func (g *Git) CompareURL(remote, branch, base string) (string, error) {
-	remoteURL, err := g.GetRemoteURL(remote)
-	if err != nil {
-		return "", err
+	info := &RemoteInfo{
+		Scheme: parsed.Scheme,
+		Host:   parsed.Host,
+		Owner:  parts[0],
+		Repo:   repo,
 	}
-
-	info, err := parseRemoteURL(remoteURL)
-	if err != nil {
-		return "", err
+	if info.Scheme != "http" && info.Scheme != "https" {
+		// The port of an SSH or git URL is not the web UI's
+		info.Scheme = "https"
+		info.Host = parsed.Hostname()
 	}
+	return info, nil
+}
 
-	switch {
-	case strings.Contains(info.Host, "github"):
-		return fmt.Sprintf("https://%s/%s/%s/compare/%s...%s?expand=1", info.Host, info.Owner, info.Repo, base, branch), nil
-	case strings.Contains(info.Host, "gitlab"):
-		return fmt.Sprintf("https://%s/%s/%s/-/merge_requests/new?merge_request[source_branch]=%s&merge_request[target_branch]=%s", info.Host, info.Owner, info.Repo, branch, base), nil
-	default:
-		return fmt.Sprintf("https://%s/%s/%s/compare/%s...%s", info.Host, info.Owner, info.Repo, base, branch), nil
+// Remote returns the host, owner, and repo of the given remote's URL
+func (g *Git) Remote(ctx context.Context, remote string) (*RemoteInfo, error) {
+	remoteURL, err := g.GetRemoteURL(ctx, remote)
+	if err != nil {
+		return nil, err
 	}
+	return parseRemoteURL(remoteURL)
 }
 
 // CurrentBranch returns the current branch name
-func (g *Git) CurrentBranch() (string, error) {
-	result, err := g.run("branch", "--show-current")
+func (g *Git) CurrentBranch(ctx context.Context) (string, error) {
+	result, err := g.run(ctx, "branch", "--show-current")
 	if err != nil {
 		return "", err
 	}
@@ -406,9 +777,9 @@ func (g *Git) CurrentBranch() (string, error) {
 
 // SetUpstream sets the upstream tracking branch for the current branch
 // This uses git config to set the tracking, which works even if the remote branch doesn't exist yet
-func (g *Git) SetUpstream(remote, branch string) (*Result, error) {
+func (g *Git) SetUpstream(ctx context.Context, remote, branch string) (*Result, error) {
 	// Get current branch first
-	currentBranch, err := g.CurrentBranch()
+	currentBranch, err := g.CurrentBranch(ctx)
 	if err != nil {
 		return &Result{ExitCode: 1, Stderr: fmt.Sprintf("failed to get current branch: %v", err)}, err
 	}
@@ -421,12 +792,117 @@ func (g *Git) SetUpstream(remote, branch string) (*Result, error) {
 	configMergeKey := fmt.Sprintf("branch.%s.merge", currentBranch)
 
 	// Set remote
-	remoteResult, err := g.run("config", configKey, remote)
+	remoteResult, err := g.run(ctx, "config", configKey, remote)
 	if err != nil || remoteResult.ExitCode != 0 {
 		return remoteResult, err
 	}
 
 	// Set merge ref
-	mergeResult, err := g.run("config", configMergeKey, upstreamRef)
+	mergeResult, err := g.run(ctx, "config", configMergeKey, upstreamRef)
 	return mergeResult, err
 }
+
+// HashObject writes data to the object database as a blob and returns its object ID
+func (g *Git) HashObject(ctx context.Context, data string) (string, error) {
+	result, err := g.runWithInput(ctx, data, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("git hash-object failed: %s", result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+// CatBlob returns the contents of a blob (by object ID or ref)
+func (g *Git) CatBlob(ctx context.Context, object string) (string, error) {
+	result, err := g.run(ctx, "cat-file", "blob", object)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("git cat-file failed: %s", result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+// ErrRefChanged is returned (wrapped) by UpdateRef when the ref no longer points at
+// the expected old value, or another git process is updating it
+var ErrRefChanged = stderrors.New("ref changed concurrently")
+
+// refChangedMessages are the update-ref errors that mean the compare-and-swap lost a race
+var refChangedMessages = []string{
+	"but expected",                // points at another object
+	"reference already exists",    // created concurrently
+	"unable to resolve reference", // deleted concurrently
+	".lock': File exists",         // being updated right now
+}
+
+// UpdateRef points ref at newValue, but only if it currently points at oldValue.
+// An empty oldValue requires that the ref does not exist yet. If the ref moved,
+// the error wraps ErrRefChanged.
+func (g *Git) UpdateRef(ctx context.Context, ref, newValue, oldValue string) error {
+	// Untranslated messages, so a lost race can be told apart from other failures
+	result, err := g.withEnv("LC_ALL=C").run(ctx, "update-ref", ref, newValue, oldValue)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		for _, msg := range refChangedMessages {
+			if strings.Contains(result.Stderr, msg) {
+				return fmt.Errorf("git update-ref failed: %w: %s", ErrRefChanged, result.Stderr)
+			}
+		}
+		return fmt.Errorf("git update-ref failed: %s", result.Stderr)
+	}
+	return nil
+}
+
+// DeleteRef deletes a ref; deleting a missing ref is not an error
+func (g *Git) DeleteRef(ctx context.Context, ref string) error {
+	exists, err := g.RefExists(ctx, ref)
+	if err != nil || !exists {
+		return err
+	}
+	result, err := g.run(ctx, "update-ref", "-d", ref)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("git update-ref -d failed: %s", result.Stderr)
+	}
+	return nil
+}
+
+// ForEachRef returns the object ID of every ref under prefix (e.g. refs/awt/tasks/), keyed by ref name
+func (g *Git) ForEachRef(ctx context.Context, prefix string) (map[string]string, error) {
+	result, err := g.run(ctx, "for-each-ref", "--format=%(objectname) %(refname)", prefix)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("git for-each-ref failed: %s", result.Stderr)
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		refs[fields[1]] = fields[0]
+	}
+	return refs, nil
+}
+
+// PushRefs pushes refspecs to a remote. leases maps remote ref names to the object ID
+// each is expected to have (empty: must not exist); the push is rejected if any differs.
+func (g *Git) PushRefs(ctx context.Context, remote string, refspecs []string, leases map[string]string) (*Result, error) {
+	args := []string{"push", "--porcelain"}
+	for ref, expected := range leases {
+		args = append(args, fmt.Sprintf("--force-with-lease=%s:%s", ref, expected))
+	}
+	args = append(args, remote)
+	args = append(args, refspecs...)
+	return g.runTimed(ctx, g.timeouts.Push, "push_timeout", args...)
+}
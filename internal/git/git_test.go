@@ -1,12 +1,17 @@
 package git
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/errors"
 )
 
 // setupTestRepo creates a temporary git repository for testing
@@ -58,7 +63,7 @@ func TestGitRun(t *testing.T) {
 	g := New(repoPath, false)
 
 	// Test successful command
-	result, err := g.run("status", "--short")
+	result, err := g.run(context.Background(), "status", "--short")
 	if err != nil {
 		t.Fatalf("git status failed: %v", err)
 	}
@@ -67,7 +72,7 @@ func TestGitRun(t *testing.T) {
 	}
 
 	// Test command with error
-	result, err = g.run("log", "nonexistent-ref")
+	result, err = g.run(context.Background(), "log", "nonexistent-ref")
 	if err != nil {
 		t.Fatalf("git log failed: %v", err)
 	}
@@ -83,13 +88,13 @@ func TestGitBranchExists(t *testing.T) {
 	g := New(repoPath, false)
 
 	// Check for main/master branch (depends on git version)
-	exists, err := g.BranchExists("master")
+	exists, err := g.BranchExists(context.Background(), "master")
 	if err != nil {
 		t.Fatalf("BranchExists failed: %v", err)
 	}
 	if !exists {
 		// Try main instead
-		exists, err = g.BranchExists("main")
+		exists, err = g.BranchExists(context.Background(), "main")
 		if err != nil {
 			t.Fatalf("BranchExists failed: %v", err)
 		}
@@ -99,7 +104,7 @@ func TestGitBranchExists(t *testing.T) {
 	}
 
 	// Check for non-existent branch
-	exists, err = g.BranchExists("nonexistent")
+	exists, err = g.BranchExists(context.Background(), "nonexistent")
 	if err != nil {
 		t.Fatalf("BranchExists failed: %v", err)
 	}
@@ -115,7 +120,7 @@ func TestGitWorktreeOperations(t *testing.T) {
 	g := New(repoPath, false)
 
 	// Get current branch
-	currentBranch, err := g.CurrentBranch()
+	currentBranch, err := g.CurrentBranch(context.Background())
 	if err != nil {
 		t.Fatalf("CurrentBranch failed: %v", err)
 	}
@@ -124,7 +129,7 @@ func TestGitWorktreeOperations(t *testing.T) {
 	}
 
 	// List worktrees (should have just the main one)
-	worktrees, err := g.WorktreeList()
+	worktrees, err := g.WorktreeList(context.Background())
 	if err != nil {
 		t.Fatalf("WorktreeList failed: %v", err)
 	}
@@ -134,7 +139,7 @@ func TestGitWorktreeOperations(t *testing.T) {
 
 	// Add a worktree
 	wtPath := filepath.Join(repoPath, "wt-test")
-	result, err := g.WorktreeAdd(wtPath, "test-branch", currentBranch)
+	result, err := g.WorktreeAdd(context.Background(), wtPath, "test-branch", currentBranch)
 	if err != nil {
 		t.Fatalf("WorktreeAdd failed: %v", err)
 	}
@@ -148,7 +153,7 @@ func TestGitWorktreeOperations(t *testing.T) {
 	}
 
 	// List worktrees again
-	worktrees, err = g.WorktreeList()
+	worktrees, err = g.WorktreeList(context.Background())
 	if err != nil {
 		t.Fatalf("WorktreeList failed: %v", err)
 	}
@@ -157,7 +162,7 @@ func TestGitWorktreeOperations(t *testing.T) {
 	}
 
 	// Check if branch is checked out
-	isCheckedOut, path, err := g.IsBranchCheckedOut("test-branch")
+	isCheckedOut, path, err := g.IsBranchCheckedOut(context.Background(), "test-branch")
 	if err != nil {
 		t.Fatalf("IsBranchCheckedOut failed: %v", err)
 	}
@@ -172,7 +177,7 @@ func TestGitWorktreeOperations(t *testing.T) {
 	}
 
 	// Remove worktree
-	result, err = g.WorktreeRemove(wtPath, false)
+	result, err = g.WorktreeRemove(context.Background(), wtPath, false)
 	if err != nil {
 		t.Fatalf("WorktreeRemove failed: %v", err)
 	}
@@ -181,7 +186,7 @@ func TestGitWorktreeOperations(t *testing.T) {
 	}
 
 	// Verify worktree was removed
-	worktrees, err = g.WorktreeList()
+	worktrees, err = g.WorktreeList(context.Background())
 	if err != nil {
 		t.Fatalf("WorktreeList failed: %v", err)
 	}
@@ -245,7 +250,7 @@ func TestGitFetch(t *testing.T) {
 	g := New(repoPath, false)
 
 	// Test fetch (will fail but tests the method)
-	result, err := g.Fetch("", "")
+	result, err := g.Fetch(context.Background(), "", "")
 	if err != nil {
 		t.Fatalf("Fetch failed: %v", err)
 	}
@@ -253,6 +258,32 @@ func TestGitFetch(t *testing.T) {
 	_ = result.ExitCode
 }
 
+func TestGitFetchTimeout(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// An ssh "transport" that never answers
+	g := New(repoPath, false).withEnv("GIT_SSH_COMMAND=sleep 5;").WithTimeouts(Timeouts{Fetch: 200 * time.Millisecond})
+
+	start := time.Now()
+	_, err := g.Fetch(context.Background(), "ssh://example.invalid/repo.git", "")
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Fetch took %s after its timeout", elapsed)
+	}
+
+	var awtErr *errors.AWTError
+	if !stderrors.As(err, &awtErr) || awtErr.Code != errors.ExitGitTimeout {
+		t.Fatalf("expected a GIT_TIMEOUT error, got %v", err)
+	}
+
+	// Cancellation is reported as such, not as a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.Fetch(ctx, "ssh://example.invalid/repo.git", ""); !stderrors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
 func TestGitAdd(t *testing.T) {
 	repoPath, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -266,7 +297,7 @@ func TestGitAdd(t *testing.T) {
 	}
 
 	// Test add
-	result, err := g.Add(testFile)
+	result, err := g.Add(context.Background(), testFile)
 	if err != nil {
 		t.Fatalf("Add failed: %v", err)
 	}
@@ -288,10 +319,10 @@ func TestGitCommit(t *testing.T) {
 	}
 
 	// Add the file
-	_, _ = g.Add(testFile)
+	_, _ = g.Add(context.Background(), testFile)
 
 	// Test commit
-	result, err := g.Commit("Test commit message", false, false, false)
+	result, err := g.Commit(context.Background(), "Test commit message", false, false, false)
 	if err != nil {
 		t.Fatalf("Commit failed: %v", err)
 	}
@@ -307,7 +338,7 @@ func TestGitStatus(t *testing.T) {
 	g := New(repoPath, false)
 
 	// Test status
-	result, err := g.Status()
+	result, err := g.Status(context.Background())
 	if err != nil {
 		t.Fatalf("Status failed: %v", err)
 	}
@@ -323,7 +354,7 @@ func TestGitRevParse(t *testing.T) {
 	g := New(repoPath, false)
 
 	// Test RevParse with HEAD
-	sha, err := g.RevParse("HEAD")
+	sha, err := g.RevParse(context.Background(), "HEAD")
 	if err != nil {
 		t.Fatalf("RevParse failed: %v", err)
 	}
@@ -332,6 +363,136 @@ func TestGitRevParse(t *testing.T) {
 	}
 }
 
+func TestGitUpdateRefCompareAndSwap(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(repoPath, false)
+	ctx := context.Background()
+	head, err := g.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse failed: %v", err)
+	}
+
+	if err := g.UpdateRef(ctx, "refs/awt/test", head, ""); err != nil {
+		t.Fatalf("UpdateRef() creating the ref failed: %v", err)
+	}
+
+	// Losing the race is reported as ErrRefChanged
+	if err := g.UpdateRef(ctx, "refs/awt/test", head, ""); !stderrors.Is(err, ErrRefChanged) {
+		t.Errorf("UpdateRef() on an existing ref: error = %v, want ErrRefChanged", err)
+	}
+	if err := g.UpdateRef(ctx, "refs/awt/missing", head, head); !stderrors.Is(err, ErrRefChanged) {
+		t.Errorf("UpdateRef() on a deleted ref: error = %v, want ErrRefChanged", err)
+	}
+
+	// Other failures are not
+	err = g.UpdateRef(ctx, "refs/awt/other", "1234567890123456789012345678901234567890", "")
+	if err == nil || stderrors.Is(err, ErrRefChanged) {
+		t.Errorf("UpdateRef() to a missing object: error = %v, want a non-conflict error", err)
+	}
+}
+
+func TestGitIsAncestorAndPatchIDs(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(repoPath, false)
+
+	initial, err := g.RevParse(context.Background(), "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse failed: %v", err)
+	}
+
+	// Create a second commit
+	_ = os.WriteFile(filepath.Join(repoPath, "file.txt"), []byte("content\n"), 0644)
+	_ = exec.Command("git", "-C", repoPath, "add", "file.txt").Run()
+	_ = exec.Command("git", "-C", repoPath, "commit", "-m", "Add file").Run()
+
+	head, err := g.RevParse(context.Background(), "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse failed: %v", err)
+	}
+
+	isAncestor, err := g.IsAncestor(context.Background(), initial, head)
+	if err != nil {
+		t.Fatalf("IsAncestor failed: %v", err)
+	}
+	if !isAncestor {
+		t.Error("expected initial commit to be an ancestor of HEAD")
+	}
+
+	isAncestor, err = g.IsAncestor(context.Background(), head, initial)
+	if err != nil {
+		t.Fatalf("IsAncestor failed: %v", err)
+	}
+	if isAncestor {
+		t.Error("expected HEAD not to be an ancestor of the initial commit")
+	}
+
+	// The patch ID of the single commit must equal the patch ID of the combined diff
+	ids, err := g.PatchIDs(context.Background(), initial+".."+head)
+	if err != nil {
+		t.Fatalf("PatchIDs failed: %v", err)
+	}
+	if len(ids) != 1 || ids[0].Commit != head {
+		t.Fatalf("PatchIDs = %+v, expected one entry for %s", ids, head)
+	}
+
+	combined, err := g.DiffPatchID(context.Background(), initial, head)
+	if err != nil {
+		t.Fatalf("DiffPatchID failed: %v", err)
+	}
+	if combined != ids[0].ID {
+		t.Errorf("DiffPatchID = %s, expected %s", combined, ids[0].ID)
+	}
+}
+
+func TestGitLogAndDiffStat(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	g := New(repoPath, false)
+	base, err := g.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse failed: %v", err)
+	}
+
+	for i, msg := range []string{"Add a.txt", "Add b.txt\n\nWith a body.\nOn two lines."} {
+		name := fmt.Sprintf("%c.txt", 'a'+i)
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte("content\n"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		_ = exec.Command("git", "-C", repoPath, "add", name).Run()
+		if out, err := exec.Command("git", "-C", repoPath, "commit", "-m", msg).CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\n%s", err, out)
+		}
+	}
+
+	commits, err := g.Log(ctx, base+"..HEAD")
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("Log returned %d commits, want 2: %+v", len(commits), commits)
+	}
+	if commits[0].Subject != "Add a.txt" || commits[0].Body != "" || commits[0].Author != "Test User" {
+		t.Errorf("first commit = %+v", commits[0])
+	}
+	if commits[1].Subject != "Add b.txt" || commits[1].Body != "With a body.\nOn two lines." || len(commits[1].SHA) != 40 {
+		t.Errorf("second commit = %+v", commits[1])
+	}
+
+	stat, err := g.DiffStat(ctx, base, "HEAD")
+	if err != nil {
+		t.Fatalf("DiffStat failed: %v", err)
+	}
+	if !strings.Contains(stat, "a.txt") || !strings.Contains(stat, "2 files changed") {
+		t.Errorf("DiffStat = %q", stat)
+	}
+}
+
 func TestGitWorktreePrune(t *testing.T) {
 	repoPath, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -339,7 +500,7 @@ func TestGitWorktreePrune(t *testing.T) {
 	g := New(repoPath, false)
 
 	// Test worktree prune (won't do much but covers the code)
-	result, err := g.WorktreePrune()
+	result, err := g.WorktreePrune(context.Background())
 	if err != nil {
 		t.Fatalf("WorktreePrune failed: %v", err)
 	}
@@ -350,12 +511,13 @@ func TestGitWorktreePrune(t *testing.T) {
 
 func TestParseRemoteURL(t *testing.T) {
 	tests := []struct {
-		name     string
-		url      string
-		wantHost string
-		wantOwn  string
-		wantRepo string
-		wantErr  bool
+		name       string
+		url        string
+		wantScheme string
+		wantHost   string
+		wantOwn    string
+		wantRepo   string
+		wantErr    bool
 	}{
 		{
 			name:     "SSH with .git",
@@ -399,11 +561,39 @@ func TestParseRemoteURL(t *testing.T) {
 			wantOwn:  "mygroup",
 			wantRepo: "myproject",
 		},
+		{
+			name:       "Bitbucket Server SSH with port",
+			url:        "ssh://git@bitbucket.example.com:7999/proj/repo.git",
+			wantScheme: "https",
+			wantHost:   "bitbucket.example.com",
+			wantOwn:    "proj",
+			wantRepo:   "repo",
+		},
+		{
+			name:     "Bitbucket Server HTTPS",
+			url:      "https://bitbucket.example.com/scm/proj/repo.git",
+			wantHost: "bitbucket.example.com",
+			wantOwn:  "proj",
+			wantRepo: "repo",
+		},
+		{
+			name:       "Forgejo HTTP with port",
+			url:        "http://localhost:3000/owner/repo.git",
+			wantScheme: "http",
+			wantHost:   "localhost:3000",
+			wantOwn:    "owner",
+			wantRepo:   "repo",
+		},
 		{
 			name:    "invalid URL",
 			url:     "not-a-url",
 			wantErr: true,
 		},
+		{
+			name:    "local path",
+			url:     "/srv/git/repo.git",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -418,6 +608,13 @@ func TestParseRemoteURL(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
+			wantScheme := tt.wantScheme
+			if wantScheme == "" {
+				wantScheme = "https"
+			}
+			if info.Scheme != wantScheme {
+				t.Errorf("Scheme = %q, want %q", info.Scheme, wantScheme)
+			}
 			if info.Host != tt.wantHost {
 				t.Errorf("Host = %q, want %q", info.Host, tt.wantHost)
 			}
@@ -431,57 +628,6 @@ func TestParseRemoteURL(t *testing.T) {
 	}
 }
 
-func TestCompareURL(t *testing.T) {
-	repoPath, cleanup := setupTestRepo(t)
-	defer cleanup()
-
-	// Add a remote
-	_ = exec.Command("git", "-C", repoPath, "remote", "add", "origin", "git@github.com:owner/repo.git").Run()
-
-	g := New(repoPath, false)
-
-	tests := []struct {
-		name       string
-		remoteURL  string
-		branch     string
-		base       string
-		wantSubstr string
-	}{
-		{
-			name:       "GitHub compare URL",
-			remoteURL:  "git@github.com:owner/repo.git",
-			branch:     "feature",
-			base:       "main",
-			wantSubstr: "github.com/owner/repo/compare/main...feature?expand=1",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Set the remote URL
-			_ = exec.Command("git", "-C", repoPath, "remote", "set-url", "origin", tt.remoteURL).Run()
-
-			url, err := g.CompareURL("origin", tt.branch, tt.base)
-			if err != nil {
-				t.Fatalf("CompareURL failed: %v", err)
-			}
-			if !strings.Contains(url, tt.wantSubstr) {
-				t.Errorf("CompareURL = %q, want substring %q", url, tt.wantSubstr)
-			}
-		})
-	}
-
-	// Test GitLab URL
-	_ = exec.Command("git", "-C", repoPath, "remote", "set-url", "origin", "git@gitlab.com:group/project.git").Run()
-	url, err := g.CompareURL("origin", "feature", "main")
-	if err != nil {
-		t.Fatalf("CompareURL (gitlab) failed: %v", err)
-	}
-	if !strings.Contains(url, "gitlab.com/group/project/-/merge_requests/new") {
-		t.Errorf("CompareURL (gitlab) = %q, want gitlab merge_requests URL", url)
-	}
-}
-
 func TestGitSetUpstream(t *testing.T) {
 	repoPath, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -491,7 +637,7 @@ func TestGitSetUpstream(t *testing.T) {
 
 	// Get current branch
 	g := New(repoPath, false)
-	currentBranch, err := g.CurrentBranch()
+	currentBranch, err := g.CurrentBranch(context.Background())
 	if err != nil {
 		t.Fatalf("CurrentBranch failed: %v", err)
 	}
@@ -501,7 +647,7 @@ func TestGitSetUpstream(t *testing.T) {
 	_ = exec.Command("git", "-C", repoPath, "checkout", "-b", testBranch).Run()
 
 	// Set upstream
-	result, err := g.SetUpstream("origin", testBranch)
+	result, err := g.SetUpstream(context.Background(), "origin", testBranch)
 	if err != nil {
 		t.Fatalf("SetUpstream failed: %v", err)
 	}
@@ -25,12 +25,78 @@ func GenerateTaskID() (string, error) {
 	return fmt.Sprintf("%s-%s", timestamp, randomHex), nil
 }
 
-// GenerateBranchName generates a branch name for a task
-// Format: <prefix>/<agent>/<id>
-func GenerateBranchName(prefix, agent, taskID string) string {
-	// Sanitize agent name (remove invalid characters)
-	agent = SanitizeName(agent)
-	return fmt.Sprintf("%s/%s/%s", prefix, agent, taskID)
+// DefaultBranchTemplate is the branch name template used when none is configured
+const DefaultBranchTemplate = "{prefix}/{agent}/{id}"
+
+// maxSlugLength limits the length of the {slug} placeholder
+const maxSlugLength = 40
+
+// branchPlaceholders are the placeholders supported in branch templates
+var branchPlaceholders = []string{"{prefix}", "{agent}", "{slug}", "{id}"}
+
+// GenerateBranchName generates a branch name for a task from a template.
+// Supported placeholders: {prefix}, {agent}, {slug} (derived from the title) and {id}.
+// An empty template uses DefaultBranchTemplate: <prefix>/<agent>/<id>
+func GenerateBranchName(template, prefix, agent, title, taskID string) (string, error) {
+	if template == "" {
+		template = DefaultBranchTemplate
+	}
+	if err := ValidateBranchTemplate(template); err != nil {
+		return "", err
+	}
+
+	slug := Slugify(title)
+	if slug == "" {
+		slug = "task"
+	}
+
+	replacer := strings.NewReplacer(
+		"{prefix}", prefix,
+		"{agent}", SanitizeName(agent),
+		"{slug}", slug,
+		"{id}", taskID,
+	)
+	return replacer.Replace(template), nil
+}
+
+// ValidateBranchTemplate checks that a branch template only uses known placeholders
+// and includes {id}, which keeps branch names unique per task
+func ValidateBranchTemplate(template string) error {
+	if !strings.Contains(template, "{id}") {
+		return fmt.Errorf("branch template must contain {id}: %s", template)
+	}
+
+	rest := template
+	for _, placeholder := range branchPlaceholders {
+		rest = strings.ReplaceAll(rest, placeholder, "")
+	}
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("branch template has unknown placeholder (supported: %s): %s", strings.Join(branchPlaceholders, ", "), template)
+	}
+
+	return nil
+}
+
+// Slugify converts a task title into a short branch-safe slug.
+// Runs of characters other than ASCII letters and digits become a single hyphen.
+func Slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, c := range strings.ToLower(title) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+			hyphen = false
+		} else if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+	return strings.Trim(slug, "-")
 }
 
 // SanitizeName sanitizes a name for use in Git branches
@@ -86,7 +86,61 @@ func TestGenerateBranchName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GenerateBranchName(tt.prefix, tt.agent, tt.taskID)
+			result, err := GenerateBranchName("", tt.prefix, tt.agent, "", tt.taskID)
+			if err != nil {
+				t.Fatalf("GenerateBranchName() failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("GenerateBranchName() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateBranchNameFromTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		title    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "slug and id",
+			template: "{prefix}/{agent}/{slug}-{id}",
+			title:    "Add User Auth!",
+			expected: "awt/claude/add-user-auth-abc123",
+		},
+		{
+			name:     "empty title falls back to task",
+			template: "{agent}/{slug}-{id}",
+			title:    "???",
+			expected: "claude/task-abc123",
+		},
+		{
+			name:     "missing id",
+			template: "{prefix}/{agent}/{slug}",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown placeholder",
+			template: "{prefix}/{user}/{id}",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GenerateBranchName(tt.template, "awt", "claude", tt.title, "abc123")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("GenerateBranchName() = %q, want error", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateBranchName() failed: %v", err)
+			}
 			if result != tt.expected {
 				t.Errorf("GenerateBranchName() = %q, want %q", result, tt.expected)
 			}
@@ -94,6 +148,16 @@ func TestGenerateBranchName(t *testing.T) {
 	}
 }
 
+func TestSlugify(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+	if slug := Slugify(long); len(slug) > 40 || strings.HasSuffix(slug, "-") {
+		t.Errorf("Slugify() = %q, want at most 40 chars without trailing hyphen", slug)
+	}
+	if slug := Slugify("  Fix: the (bug) "); slug != "fix-the-bug" {
+		t.Errorf("Slugify() = %q, want %q", slug, "fix-the-bug")
+	}
+}
+
 func TestSanitizeName(t *testing.T) {
 	tests := []struct {
 		name     string
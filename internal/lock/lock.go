@@ -2,9 +2,11 @@ package lock
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -22,30 +24,114 @@ type Lock struct {
 	file *os.File
 }
 
+// writeOwner records the owner metadata in the lock file
+func (l *Lock) writeOwner(owner *Owner) error {
+	data, err := json.Marshal(owner)
+	if err != nil {
+		return err
+	}
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err = l.file.WriteAt(append(data, '\n'), 0)
+	return err
+}
+
+// HeldError is returned when a lock could not be acquired or broken because another process holds it
+type HeldError struct {
+	// Name is the lock name
+	Name string
+	// Holder describes the current holder (empty if unknown)
+	Holder string
+	// Waited is how long acquisition was attempted (zero if it was not attempted)
+	Waited time.Duration
+	// Reason explains why the lock could not be broken (empty for acquisition timeouts)
+	Reason string
+}
+
+// Error implements the error interface
+func (e *HeldError) Error() string {
+	msg := fmt.Sprintf("lock %s is held", e.Name)
+	if e.Holder != "" {
+		msg += " by " + e.Holder
+	}
+	if e.Reason != "" {
+		msg += ", " + e.Reason
+	}
+	if e.Waited > 0 {
+		msg += fmt.Sprintf(" (waited %v)", e.Waited.Round(time.Millisecond))
+	}
+	return msg
+}
+
 // LockManager manages locks for the AWT system
 type LockManager struct {
 	locksDir string
+	timeout  time.Duration
 }
 
-// NewLockManager creates a new lock manager
+// NewLockManager creates a new lock manager using DefaultTimeout
 func NewLockManager(gitCommonDir string) *LockManager {
+	return NewLockManagerWithTimeout(gitCommonDir, DefaultTimeout)
+}
+
+// NewLockManagerWithTimeout creates a new lock manager with the given acquisition timeout.
+// A non-positive timeout falls back to DefaultTimeout.
+func NewLockManagerWithTimeout(gitCommonDir string, timeout time.Duration) *LockManager {
+	return NewLockManagerInDir(filepath.Join(gitCommonDir, "awt", "locks"), timeout)
+}
+
+// NewLockManagerInDir creates a lock manager keeping its lock files in locksDir,
+// for locks private to one component that should not show up in 'awt lock status'.
+// A non-positive timeout falls back to DefaultTimeout.
+func NewLockManagerInDir(locksDir string, timeout time.Duration) *LockManager {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
 	return &LockManager{
-		locksDir: filepath.Join(gitCommonDir, "awt", "locks"),
+		locksDir: locksDir,
+		timeout:  timeout,
 	}
 }
 
-// AcquireGlobal acquires the global lock with the default timeout
+// AcquireGlobal acquires the global lock with the manager's timeout
 func (lm *LockManager) AcquireGlobal(ctx context.Context) (*Lock, error) {
-	return lm.AcquireLock(ctx, "global")
+	return lm.acquireLock(ctx, "global", "")
 }
 
-// AcquireTask acquires a task-specific lock with the default timeout
+// AcquireTask acquires a task-specific lock with the manager's timeout
 func (lm *LockManager) AcquireTask(ctx context.Context, taskID string) (*Lock, error) {
-	return lm.AcquireLock(ctx, taskID)
+	return lm.acquireLock(ctx, taskID, taskID)
+}
+
+// Holder describes the process holding the named lock, or "" if unknown
+func (lm *LockManager) Holder(name string) string {
+	owner := lm.Owner(name)
+	if owner == nil {
+		return ""
+	}
+	return owner.String()
+}
+
+// Owner returns the owner metadata recorded in the named lock file, or nil if there is none.
+// The .exclusive fallback file takes precedence since it only exists while held.
+func (lm *LockManager) Owner(name string) *Owner {
+	lockPath := filepath.Join(lm.locksDir, name+".lock")
+	for _, path := range []string{lockPath + ".exclusive", lockPath} {
+		if owner, err := readOwner(path); err == nil && owner != nil {
+			return owner
+		}
+	}
+	return nil
 }
 
 // AcquireLock acquires a lock with the given name
 func (lm *LockManager) AcquireLock(ctx context.Context, name string) (*Lock, error) {
+	return lm.acquireLock(ctx, name, "")
+}
+
+// acquireLock acquires a lock with the given name and records this process as its owner
+func (lm *LockManager) acquireLock(ctx context.Context, name, taskID string) (*Lock, error) {
 	// Ensure locks directory exists
 	if err := os.MkdirAll(lm.locksDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create locks directory: %w", err)
@@ -56,9 +142,9 @@ func (lm *LockManager) AcquireLock(ctx context.Context, name string) (*Lock, err
 	// Try to acquire lock with timeout
 	deadline, hasDeadline := ctx.Deadline()
 	if !hasDeadline {
-		// No deadline set, use default timeout
+		// No deadline set, use the manager's timeout
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		ctx, cancel = context.WithTimeout(ctx, lm.timeout)
 		defer cancel()
 		deadline, _ = ctx.Deadline()
 	}
@@ -68,12 +154,17 @@ func (lm *LockManager) AcquireLock(ctx context.Context, name string) (*Lock, err
 		// Try to acquire the lock
 		lock, err := tryAcquireLock(lockPath)
 		if err == nil {
+			// Record who holds the lock so contenders and 'awt lock status' can report it
+			_ = lock.writeOwner(newOwner(taskID))
 			return lock, nil
 		}
 
 		// Check if context is done (timeout or cancellation)
 		select {
 		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, &HeldError{Name: name, Holder: lm.Holder(name), Waited: time.Since(startTime)}
+			}
 			elapsed := time.Since(startTime)
 			return nil, fmt.Errorf("failed to acquire lock %s after %v: %w", name, elapsed, ctx.Err())
 		default:
@@ -81,8 +172,7 @@ func (lm *LockManager) AcquireLock(ctx context.Context, name string) (*Lock, err
 
 		// Check if we've exceeded the deadline
 		if time.Now().After(deadline) {
-			elapsed := time.Since(startTime)
-			return nil, fmt.Errorf("lock acquisition timeout for %s after %v", name, elapsed)
+			return nil, &HeldError{Name: name, Holder: lm.Holder(name), Waited: time.Since(startTime)}
 		}
 
 		// Wait before retrying
@@ -92,12 +182,27 @@ func (lm *LockManager) AcquireLock(ctx context.Context, name string) (*Lock, err
 
 // tryAcquireLock is implemented in platform-specific files (lock_unix.go, lock_windows.go)
 
+// Name returns the lock name ("global" or a task ID)
+func (l *Lock) Name() string {
+	return strings.TrimSuffix(strings.TrimSuffix(filepath.Base(l.path), ".exclusive"), ".lock")
+}
+
+// Held reports whether the lock has not been released yet
+func (l *Lock) Held() bool {
+	return l != nil && l.file != nil
+}
+
 // Release releases the lock
 func (l *Lock) Release() error {
 	if l.file == nil {
 		return nil
 	}
 
+	// Clear the owner metadata while still holding the lock
+	if filepath.Ext(l.path) == ".lock" {
+		_ = l.file.Truncate(0)
+	}
+
 	// Platform-specific unlock
 	if err := releaseLock(l); err != nil {
 		return err
@@ -121,35 +226,18 @@ func (l *Lock) Release() error {
 
 // releaseLock is implemented in platform-specific files (lock_unix.go, lock_windows.go)
 
-// Cleanup removes stale lock files
+// Cleanup cleans up stale lock files (see FindStale and RemoveStale)
 // This should be called during prune operations
 func (lm *LockManager) Cleanup() error {
-	entries, err := os.ReadDir(lm.locksDir)
+	stale, err := lm.FindStale()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("failed to read locks directory: %w", err)
+		return err
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		lockPath := filepath.Join(lm.locksDir, entry.Name())
-
-		// Try to acquire the lock
-		lock, err := tryAcquireLock(lockPath)
-		if err == nil {
-			// Lock was available, so it was stale - release it
-			_ = lock.Release()
-			// Remove the lock file if it's not in use
-			if filepath.Ext(lockPath) == ".lock" {
-				_ = os.Remove(lockPath)
-			}
+	for _, s := range stale {
+		if _, err := lm.RemoveStale(s); err != nil {
+			return err
 		}
-		// If we can't acquire it, it's in use - leave it alone
 	}
 
 	return nil
@@ -2,7 +2,10 @@ package lock
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -189,6 +192,32 @@ func TestLockTimeout(t *testing.T) {
 	}
 }
 
+func TestLockHeldErrorReportsHolder(t *testing.T) {
+	tempDir := t.TempDir()
+
+	lm := NewLockManagerWithTimeout(tempDir, 200*time.Millisecond)
+
+	lock, err := lm.AcquireTask(context.Background(), "task-1")
+	if err != nil {
+		t.Fatalf("failed to acquire lock: %v", err)
+	}
+
+	_, err = lm.AcquireTask(context.Background(), "task-1")
+	var held *HeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("expected HeldError, got %v", err)
+	}
+	if want := fmt.Sprintf("pid %d", os.Getpid()); !strings.HasPrefix(held.Holder, want) {
+		t.Errorf("holder = %q, want prefix %q", held.Holder, want)
+	}
+
+	// Released locks no longer report a holder
+	_ = lock.Release()
+	if holder := lm.Holder("task-1"); holder != "" {
+		t.Errorf("holder after release = %q, want empty", holder)
+	}
+}
+
 func TestLockCleanup(t *testing.T) {
 	// Create temp directory for testing
 	tempDir, err := os.MkdirTemp("", "awt-lock-test-*")
@@ -47,10 +47,6 @@ func tryAcquireLock(lockPath string) (*Lock, error) {
 		return nil, fmt.Errorf("failed to create exclusive lock: %w", err)
 	}
 
-	// Write PID to lock file for debugging
-	pid := os.Getpid()
-	_, _ = fmt.Fprintf(exclusiveFile, "%d\n", pid)
-
 	return &Lock{
 		path: exclusivePath,
 		file: exclusiveFile,
@@ -63,3 +59,12 @@ func releaseLock(l *Lock) error {
 	_ = unix.Flock(int(l.file.Fd()), unix.LOCK_UN)
 	return nil
 }
+
+// processAlive reports whether a process with the given PID exists on this host
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := unix.Kill(pid, 0)
+	return err == nil || err == unix.EPERM
+}
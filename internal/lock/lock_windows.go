@@ -21,10 +21,6 @@ func tryAcquireLock(lockPath string) (*Lock, error) {
 		return nil, fmt.Errorf("failed to create exclusive lock: %w", err)
 	}
 
-	// Write PID to lock file for debugging
-	pid := os.Getpid()
-	_, _ = fmt.Fprintf(exclusiveFile, "%d\n", pid)
-
 	return &Lock{
 		path: exclusivePath,
 		file: exclusiveFile,
@@ -36,3 +32,17 @@ func releaseLock(l *Lock) error {
 	// No platform-specific unlock needed on Windows
 	return nil
 }
+
+// processAlive reports whether a process with the given PID exists on this host
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	// On Windows, FindProcess opens a handle and fails if the process does not exist
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
@@ -0,0 +1,239 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoLock is returned by Break when no lock file exists for the given name
+var ErrNoLock = errors.New("no such lock")
+
+// Owner describes the process holding a lock. It is recorded in the lock file while held.
+type Owner struct {
+	// PID is the process ID of the holder
+	PID int `json:"pid"`
+
+	// Hostname is the host the holder runs on
+	Hostname string `json:"hostname,omitempty"`
+
+	// Command is the holder's command line
+	Command string `json:"command,omitempty"`
+
+	// TaskID is the task the lock protects (empty for the global lock)
+	TaskID string `json:"task_id,omitempty"`
+
+	// AcquiredAt is when the lock was acquired
+	AcquiredAt time.Time `json:"acquired_at,omitempty"`
+}
+
+// newOwner returns owner metadata for the current process
+func newOwner(taskID string) *Owner {
+	hostname, _ := os.Hostname()
+	return &Owner{
+		PID:        os.Getpid(),
+		Hostname:   hostname,
+		Command:    strings.Join(os.Args, " "),
+		TaskID:     taskID,
+		AcquiredAt: time.Now(),
+	}
+}
+
+// String returns a short human-readable description of the owner
+func (o *Owner) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("pid %d", o.PID))
+	if o.Hostname != "" {
+		sb.WriteString(" on " + o.Hostname)
+	}
+	if o.Command != "" {
+		sb.WriteString(fmt.Sprintf(" (%s)", o.Command))
+	}
+	if !o.AcquiredAt.IsZero() {
+		sb.WriteString(" since " + o.AcquiredAt.Format("2006-01-02 15:04:05"))
+	}
+	return sb.String()
+}
+
+// IsLocal reports whether the owner runs on this host
+func (o *Owner) IsLocal() bool {
+	if o.Hostname == "" {
+		// Older lock files only recorded a PID, always on the local host
+		return true
+	}
+	hostname, err := os.Hostname()
+	return err == nil && hostname == o.Hostname
+}
+
+// Alive reports whether the owning process is still running.
+// The second result is false if liveness cannot be determined (owner on another host).
+func (o *Owner) Alive() (alive bool, known bool) {
+	if !o.IsLocal() {
+		return false, false
+	}
+	return processAlive(o.PID), true
+}
+
+// readOwner reads the owner metadata from a lock file.
+// Returns nil without error for an empty file. A bare PID (the older format) is also accepted.
+func readOwner(path string) (*Owner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	content := strings.TrimSpace(string(data))
+	if content == "" {
+		return nil, nil
+	}
+
+	var owner Owner
+	if err := json.Unmarshal([]byte(content), &owner); err == nil {
+		return &owner, nil
+	}
+
+	pid, err := strconv.Atoi(content)
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized lock file content in %s", path)
+	}
+	return &Owner{PID: pid}, nil
+}
+
+// Status describes a lock file and its holder
+type Status struct {
+	// Name is the lock name ("global" or a task ID)
+	Name string `json:"name"`
+
+	// Path is the lock file path
+	Path string `json:"path"`
+
+	// Exclusive is true for the O_EXCL fallback lock (used where flock is unavailable)
+	Exclusive bool `json:"exclusive,omitempty"`
+
+	// Held is true if the lock is currently held
+	Held bool `json:"held"`
+
+	// Owner is the recorded holder, if any
+	Owner *Owner `json:"owner,omitempty"`
+
+	// OwnerAlive reports whether the holder's process is running (nil if it cannot be determined)
+	OwnerAlive *bool `json:"owner_alive,omitempty"`
+}
+
+// Status inspects a single lock file without waiting.
+// A flock-based lock is probed with a non-blocking tryAcquireLock; an .exclusive lock is held while it exists.
+func (lm *LockManager) Status(fileName string) (*Status, error) {
+	path := filepath.Join(lm.locksDir, fileName)
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	status := &Status{Path: path}
+	switch {
+	case strings.HasSuffix(fileName, ".lock.exclusive"):
+		status.Name = strings.TrimSuffix(fileName, ".lock.exclusive")
+		status.Exclusive = true
+		status.Held = true
+	case strings.HasSuffix(fileName, ".lock"):
+		status.Name = strings.TrimSuffix(fileName, ".lock")
+		if l, err := tryAcquireLock(path); err == nil {
+			_ = l.Release()
+		} else {
+			status.Held = true
+		}
+	default:
+		return nil, fmt.Errorf("not a lock file: %s", fileName)
+	}
+
+	if status.Held {
+		status.Owner, _ = readOwner(path)
+		if status.Owner != nil {
+			if alive, known := status.Owner.Alive(); known {
+				status.OwnerAlive = &alive
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// List returns the status of every lock file, sorted by name
+func (lm *LockManager) List() ([]*Status, error) {
+	entries, err := os.ReadDir(lm.locksDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read locks directory: %w", err)
+	}
+
+	var statuses []*Status
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		status, err := lm.Status(entry.Name())
+		if err != nil {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses, nil
+}
+
+// Break force-releases the named lock by removing its lock files.
+// It refuses unless the owning process is known to be dead; force skips that check
+// (a live flock holder keeps its lock on the unlinked file, so only use it on hung processes).
+// Returns the statuses of the lock files that were removed.
+func (lm *LockManager) Break(name string, force bool) ([]*Status, error) {
+	var broken []*Status
+
+	for _, fileName := range []string{name + ".lock.exclusive", name + ".lock"} {
+		status, err := lm.Status(fileName)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return broken, err
+		}
+
+		if status.Held && !force {
+			if status.OwnerAlive == nil {
+				return broken, &HeldError{Name: name, Holder: describeOwner(status.Owner), Reason: "which cannot be checked from this host"}
+			}
+			if *status.OwnerAlive {
+				return broken, &HeldError{Name: name, Holder: describeOwner(status.Owner), Reason: "which is still running"}
+			}
+		}
+
+		if err := os.Remove(status.Path); err != nil && !os.IsNotExist(err) {
+			return broken, fmt.Errorf("failed to remove lock file: %w", err)
+		}
+		broken = append(broken, status)
+	}
+
+	if len(broken) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoLock, name)
+	}
+
+	return broken, nil
+}
+
+// describeOwner describes a possibly unknown owner
+func describeOwner(o *Owner) string {
+	if o == nil {
+		return "an unknown process"
+	}
+	return o.String()
+}
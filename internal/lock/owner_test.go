@@ -0,0 +1,142 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// deadPID is a PID that is far above any real pid_max
+const deadPID = 1 << 30
+
+func TestLockRecordsOwner(t *testing.T) {
+	tempDir := t.TempDir()
+	lm := NewLockManager(tempDir)
+
+	l, err := lm.AcquireTask(context.Background(), "task-1")
+	if err != nil {
+		t.Fatalf("failed to acquire task lock: %v", err)
+	}
+	defer func() {
+		_ = l.Release()
+	}()
+
+	owner := lm.Owner("task-1")
+	if owner == nil {
+		t.Fatal("expected owner metadata while lock is held")
+	}
+	if owner.PID != os.Getpid() {
+		t.Errorf("PID = %d, want %d", owner.PID, os.Getpid())
+	}
+	if owner.TaskID != "task-1" {
+		t.Errorf("TaskID = %q, want %q", owner.TaskID, "task-1")
+	}
+	if owner.AcquiredAt.IsZero() {
+		t.Error("AcquiredAt not recorded")
+	}
+	if alive, known := owner.Alive(); !known || !alive {
+		t.Errorf("Alive() = %v, %v, want true, true", alive, known)
+	}
+}
+
+func TestLockList(t *testing.T) {
+	tempDir := t.TempDir()
+	lm := NewLockManager(tempDir)
+	ctx := context.Background()
+
+	held, err := lm.AcquireGlobal(ctx)
+	if err != nil {
+		t.Fatalf("failed to acquire global lock: %v", err)
+	}
+	defer func() {
+		_ = held.Release()
+	}()
+
+	free, err := lm.AcquireTask(ctx, "task-1")
+	if err != nil {
+		t.Fatalf("failed to acquire task lock: %v", err)
+	}
+	if err := free.Release(); err != nil {
+		t.Fatalf("failed to release task lock: %v", err)
+	}
+
+	statuses, err := lm.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 lock files, got %d", len(statuses))
+	}
+
+	if statuses[0].Name != "global" || !statuses[0].Held || statuses[0].Owner == nil {
+		t.Errorf("global lock status = %+v, want held with owner", statuses[0])
+	}
+	if statuses[1].Name != "task-1" || statuses[1].Held {
+		t.Errorf("task lock status = %+v, want free", statuses[1])
+	}
+}
+
+func TestLockBreakDeadOwner(t *testing.T) {
+	tempDir := t.TempDir()
+	lm := NewLockManager(tempDir)
+
+	// An O_EXCL lock left behind by a process that has exited
+	hostname, _ := os.Hostname()
+	data, _ := json.Marshal(&Owner{PID: deadPID, Hostname: hostname})
+	path := filepath.Join(lm.locksDir, "global.lock.exclusive")
+	if err := os.MkdirAll(lm.locksDir, 0755); err != nil {
+		t.Fatalf("failed to create locks dir: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+
+	status, err := lm.Status("global.lock.exclusive")
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	if !status.Held || status.OwnerAlive == nil || *status.OwnerAlive {
+		t.Fatalf("status = %+v, want held by a dead owner", status)
+	}
+
+	removed, err := lm.Break("global", false)
+	if err != nil {
+		t.Fatalf("Break() failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Owner.PID != deadPID {
+		t.Errorf("unexpected removed locks: %+v", removed)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("lock file still exists after Break()")
+	}
+
+	if _, err := lm.Break("global", false); !errors.Is(err, ErrNoLock) {
+		t.Errorf("expected ErrNoLock breaking a missing lock, got %v", err)
+	}
+}
+
+func TestLockBreakLiveOwner(t *testing.T) {
+	tempDir := t.TempDir()
+	lm := NewLockManager(tempDir)
+
+	l, err := lm.AcquireTask(context.Background(), "task-1")
+	if err != nil {
+		t.Fatalf("failed to acquire task lock: %v", err)
+	}
+	defer func() {
+		_ = l.Release()
+	}()
+
+	_, err = lm.Break("task-1", false)
+	var held *HeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("expected HeldError breaking a live lock, got %v", err)
+	}
+
+	if _, err := lm.Break("task-1", true); err != nil {
+		t.Errorf("Break() with force failed: %v", err)
+	}
+}
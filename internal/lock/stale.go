@@ -0,0 +1,160 @@
+package lock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// StaleOwnerExited is the reason a lock file is judged stale: it was left behind
+// by a process that is no longer running
+const StaleOwnerExited = "owner_exited"
+
+// StaleLock describes a lock file that no live process holds
+type StaleLock struct {
+	// Name is the lock name ("global" or a task ID)
+	Name string `json:"name"`
+
+	// File is the lock file name within the locks directory
+	File string `json:"file"`
+
+	// Exclusive is true for the O_EXCL fallback lock
+	Exclusive bool `json:"exclusive,omitempty"`
+
+	// Reason is why the lock was judged stale (StaleOwnerExited)
+	Reason string `json:"reason"`
+
+	// Owner is the last recorded holder, if any
+	Owner *Owner `json:"owner,omitempty"`
+}
+
+// Describe returns a human-readable explanation of why the lock is stale
+func (s *StaleLock) Describe() string {
+	if s.Owner != nil {
+		return fmt.Sprintf("holder pid %d is no longer running", s.Owner.PID)
+	}
+	return "holder is no longer running"
+}
+
+// FindStale returns the lock files left behind by holders that exited, sorted by file name.
+//
+// A flock-based .lock file is probed with a non-blocking tryAcquireLock; it is stale if the
+// probe succeeds and the file still records an owner, which only happens when the holder
+// exited without releasing. An unheld lock file without an owner is idle, not stale. An
+// .exclusive lock file is held for as long as it exists, so it is only stale if its recorded
+// owner ran on this host and is no longer running. Lock files whose holder cannot be checked
+// are left alone.
+func (lm *LockManager) FindStale() ([]*StaleLock, error) {
+	entries, err := os.ReadDir(lm.locksDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read locks directory: %w", err)
+	}
+
+	var stale []*StaleLock
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		s, err := lm.checkStale(entry.Name(), false)
+		if err != nil || s == nil {
+			continue
+		}
+		stale = append(stale, s)
+	}
+
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].File < stale[j].File
+	})
+
+	return stale, nil
+}
+
+// RemoveStale cleans up a lock file reported by FindStale.
+// The lock is checked again first, so a lock acquired since FindStale ran is left alone.
+// An .exclusive lock file is removed. A flock-based lock file is kept and only its owner
+// metadata cleared while holding the lock: unlinking it would let a process that opened
+// the old file and one that creates a new file both hold the lock.
+// Returns false without error if the lock is no longer stale.
+func (lm *LockManager) RemoveStale(s *StaleLock) (bool, error) {
+	current, err := lm.checkStale(s.File, true)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return current != nil, nil
+}
+
+// checkStale checks whether a single lock file is stale, and cleans it up if clean is set.
+// Returns nil if the lock is idle, in use or its holder cannot be checked.
+func (lm *LockManager) checkStale(fileName string, clean bool) (*StaleLock, error) {
+	path := filepath.Join(lm.locksDir, fileName)
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.HasSuffix(fileName, ".lock.exclusive"):
+		owner, _ := readOwner(path)
+		if owner == nil {
+			return nil, nil
+		}
+		if alive, known := owner.Alive(); !known || alive {
+			return nil, nil
+		}
+		if clean {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove lock file: %w", err)
+			}
+		}
+		return &StaleLock{
+			Name:      strings.TrimSuffix(fileName, ".lock.exclusive"),
+			File:      fileName,
+			Exclusive: true,
+			Reason:    StaleOwnerExited,
+			Owner:     owner,
+		}, nil
+
+	case strings.HasSuffix(fileName, ".lock"):
+		l, err := tryAcquireLock(path)
+		if err != nil {
+			// Held by a live process
+			return nil, nil
+		}
+
+		// Without flock support the probe took the .exclusive fallback instead
+		if l.path != path {
+			_ = l.Release()
+			return nil, nil
+		}
+
+		// Holders clear the owner before unlocking, so one is only left in the
+		// file if its holder exited without releasing
+		owner, _ := readOwner(path)
+		if owner == nil || !clean {
+			// Unlock without Release, which would clear the owner
+			_ = releaseLock(l)
+			_ = l.file.Close()
+			if owner == nil {
+				return nil, nil
+			}
+		} else if err := l.Release(); err != nil {
+			return nil, err
+		}
+		return &StaleLock{
+			Name:   strings.TrimSuffix(fileName, ".lock"),
+			File:   fileName,
+			Reason: StaleOwnerExited,
+			Owner:  owner,
+		}, nil
+
+	default:
+		return nil, nil
+	}
+}
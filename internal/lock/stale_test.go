@@ -0,0 +1,105 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindStale(t *testing.T) {
+	tempDir := t.TempDir()
+	lm := NewLockManager(tempDir)
+	ctx := context.Background()
+
+	// A held flock lock is never stale, even though it may look empty
+	held, err := lm.AcquireGlobal(ctx)
+	if err != nil {
+		t.Fatalf("failed to acquire global lock: %v", err)
+	}
+	defer func() {
+		_ = held.Release()
+	}()
+
+	// A released flock lock is idle, not stale
+	released, err := lm.AcquireTask(ctx, "released")
+	if err != nil {
+		t.Fatalf("failed to acquire task lock: %v", err)
+	}
+	if err := released.Release(); err != nil {
+		t.Fatalf("failed to release task lock: %v", err)
+	}
+
+	// .exclusive locks are stale only when their owner has exited
+	hostname, _ := os.Hostname()
+	writeExclusive := func(name string, pid int) {
+		data, _ := json.Marshal(&Owner{PID: pid, Hostname: hostname})
+		if err := os.WriteFile(filepath.Join(lm.locksDir, name+".lock.exclusive"), data, 0644); err != nil {
+			t.Fatalf("failed to write lock file: %v", err)
+		}
+	}
+	writeExclusive("dead", deadPID)
+	writeExclusive("live", os.Getpid())
+
+	// A flock lock whose holder exited without releasing still records its owner
+	crashed, _ := json.Marshal(&Owner{PID: deadPID, Hostname: hostname})
+	if err := os.WriteFile(filepath.Join(lm.locksDir, "crashed.lock"), crashed, 0644); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+
+	stale, err := lm.FindStale()
+	if err != nil {
+		t.Fatalf("FindStale() failed: %v", err)
+	}
+
+	reasons := make(map[string]string)
+	for _, s := range stale {
+		reasons[s.File] = s.Reason
+	}
+	want := map[string]string{
+		"dead.lock.exclusive": StaleOwnerExited,
+		"crashed.lock":        StaleOwnerExited,
+	}
+	if len(reasons) != len(want) {
+		t.Fatalf("stale locks = %v, want %v", reasons, want)
+	}
+	for file, reason := range want {
+		if reasons[file] != reason {
+			t.Errorf("reason for %s = %q, want %q", file, reasons[file], reason)
+		}
+	}
+
+	// Finding stale locks does not change them
+	if owner := lm.Owner("crashed"); owner == nil || owner.PID != deadPID {
+		t.Errorf("owner of crashed lock = %v, want it kept by FindStale", owner)
+	}
+
+	// Removing the stale locks leaves the held ones alone
+	for _, s := range stale {
+		removed, err := lm.RemoveStale(s)
+		if err != nil || !removed {
+			t.Errorf("RemoveStale(%s) = %v, %v, want true, nil", s.File, removed, err)
+		}
+	}
+	for _, file := range []string{"global.lock", "live.lock.exclusive"} {
+		if _, err := os.Stat(filepath.Join(lm.locksDir, file)); err != nil {
+			t.Errorf("held lock %s was removed: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(lm.locksDir, "dead.lock.exclusive")); !os.IsNotExist(err) {
+		t.Errorf("stale exclusive lock was not removed: %v", err)
+	}
+
+	// A flock lock file is never unlinked, only its owner cleared
+	info, err := os.Stat(filepath.Join(lm.locksDir, "crashed.lock"))
+	if err != nil {
+		t.Fatalf("flock lock file was removed: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("owner of crashed lock was not cleared")
+	}
+	if stale, err := lm.FindStale(); err != nil || len(stale) != 0 {
+		t.Errorf("FindStale() after RemoveStale = %v, %v, want none", stale, err)
+	}
+}
@@ -0,0 +1,195 @@
+package oplog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/idgen"
+	"github.com/kernel-labs-ai/awt/internal/task"
+)
+
+// Operation records the state of a task before a mutating command acted on it,
+// so the command can be undone
+type Operation struct {
+	// ID identifies the operation (YYYYmmdd-HHMMSS-<6random>)
+	ID string `json:"id"`
+
+	// Time is when the operation started
+	Time time.Time `json:"time"`
+
+	// Command is the AWT command that ran (e.g. "task sync")
+	Command string `json:"command"`
+
+	// Actor is who ran the command
+	Actor string `json:"actor,omitempty"`
+
+	// TaskID is the task the command acted on
+	TaskID string `json:"task_id"`
+
+	// Branch is the task branch (without refs/heads/)
+	Branch string `json:"branch"`
+
+	// BranchOID is the commit the branch pointed at (empty if it did not exist)
+	BranchOID string `json:"branch_oid,omitempty"`
+
+	// WorktreePath is the task worktree (empty if there was none)
+	WorktreePath string `json:"worktree_path,omitempty"`
+
+	// Head is the commit checked out in the worktree
+	Head string `json:"head,omitempty"`
+
+	// HeadRef is the branch checked out in the worktree (empty if HEAD was detached)
+	HeadRef string `json:"head_ref,omitempty"`
+
+	// Task is the task metadata snapshot (absent if the task did not exist yet)
+	Task json.RawMessage `json:"task,omitempty"`
+
+	// UndoOf is the ID of the operation this one undid (empty for regular commands)
+	UndoOf string `json:"undo_of,omitempty"`
+}
+
+// Snapshot decodes the task metadata snapshot, or returns nil if the task did not exist
+func (op *Operation) Snapshot() (*task.Task, error) {
+	if len(op.Task) == 0 {
+		return nil, nil
+	}
+	return task.DecodeTask(op.Task)
+}
+
+// Log is the repository's operation log, an append-only JSONL file at .git/awt/oplog.jsonl
+type Log struct {
+	path string
+}
+
+// NewLog creates the operation log for the repository at gitCommonDir
+func NewLog(gitCommonDir string) *Log {
+	return &Log{path: filepath.Join(gitCommonDir, "awt", "oplog.jsonl")}
+}
+
+// Record assigns the operation an ID and time and appends it to the log.
+// Each operation is written with a single append, so concurrent writers do not interleave.
+func (l *Log) Record(op *Operation) error {
+	id, err := idgen.GenerateTaskID()
+	if err != nil {
+		return fmt.Errorf("failed to generate operation ID: %w", err)
+	}
+	op.ID = id
+	op.Time = time.Now()
+
+	data, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create awt directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open operation log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write operation: %w", err)
+	}
+	return f.Close()
+}
+
+// List returns all operations, oldest first. Lines that fail to decode are skipped.
+func (l *Log) List() ([]*Operation, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Operation{}, nil
+		}
+		return nil, fmt.Errorf("failed to open operation log: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	ops := []*Operation{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var op Operation
+		if err := json.Unmarshal([]byte(line), &op); err != nil {
+			continue
+		}
+		ops = append(ops, &op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read operation log: %w", err)
+	}
+
+	return ops, nil
+}
+
+// Get returns the operation with the given ID
+func (l *Log) Get(id string) (*Operation, error) {
+	ops, err := l.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		if op.ID == id {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("operation not found: %s", id)
+}
+
+// UndoneBy maps the ID of each undone operation to the ID of the operation that undid it.
+// An undo that was itself undone (a redo) no longer counts.
+func UndoneBy(ops []*Operation) map[string]string {
+	undoOf := map[string]string{}
+	for _, op := range ops {
+		if op.UndoOf != "" {
+			undoOf[op.UndoOf] = op.ID
+		}
+	}
+
+	var isUndone func(id string, depth int) bool
+	isUndone = func(id string, depth int) bool {
+		by, ok := undoOf[id]
+		if !ok || depth > len(ops) {
+			return false
+		}
+		return !isUndone(by, depth+1)
+	}
+
+	undone := map[string]string{}
+	for id, by := range undoOf {
+		if isUndone(id, 0) {
+			undone[id] = by
+		}
+	}
+	return undone
+}
+
+// LastUndoable returns the most recent operation that is not an undo, has not been undone
+// and is accepted by canUndo (nil accepts every operation), or nil if there is none.
+// Repeated undos therefore walk back through the log.
+func (l *Log) LastUndoable(canUndo func(*Operation) bool) (*Operation, error) {
+	ops, err := l.List()
+	if err != nil {
+		return nil, err
+	}
+	undone := UndoneBy(ops)
+	for i := len(ops) - 1; i >= 0; i-- {
+		op := ops[i]
+		if op.UndoOf == "" && undone[op.ID] == "" && (canUndo == nil || canUndo(op)) {
+			return op, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,73 @@
+package oplog
+
+import "testing"
+
+func TestLogUndoAndRedo(t *testing.T) {
+	log := NewLog(t.TempDir())
+
+	first := &Operation{Command: "task start", TaskID: "task-1"}
+	second := &Operation{Command: "task commit", TaskID: "task-1", Task: []byte(`{"id":"task-1","agent":"a","title":"t","branch":"b","base":"main","created_at":"2025-01-10T12:00:00Z","state":"ACTIVE","worktree_path":""}`)}
+	for _, op := range []*Operation{first, second} {
+		if err := log.Record(op); err != nil {
+			t.Fatalf("Record() failed: %v", err)
+		}
+	}
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("operations got IDs %q and %q", first.ID, second.ID)
+	}
+
+	if snap, err := first.Snapshot(); err != nil || snap != nil {
+		t.Errorf("Snapshot() of a creating operation = %v, %v, want nil", snap, err)
+	}
+	if snap, err := second.Snapshot(); err != nil || snap.ID != "task-1" || snap.SchemaVersion == 0 {
+		t.Errorf("Snapshot() = %+v, %v", snap, err)
+	}
+
+	last, err := log.LastUndoable(nil)
+	if err != nil || last.ID != second.ID {
+		t.Fatalf("LastUndoable() = %v, %v, want %s", last, err, second.ID)
+	}
+
+	// Undoing the commit makes the start the next undoable operation
+	undo := &Operation{Command: "undo", TaskID: "task-1", UndoOf: second.ID}
+	if err := log.Record(undo); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+	if last, _ := log.LastUndoable(nil); last == nil || last.ID != first.ID {
+		t.Errorf("LastUndoable() after undo = %v, want %s", last, first.ID)
+	}
+
+	// Undoing the undo redoes the commit
+	redo := &Operation{Command: "undo", TaskID: "task-1", UndoOf: undo.ID}
+	if err := log.Record(redo); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+	ops, err := log.List()
+	if err != nil || len(ops) != 4 {
+		t.Fatalf("List() = %d operations, %v, want 4", len(ops), err)
+	}
+	undone := UndoneBy(ops)
+	if undone[second.ID] != "" || undone[undo.ID] != redo.ID {
+		t.Errorf("UndoneBy() = %v, want only %s undone by %s", undone, undo.ID, redo.ID)
+	}
+	if last, _ := log.LastUndoable(nil); last == nil || last.ID != second.ID {
+		t.Errorf("LastUndoable() after redo = %v, want %s", last, second.ID)
+	}
+}
+
+func TestLastUndoableSkipsRejectedOperations(t *testing.T) {
+	log := NewLog(t.TempDir())
+
+	first := &Operation{Command: "task start", TaskID: "task-1"}
+	second := &Operation{Command: "task handoff", TaskID: "task-2"}
+	for _, op := range []*Operation{first, second} {
+		if err := log.Record(op); err != nil {
+			t.Fatalf("Record() failed: %v", err)
+		}
+	}
+
+	canUndo := func(op *Operation) bool { return op.TaskID != "task-2" }
+	if last, err := log.LastUndoable(canUndo); err != nil || last == nil || last.ID != first.ID {
+		t.Errorf("LastUndoable() = %v, %v, want %s", last, err, first.ID)
+	}
+}
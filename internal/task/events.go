@@ -0,0 +1,212 @@
+package task
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EventType identifies what happened to a task
+type EventType string
+
+const (
+	// EventStart is recorded when a task is started or adopted
+	EventStart EventType = "start"
+	// EventCommit is recorded for each commit made with 'awt task commit'
+	EventCommit EventType = "commit"
+	// EventSync is recorded when the task branch is rebased or merged onto its base
+	EventSync EventType = "sync"
+	// EventPush is recorded when the task branch is pushed
+	EventPush EventType = "push"
+	// EventPR is recorded when a pull request is created (or a compare URL generated)
+	EventPR EventType = "pr"
+	// EventExec is recorded for each command run with 'awt task exec'
+	EventExec EventType = "exec"
+	// EventHandoff is recorded when the task is handed off
+	EventHandoff EventType = "handoff"
+	// EventAbandon is recorded when the task is abandoned
+	EventAbandon EventType = "abandon"
+	// EventReopen is recorded when the task is reopened
+	EventReopen EventType = "reopen"
+	// EventMerged is recorded when reconcile detects the task was merged
+	EventMerged EventType = "merged"
+	// EventUndo is recorded when 'awt undo' restores the task
+	EventUndo EventType = "undo"
+	// EventCheckpoint is recorded when a worktree checkpoint is saved
+	EventCheckpoint EventType = "checkpoint"
+	// EventRestore is recorded when the worktree is restored from a checkpoint
+	EventRestore EventType = "restore"
+	// EventFeedback is recorded when PR review feedback is written into the worktree
+	EventFeedback EventType = "feedback"
+)
+
+// Event results
+const (
+	ResultOK       = "ok"
+	ResultFailed   = "failed"
+	ResultConflict = "conflict"
+)
+
+// Event is one entry in a task's event log. Fields other than Time, Type and Actor
+// are set depending on the event type.
+type Event struct {
+	// Time is when the event happened
+	Time time.Time `json:"time"`
+
+	// Type is what happened
+	Type EventType `json:"type"`
+
+	// Actor is who ran the command (see Transition.Actor)
+	Actor string `json:"actor,omitempty"`
+
+	// SHA is the resulting commit (commit, sync, push, merged)
+	SHA string `json:"sha,omitempty"`
+
+	// Strategy is the sync strategy (rebase or merge) or the merge detection method
+	Strategy string `json:"strategy,omitempty"`
+
+	// Result is ResultOK, ResultFailed or ResultConflict
+	Result string `json:"result,omitempty"`
+
+	// Remote is the remote pushed to
+	Remote string `json:"remote,omitempty"`
+
+	// URL is the pull request or compare URL
+	URL string `json:"url,omitempty"`
+
+	// Command is the command line run by exec
+	Command string `json:"command,omitempty"`
+
+	// ExitCode is the exit code of an exec command
+	ExitCode *int `json:"exit_code,omitempty"`
+
+	// Message is free-form detail: a commit subject, an abandon reason or an error
+	Message string `json:"message,omitempty"`
+}
+
+// Summary returns a one-line description of the event for timelines
+func (e *Event) Summary() string {
+	var parts []string
+	switch e.Type {
+	case EventExec:
+		parts = append(parts, e.Command)
+		if e.ExitCode != nil {
+			parts = append(parts, fmt.Sprintf("(exit %d)", *e.ExitCode))
+		}
+	case EventSync:
+		parts = append(parts, e.Strategy)
+	case EventPush:
+		parts = append(parts, "to "+e.Remote)
+	case EventMerged:
+		parts = append(parts, "via "+e.Strategy)
+	}
+	if e.SHA != "" {
+		parts = append(parts, shortSHA(e.SHA))
+	}
+	if e.URL != "" {
+		parts = append(parts, e.URL)
+	}
+	if e.Result != "" && e.Result != ResultOK {
+		parts = append(parts, e.Result)
+	}
+	if e.Message != "" {
+		parts = append(parts, e.Message)
+	}
+	return strings.Join(parts, " ")
+}
+
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+// EventLog is the per-task event journal: one JSONL file per task next to the
+// task metadata (.git/awt/tasks/<id>.events.jsonl), whatever the task store backend
+type EventLog struct {
+	dir string
+}
+
+// NewEventLog creates an event log for the repository at gitCommonDir
+func NewEventLog(gitCommonDir string) *EventLog {
+	return &EventLog{dir: filepath.Join(gitCommonDir, "awt", "tasks")}
+}
+
+// Path returns the journal file for a task
+func (l *EventLog) Path(taskID string) string {
+	return filepath.Join(l.dir, taskID+".events.jsonl")
+}
+
+// Append adds an event to the task's journal. Each event is written with a single
+// append, so concurrent writers do not interleave within a line.
+func (l *EventLog) Append(taskID string, e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+	f, err := os.OpenFile(l.Path(taskID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return f.Close()
+}
+
+// Read returns the task's events, oldest first. A task without a journal has no events.
+// Lines that fail to decode (e.g. a write cut short) are skipped.
+func (l *EventLog) Read(taskID string) ([]Event, error) {
+	f, err := os.Open(l.Path(taskID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Event{}, nil
+		}
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	events := []Event{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event log: %w", err)
+	}
+
+	return events, nil
+}
+
+// Delete removes the task's journal
+func (l *EventLog) Delete(taskID string) error {
+	if err := os.Remove(l.Path(taskID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete event log: %w", err)
+	}
+	return nil
+}
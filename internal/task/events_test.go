@@ -0,0 +1,58 @@
+package task
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEventLogAppendAndRead(t *testing.T) {
+	log := NewEventLog(t.TempDir())
+
+	events, err := log.Read("task-1")
+	if err != nil || len(events) != 0 {
+		t.Fatalf("Read() of a missing log = %v, %v, want no events", events, err)
+	}
+
+	exitCode := 2
+	for _, e := range []Event{
+		{Type: EventStart, Actor: "claude"},
+		{Type: EventCommit, SHA: "0123456789abcdef", Message: "Add feature"},
+		{Type: EventExec, Command: "go test ./...", ExitCode: &exitCode},
+	} {
+		if err := log.Append("task-1", e); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+
+	// A torn final line is skipped rather than failing the whole log
+	f, err := os.OpenFile(log.Path("task-1"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open log: %v", err)
+	}
+	_, _ = f.WriteString(`{"type":"sy`)
+	_ = f.Close()
+
+	events, err = log.Read("task-1")
+	if err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Read() = %d events, want 3", len(events))
+	}
+	if events[0].Type != EventStart || events[0].Time.IsZero() {
+		t.Errorf("first event = %+v, want a timestamped start event", events[0])
+	}
+	if got := events[1].Summary(); got != "0123456789ab Add feature" {
+		t.Errorf("commit summary = %q", got)
+	}
+	if got := events[2].Summary(); got != "go test ./... (exit 2)" {
+		t.Errorf("exec summary = %q", got)
+	}
+
+	if err := log.Delete("task-1"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if events, _ := log.Read("task-1"); len(events) != 0 {
+		t.Errorf("deleted log still has %d events", len(events))
+	}
+}
@@ -0,0 +1,554 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/git"
+)
+
+const (
+	// gitRefsPrefix is the namespace holding one blob ref per task
+	gitRefsPrefix = "refs/awt/tasks/"
+
+	// gitSaveRetries bounds the compare-and-swap retries when a ref moves during Save
+	gitSaveRetries = 5
+)
+
+// gitRecord is the blob stored under refs/awt/tasks/<id>.
+// Updated records when each top-level task field last changed; it drives the
+// last-writer-wins-per-field merge of concurrent updates from other clones.
+// A deleted task keeps its ref as a tombstone, with Deleted set and no fields,
+// so the deletion is pushed and wins over older changes fetched from other clones.
+type gitRecord struct {
+	Task    map[string]json.RawMessage `json:"task"`
+	Updated map[string]time.Time       `json:"updated"`
+	Deleted *time.Time                 `json:"deleted,omitempty"`
+}
+
+// GitRefStore is a Store that keeps each task as a JSON blob under refs/awt/tasks/<id>,
+// so tasks can be pushed to and fetched from a remote like any other ref.
+// Only Fetch and Push take a context; the Store methods run local ref commands.
+type GitRefStore struct {
+	g *git.Git
+}
+
+// NewGitRefStore creates a git-refs task store for the repository at gitCommonDir
+func NewGitRefStore(gitCommonDir string) *GitRefStore {
+	return &GitRefStore{g: git.New(gitCommonDir, false)}
+}
+
+// WithTimeouts returns a copy of the store whose Fetch and Push apply the given git timeouts
+func (s *GitRefStore) WithTimeouts(timeouts git.Timeouts) *GitRefStore {
+	return &GitRefStore{g: s.g.WithTimeouts(timeouts)}
+}
+
+// Save writes the task at the current schema version, recording which fields changed.
+// The stored record must still be at task.Revision; the ref itself is updated with
+// compare-and-swap, so a concurrent writer is detected on the next read.
+func (s *GitRefStore) Save(task *Task) error {
+	ref := gitRefsPrefix + task.ID
+
+	for attempt := 0; attempt < gitSaveRetries; attempt++ {
+		oldID, stored, err := s.readRef(ref)
+		if err != nil {
+			return err
+		}
+		if stored != nil && stored.Deleted != nil {
+			// Saving a deleted task creates it anew over the tombstone
+			stored = nil
+		}
+
+		// Reject stale revisions and illegal state changes against the stored record
+		if stored != nil {
+			if prev, err := stored.decode(); err == nil {
+				if err := checkSave(task, prev); err != nil {
+					return err
+				}
+			}
+		}
+
+		task.SchemaVersion = CurrentSchemaVersion
+		task.Revision++
+		record, err := newGitRecord(task, stored, time.Now().UTC())
+		if err != nil {
+			task.Revision--
+			return err
+		}
+
+		err = s.writeRef(ref, record, oldID)
+		if err == nil {
+			return nil
+		}
+		task.Revision--
+		if !stderrors.Is(err, git.ErrRefChanged) {
+			return fmt.Errorf("failed to save task %s: %w", task.ID, err)
+		}
+	}
+
+	return fmt.Errorf("failed to save task %s: its ref kept changing", task.ID)
+}
+
+// Load loads a task from its ref
+func (s *GitRefStore) Load(taskID string) (*Task, error) {
+	_, record, err := s.readRef(gitRefsPrefix + taskID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.Deleted != nil {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+	return record.decode()
+}
+
+// List returns all tasks, ordered by ID
+func (s *GitRefStore) List() ([]*Task, error) {
+	refs, err := s.g.ForEachRef(context.Background(), gitRefsPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task refs: %w", err)
+	}
+
+	names := make([]string, 0, len(refs))
+	for ref := range refs {
+		names = append(names, ref)
+	}
+	sort.Strings(names)
+
+	tasks := []*Task{}
+	for _, ref := range names {
+		record, err := s.readBlob(refs[ref])
+		if err != nil {
+			// Skip unreadable records, like unreadable files in the JSON store
+			continue
+		}
+		if record.Deleted != nil {
+			continue
+		}
+		task, err := record.decode()
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// Find returns the tasks matching the filter
+func (s *GitRefStore) Find(filter Filter) ([]*Task, error) {
+	tasks, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := []*Task{}
+	for _, t := range tasks {
+		if filter.Matches(t) {
+			matched = append(matched, t)
+		}
+	}
+
+	return matched, nil
+}
+
+// FindByBranch returns the task that owns the given branch
+func (s *GitRefStore) FindByBranch(branch string) (*Task, error) {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+
+	tasks, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tasks {
+		if strings.TrimPrefix(t.Branch, "refs/heads/") == branch {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no task found for branch: %s", branch)
+}
+
+// Delete replaces the task's record with a tombstone, so that fetching the task
+// from a remote that still has it does not bring it back
+func (s *GitRefStore) Delete(taskID string) error {
+	ref := gitRefsPrefix + taskID
+
+	for attempt := 0; attempt < gitSaveRetries; attempt++ {
+		oldID, stored, err := s.readRef(ref)
+		if err != nil {
+			return fmt.Errorf("failed to delete task: %w", err)
+		}
+		if stored == nil || stored.Deleted != nil {
+			return nil
+		}
+
+		now := time.Now().UTC()
+		tombstone := &gitRecord{
+			Task:    map[string]json.RawMessage{},
+			Updated: map[string]time.Time{},
+			Deleted: &now,
+		}
+		err = s.writeRef(ref, tombstone, oldID)
+		if err == nil {
+			return nil
+		}
+		if !stderrors.Is(err, git.ErrRefChanged) {
+			return fmt.Errorf("failed to delete task: %w", err)
+		}
+	}
+
+	return fmt.Errorf("failed to delete task %s: its ref kept changing", taskID)
+}
+
+// Inspect reports on every task ref, including those that fail to load
+func (s *GitRefStore) Inspect() ([]RecordStatus, error) {
+	refs, err := s.g.ForEachRef(context.Background(), gitRefsPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task refs: %w", err)
+	}
+
+	names := make([]string, 0, len(refs))
+	for ref := range refs {
+		names = append(names, ref)
+	}
+	sort.Strings(names)
+
+	statuses := []RecordStatus{}
+	for _, ref := range names {
+		taskID := strings.TrimPrefix(ref, gitRefsPrefix)
+		record, err := s.readBlob(refs[ref])
+		if err != nil {
+			statuses = append(statuses, RecordStatus{ID: taskID, Source: ref, Error: err.Error()})
+			continue
+		}
+		if record.Deleted != nil {
+			continue
+		}
+		data, err := json.Marshal(record.Task)
+		if err != nil {
+			statuses = append(statuses, RecordStatus{ID: taskID, Source: ref, Error: err.Error()})
+			continue
+		}
+		statuses = append(statuses, inspectRecord(taskID, ref, data))
+	}
+
+	return statuses, nil
+}
+
+// Close implements Store; the git-refs store holds no resources
+func (s *GitRefStore) Close() error {
+	return nil
+}
+
+// Fetch fetches the remote's task refs into refs/awt/remotes/<remote>/tasks/ and merges
+// each into the local task field by field, keeping whichever side changed a field last.
+// Returns the IDs of the local tasks that were created, changed or deleted.
+// A task deleted on one side stays deleted unless the other side changed it afterwards.
+func (s *GitRefStore) Fetch(ctx context.Context, remote string) ([]string, error) {
+	tracking := remoteTrackingPrefix(remote)
+
+	result, err := s.g.Fetch(ctx, remote, "+"+gitRefsPrefix+"*:"+tracking+"*")
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to fetch task refs from %s: %s", remote, result.Stderr)
+	}
+
+	remoteRefs, err := s.g.ForEachRef(ctx, tracking)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fetched task refs: %w", err)
+	}
+
+	updated := []string{}
+	for trackingRef, remoteID := range remoteRefs {
+		taskID := strings.TrimPrefix(trackingRef, tracking)
+		changed, err := s.mergeRemote(taskID, remoteID)
+		if err != nil {
+			return updated, err
+		}
+		if changed {
+			updated = append(updated, taskID)
+		}
+	}
+	sort.Strings(updated)
+
+	return updated, nil
+}
+
+// Push fetches and merges the remote's task refs, then pushes every local task ref that
+// differs from the remote. Each ref is pushed with a lease on the fetched value, so a
+// concurrent push from another clone is rejected instead of overwritten.
+// Returns the IDs of the tasks that were pushed.
+func (s *GitRefStore) Push(ctx context.Context, remote string) ([]string, error) {
+	if _, err := s.Fetch(ctx, remote); err != nil {
+		return nil, err
+	}
+
+	tracking := remoteTrackingPrefix(remote)
+	remoteRefs, err := s.g.ForEachRef(ctx, tracking)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fetched task refs: %w", err)
+	}
+	localRefs, err := s.g.ForEachRef(ctx, gitRefsPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task refs: %w", err)
+	}
+
+	var refspecs []string
+	leases := make(map[string]string)
+	pushed := []string{}
+	for ref, localID := range localRefs {
+		taskID := strings.TrimPrefix(ref, gitRefsPrefix)
+		remoteID := remoteRefs[tracking+taskID]
+		if remoteID == localID {
+			continue
+		}
+		refspecs = append(refspecs, ref+":"+ref)
+		leases[ref] = remoteID
+		pushed = append(pushed, taskID)
+	}
+	sort.Strings(pushed)
+
+	if len(refspecs) == 0 {
+		return pushed, nil
+	}
+
+	result, err := s.g.PushRefs(ctx, remote, refspecs, leases)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to push task refs to %s (updated concurrently? run again): %s", remote, result.Stderr)
+	}
+
+	// Record what the remote now has, so the next push leases against it
+	if _, err := s.g.Fetch(ctx, remote, "+"+gitRefsPrefix+"*:"+tracking+"*"); err != nil {
+		return pushed, err
+	}
+
+	return pushed, nil
+}
+
+// mergeRemote merges a fetched remote record into the local task ref.
+// Returns true if the local ref changed.
+func (s *GitRefStore) mergeRemote(taskID, remoteID string) (bool, error) {
+	ref := gitRefsPrefix + taskID
+
+	theirs, err := s.readBlob(remoteID)
+	if err != nil {
+		return false, err
+	}
+
+	for attempt := 0; attempt < gitSaveRetries; attempt++ {
+		oldID, ours, err := s.readRef(ref)
+		if err != nil {
+			return false, err
+		}
+		if oldID == remoteID {
+			return false, nil
+		}
+
+		merged := mergeGitRecords(ours, theirs)
+		if ours != nil && merged.equal(ours) {
+			return false, nil
+		}
+		if merged.Deleted == nil {
+			if _, err := merged.decode(); err != nil {
+				return false, fmt.Errorf("merged task %s is invalid: %w", taskID, err)
+			}
+		}
+
+		err = s.writeRef(ref, merged, oldID)
+		if err == nil {
+			return true, nil
+		}
+		if !stderrors.Is(err, git.ErrRefChanged) {
+			return false, fmt.Errorf("failed to merge task %s: %w", taskID, err)
+		}
+	}
+
+	return false, fmt.Errorf("failed to merge task %s: its ref kept changing", taskID)
+}
+
+// readRef returns the object ID and record a ref points at, or empty values if it does not exist
+func (s *GitRefStore) readRef(ref string) (string, *gitRecord, error) {
+	ctx := context.Background()
+	exists, err := s.g.RefExists(ctx, ref)
+	if err != nil {
+		return "", nil, err
+	}
+	if !exists {
+		return "", nil, nil
+	}
+
+	id, err := s.g.RevParse(ctx, ref)
+	if err != nil {
+		return "", nil, err
+	}
+	record, err := s.readBlob(id)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, record, nil
+}
+
+// readBlob reads and parses a task record blob
+func (s *GitRefStore) readBlob(id string) (*gitRecord, error) {
+	data, err := s.g.CatBlob(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	var record gitRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task record (corrupted JSON?): %w", err)
+	}
+	if record.Updated == nil {
+		record.Updated = make(map[string]time.Time)
+	}
+	return &record, nil
+}
+
+// writeRef writes the record as a blob and moves ref to it if it still points at oldID
+func (s *GitRefStore) writeRef(ref string, record *gitRecord, oldID string) error {
+	ctx := context.Background()
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal task record: %w", err)
+	}
+
+	id, err := s.g.HashObject(ctx, string(data)+"\n")
+	if err != nil {
+		return err
+	}
+
+	return s.g.UpdateRef(ctx, ref, id, oldID)
+}
+
+// remoteTrackingPrefix returns the namespace holding fetched task refs of a remote
+func remoteTrackingPrefix(remote string) string {
+	return "refs/awt/remotes/" + remote + "/tasks/"
+}
+
+// newGitRecord builds the record for a task, stamping every field that differs from the
+// previous record with now
+func newGitRecord(task *Task, prev *gitRecord, now time.Time) (*gitRecord, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	record := &gitRecord{Updated: make(map[string]time.Time)}
+	if err := json.Unmarshal(data, &record.Task); err != nil {
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	var prevFields map[string]json.RawMessage
+	if prev != nil {
+		prevFields = prev.Task
+		for field, at := range prev.Updated {
+			record.Updated[field] = at
+		}
+	}
+
+	// Fields added, changed or removed since the previous record
+	for field, value := range record.Task {
+		if old, ok := prevFields[field]; !ok || !bytes.Equal(old, value) {
+			record.Updated[field] = now
+		}
+	}
+	for field := range prevFields {
+		if _, ok := record.Task[field]; !ok {
+			record.Updated[field] = now
+		}
+	}
+
+	return record, nil
+}
+
+// mergeGitRecords merges two records field by field: each field takes the value from the
+// side that changed it last, and ours wins ties. A tombstone wins over a record whose
+// fields all changed before the deletion; a record changed after it is kept whole.
+func mergeGitRecords(ours, theirs *gitRecord) *gitRecord {
+	if ours == nil {
+		return theirs
+	}
+	if ours.Deleted != nil || theirs.Deleted != nil {
+		if theirs.lastChange().After(ours.lastChange()) {
+			return theirs
+		}
+		return ours
+	}
+
+	merged := &gitRecord{
+		Task:    make(map[string]json.RawMessage),
+		Updated: make(map[string]time.Time),
+	}
+
+	fields := make(map[string]bool)
+	for field := range ours.Task {
+		fields[field] = true
+	}
+	for field := range theirs.Task {
+		fields[field] = true
+	}
+	for field := range ours.Updated {
+		fields[field] = true
+	}
+	for field := range theirs.Updated {
+		fields[field] = true
+	}
+
+	for field := range fields {
+		src := ours
+		if theirs.Updated[field].After(ours.Updated[field]) {
+			src = theirs
+		}
+		if value, ok := src.Task[field]; ok {
+			merged.Task[field] = value
+		}
+		if at, ok := src.Updated[field]; ok {
+			merged.Updated[field] = at
+		}
+	}
+
+	return merged
+}
+
+// lastChange returns when the record last changed: its deletion, or its latest field change
+func (r *gitRecord) lastChange() time.Time {
+	if r.Deleted != nil {
+		return *r.Deleted
+	}
+	var last time.Time
+	for _, at := range r.Updated {
+		if at.After(last) {
+			last = at
+		}
+	}
+	return last
+}
+
+// decode converts the record to a migrated, validated task
+func (r *gitRecord) decode() (*Task, error) {
+	data, err := json.Marshal(r.Task)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeTask(data)
+}
+
+// equal reports whether two records hold the same fields and timestamps
+func (r *gitRecord) equal(other *gitRecord) bool {
+	a, errA := json.Marshal(r)
+	b, errB := json.Marshal(other)
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
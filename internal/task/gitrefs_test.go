@@ -0,0 +1,194 @@
+package task
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cloneStore creates a repository with the given remote and returns a git-refs store for it
+func cloneStore(t *testing.T, remote string) *GitRefStore {
+	t.Helper()
+	dir := initBareRepo(t)
+	if out, err := exec.Command("git", "-C", dir, "remote", "add", "origin", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git remote add failed: %v: %s", err, out)
+	}
+	return NewGitRefStore(dir)
+}
+
+func TestGitRefStoreSyncsAcrossClones(t *testing.T) {
+	remote := initBareRepo(t)
+	a := cloneStore(t, remote)
+	b := cloneStore(t, remote)
+
+	task := &Task{
+		ID:        "20250110-120000-abc123",
+		Agent:     "claude",
+		Title:     "Shared task",
+		Branch:    "awt/claude/20250110-120000-abc123",
+		Base:      "main",
+		CreatedAt: time.Now(),
+		State:     StateActive,
+	}
+	if err := a.Save(task); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+
+	pushed, err := a.Push(context.Background(), "origin")
+	if err != nil {
+		t.Fatalf("Push() failed: %v", err)
+	}
+	if len(pushed) != 1 || pushed[0] != task.ID {
+		t.Errorf("pushed = %v, want [%s]", pushed, task.ID)
+	}
+
+	fetched, err := b.Fetch(context.Background(), "origin")
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	if len(fetched) != 1 {
+		t.Fatalf("fetched = %v, want [%s]", fetched, task.ID)
+	}
+
+	// Each clone changes a different field
+	onA, _ := a.Load(task.ID)
+	onA.PRURL = "https://example.com/pr/1"
+	if err := a.Save(onA); err != nil {
+		t.Fatalf("failed to save on a: %v", err)
+	}
+	onB, _ := b.Load(task.ID)
+	onB.LastCommit = "abc123"
+	if err := b.Save(onB); err != nil {
+		t.Fatalf("failed to save on b: %v", err)
+	}
+
+	if _, err := a.Push(context.Background(), "origin"); err != nil {
+		t.Fatalf("Push() from a failed: %v", err)
+	}
+	// b merges a's change before pushing its own
+	if _, err := b.Push(context.Background(), "origin"); err != nil {
+		t.Fatalf("Push() from b failed: %v", err)
+	}
+	if _, err := a.Fetch(context.Background(), "origin"); err != nil {
+		t.Fatalf("Fetch() on a failed: %v", err)
+	}
+
+	for name, store := range map[string]*GitRefStore{"a": a, "b": b} {
+		merged, err := store.Load(task.ID)
+		if err != nil {
+			t.Fatalf("failed to load on %s: %v", name, err)
+		}
+		if merged.PRURL != "https://example.com/pr/1" || merged.LastCommit != "abc123" {
+			t.Errorf("%s: merged task has pr_url=%q last_commit=%q, want both updates", name, merged.PRURL, merged.LastCommit)
+		}
+	}
+}
+
+func TestMergeGitRecordsLastWriterWins(t *testing.T) {
+	base := &Task{ID: "t1", Agent: "claude", Title: "Task", Branch: "b", Base: "main", State: StateActive}
+	t0 := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	ours, err := newGitRecord(base, nil, t0)
+	if err != nil {
+		t.Fatalf("newGitRecord() failed: %v", err)
+	}
+
+	// Both sides change the title; theirs changed it later
+	ourTask := *base
+	ourTask.Title = "Ours"
+	ours, _ = newGitRecord(&ourTask, ours, t0.Add(time.Minute))
+
+	theirTask := *base
+	theirTask.Title = "Theirs"
+	theirTask.State = StateHandoffReady
+	theirs, _ := newGitRecord(base, nil, t0)
+	theirs, _ = newGitRecord(&theirTask, theirs, t0.Add(2*time.Minute))
+	// Backdate their state change so it ties with ours; ties go to ours
+	theirs.Updated["state"] = t0
+
+	merged, err := mergeGitRecords(ours, theirs).decode()
+	if err != nil {
+		t.Fatalf("decode() failed: %v", err)
+	}
+	if merged.Title != "Theirs" {
+		t.Errorf("title = %q, want the later write %q", merged.Title, "Theirs")
+	}
+	if merged.State != StateActive {
+		t.Errorf("state = %s, want ours on a tie", merged.State)
+	}
+}
+
+func TestGitRefStoreDeleteSurvivesFetch(t *testing.T) {
+	remote := initBareRepo(t)
+	a := cloneStore(t, remote)
+	b := cloneStore(t, remote)
+	ctx := context.Background()
+
+	task := &Task{ID: "t1", Agent: "claude", Title: "Shared task", Branch: "b", Base: "main", CreatedAt: time.Now(), State: StateActive}
+	if err := a.Save(task); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+	if _, err := a.Push(ctx, "origin"); err != nil {
+		t.Fatalf("Push() failed: %v", err)
+	}
+	if _, err := b.Fetch(ctx, "origin"); err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+
+	// The remote still has the task, so fetching must not bring it back
+	if err := a.Delete(task.ID); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := a.Fetch(ctx, "origin"); err != nil {
+		t.Fatalf("Fetch() after Delete() failed: %v", err)
+	}
+	if _, err := a.Load(task.ID); err == nil {
+		t.Error("deleted task came back after fetch")
+	}
+	if tasks, _ := a.List(); len(tasks) != 0 {
+		t.Errorf("List() = %d tasks, want none", len(tasks))
+	}
+
+	// Pushing the deletion deletes the task in other clones
+	if _, err := a.Push(ctx, "origin"); err != nil {
+		t.Fatalf("Push() after Delete() failed: %v", err)
+	}
+	fetched, err := b.Fetch(ctx, "origin")
+	if err != nil {
+		t.Fatalf("Fetch() on b failed: %v", err)
+	}
+	if len(fetched) != 1 {
+		t.Errorf("fetched = %v, want the deleted task", fetched)
+	}
+	if _, err := b.Load(task.ID); err == nil {
+		t.Error("task deleted on a is still on b")
+	}
+
+	// A task can be created again under a deleted ID
+	again := &Task{ID: "t1", Agent: "claude", Title: "New task", Branch: "b", Base: "main", CreatedAt: time.Now(), State: StateActive}
+	if err := a.Save(again); err != nil {
+		t.Fatalf("Save() over a deleted task failed: %v", err)
+	}
+	if loaded, err := a.Load(task.ID); err != nil || loaded.Title != "New task" {
+		t.Errorf("Load() = %v, %v, want the new task", loaded, err)
+	}
+}
+
+func TestGitRefStoreSaveReportsWriteErrors(t *testing.T) {
+	store := NewGitRefStore(initBareRepo(t))
+
+	// git refuses the ref name, which is not a concurrent update
+	task := &Task{ID: "bad..id", Agent: "claude", Title: "Task", Branch: "b", Base: "main", CreatedAt: time.Now(), State: StateActive}
+	err := store.Save(task)
+	if err == nil {
+		t.Fatal("Save() with an invalid ref name succeeded")
+	}
+	if strings.Contains(err.Error(), "kept changing") {
+		t.Errorf("Save() error = %v, want the update-ref failure instead of a conflict", err)
+	}
+	if task.Revision != 0 {
+		t.Errorf("revision = %d, want it restored after the failed save", task.Revision)
+	}
+}
@@ -0,0 +1,136 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CurrentSchemaVersion is the schema version written by Save.
+// Records without a schema_version field are version 0.
+const CurrentSchemaVersion = 1
+
+// migration upgrades a raw task record by one schema version, in place
+type migration func(record map[string]any) error
+
+// migrations[i] upgrades a record from schema version i to i+1
+var migrations = []migration{
+	migrateV0ToV1,
+}
+
+// migrateV0ToV1 fills in fields that early AWT versions did not always write,
+// and normalizes the state name
+func migrateV0ToV1(record map[string]any) error {
+	id, _ := record["id"].(string)
+
+	if s, ok := record["state"].(string); ok {
+		record["state"] = strings.ToUpper(s)
+	} else {
+		// Tasks were ACTIVE while they had a worktree, and handed off otherwise
+		if path, _ := record["worktree_path"].(string); path != "" {
+			record["state"] = string(StateActive)
+		} else {
+			record["state"] = string(StateHandoffReady)
+		}
+	}
+
+	if agent, _ := record["agent"].(string); agent == "" {
+		record["agent"] = "unknown"
+	}
+	if title, _ := record["title"].(string); title == "" && id != "" {
+		record["title"] = id
+	}
+
+	return nil
+}
+
+// migrateRecord runs the migration chain on a raw record and stamps it with the current version.
+// Returns the record's original schema version.
+func migrateRecord(record map[string]any) (int, error) {
+	version := 0
+	if v, ok := record["schema_version"].(float64); ok {
+		version = int(v)
+	}
+
+	if version > CurrentSchemaVersion {
+		return version, fmt.Errorf("task schema version %d is newer than this awt supports (%d); upgrade awt", version, CurrentSchemaVersion)
+	}
+
+	for v := version; v < CurrentSchemaVersion; v++ {
+		if err := migrations[v](record); err != nil {
+			return version, fmt.Errorf("failed to migrate task from schema version %d to %d: %w", v, v+1, err)
+		}
+	}
+	record["schema_version"] = CurrentSchemaVersion
+
+	return version, nil
+}
+
+// DecodeTask parses a stored task record, migrates it to the current schema and validates it
+func DecodeTask(data []byte) (*Task, error) {
+	task, _, err := decodeTaskVersion(data)
+	return task, err
+}
+
+// decodeTaskVersion is DecodeTask that also returns the record's original schema version
+func decodeTaskVersion(data []byte) (*Task, int, error) {
+	var record map[string]any
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, 0, fmt.Errorf("failed to unmarshal task (corrupted JSON?): %w", err)
+	}
+
+	version, err := migrateRecord(record)
+	if err != nil {
+		return nil, version, err
+	}
+
+	migrated, err := json.Marshal(record)
+	if err != nil {
+		return nil, version, fmt.Errorf("failed to marshal migrated task: %w", err)
+	}
+
+	var task Task
+	if err := json.Unmarshal(migrated, &task); err != nil {
+		return nil, version, fmt.Errorf("failed to unmarshal task (corrupted JSON?): %w", err)
+	}
+
+	if err := task.Validate(); err != nil {
+		return nil, version, fmt.Errorf("task validation failed: %w", err)
+	}
+
+	return &task, version, nil
+}
+
+// RecordStatus reports whether a stored task record can be loaded
+type RecordStatus struct {
+	// ID is the task ID (from the record, or its file/ref/row name if unreadable)
+	ID string `json:"id"`
+
+	// Source locates the record in the store (file path, ref name or table row)
+	Source string `json:"source"`
+
+	// SchemaVersion is the version the record was stored with
+	SchemaVersion int `json:"schema_version"`
+
+	// Migrated is true if the record was upgraded from an older schema version
+	Migrated bool `json:"migrated"`
+
+	// Error is why the record cannot be loaded (empty if it loads)
+	Error string `json:"error,omitempty"`
+}
+
+// inspectRecord checks a stored record the same way Load does
+func inspectRecord(id, source string, data []byte) RecordStatus {
+	status := RecordStatus{ID: id, Source: source}
+
+	task, version, err := decodeTaskVersion(data)
+	status.SchemaVersion = version
+	status.Migrated = version < CurrentSchemaVersion
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	status.ID = task.ID
+	return status
+}
@@ -0,0 +1,99 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecodeTaskMigratesV0(t *testing.T) {
+	// A record from before schema versioning: no schema_version, lowercase state, no title
+	data := []byte(`{
+  "id": "20250110-120000-abc123",
+  "agent": "claude",
+  "branch": "awt/claude/20250110-120000-abc123",
+  "base": "main",
+  "state": "active"
+}`)
+
+	task, err := DecodeTask(data)
+	if err != nil {
+		t.Fatalf("DecodeTask() failed: %v", err)
+	}
+	if task.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", task.SchemaVersion, CurrentSchemaVersion)
+	}
+	if task.State != StateActive {
+		t.Errorf("State = %q, want %q", task.State, StateActive)
+	}
+	if task.Title != task.ID {
+		t.Errorf("Title = %q, want the task ID", task.Title)
+	}
+}
+
+func TestDecodeTaskRejectsNewerSchema(t *testing.T) {
+	data := []byte(`{"schema_version": 999, "id": "t1", "agent": "a", "title": "t", "branch": "b", "base": "main", "state": "ACTIVE"}`)
+
+	if _, err := DecodeTask(data); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("expected schema version error, got %v", err)
+	}
+}
+
+func TestTaskStoreInspect(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewTaskStore(tempDir)
+	tasksDir := filepath.Join(tempDir, "awt", "tasks")
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		t.Fatalf("failed to create tasks dir: %v", err)
+	}
+
+	files := map[string]string{
+		// Current and loadable
+		"current": `{"schema_version": 1, "id": "current", "agent": "a", "title": "t", "branch": "b1", "base": "main", "state": "ACTIVE"}`,
+		// Old but migratable
+		"old": `{"id": "old", "agent": "a", "branch": "b2", "base": "main", "state": "merged"}`,
+		// Missing its branch, which no migration can recover
+		"broken": `{"id": "broken", "agent": "a", "title": "t", "base": "main", "state": "ACTIVE"}`,
+		// Not JSON at all
+		"corrupt": `{not json`,
+	}
+	for id, content := range files {
+		if err := os.WriteFile(filepath.Join(tasksDir, id+".json"), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", id, err)
+		}
+	}
+
+	statuses, err := store.Inspect()
+	if err != nil {
+		t.Fatalf("Inspect() failed: %v", err)
+	}
+
+	byID := make(map[string]RecordStatus)
+	for _, s := range statuses {
+		byID[s.ID] = s
+	}
+	if len(byID) != 4 {
+		t.Fatalf("expected 4 records, got %+v", statuses)
+	}
+	if s := byID["current"]; s.Error != "" || s.Migrated {
+		t.Errorf("current = %+v, want ok", s)
+	}
+	if s := byID["old"]; s.Error != "" || !s.Migrated || s.SchemaVersion != 0 {
+		t.Errorf("old = %+v, want migrated from version 0", s)
+	}
+	for _, id := range []string{"broken", "corrupt"} {
+		if byID[id].Error == "" {
+			t.Errorf("%s = %+v, want an error", id, byID[id])
+		}
+	}
+
+	// List still loads only the loadable records
+	tasks, err := store.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("List() returned %d tasks, want 2", len(tasks))
+	}
+}
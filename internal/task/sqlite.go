@@ -0,0 +1,263 @@
+package task
+
+import (
+	"database/sql"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// Pure Go SQLite driver (no cgo)
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the tasks table. The full task is stored as JSON in data;
+// the other columns are indexed copies used by Find and FindByBranch.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tasks (
+	id         TEXT PRIMARY KEY,
+	agent      TEXT NOT NULL,
+	state      TEXT NOT NULL,
+	grp        TEXT NOT NULL DEFAULT '',
+	branch     TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS tasks_agent ON tasks(agent);
+CREATE INDEX IF NOT EXISTS tasks_state ON tasks(state);
+CREATE INDEX IF NOT EXISTS tasks_grp ON tasks(grp);
+CREATE INDEX IF NOT EXISTS tasks_branch ON tasks(branch);
+`
+
+// SQLiteStore is a Store backed by an embedded SQLite database.
+// Each Save runs in its own transaction, so the transition check and the write are atomic
+// across processes.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating if needed) the task database at .git/awt/tasks.db
+func NewSQLiteStore(gitCommonDir string) (*SQLiteStore, error) {
+	dir := filepath.Join(gitCommonDir, "awt")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create awt directory: %w", err)
+	}
+
+	// Wait for concurrent writers instead of failing with SQLITE_BUSY, and take the
+	// write lock when a transaction begins so read-check-write cannot interleave
+	dsn := "file:" + filepath.Join(dir, "tasks.db") +
+		"?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open task database: %w", err)
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize task database: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// Save saves the task at the current schema version in a single transaction.
+// The stored record must still be at task.Revision.
+func (s *SQLiteStore) Save(task *Task) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Reject stale revisions and illegal state changes against the stored record.
+	// The transaction takes the write lock up front (_txlock=immediate), so the
+	// compare and the write are atomic.
+	var stored string
+	err = tx.QueryRow(`SELECT data FROM tasks WHERE id = ?`, task.ID).Scan(&stored)
+	switch {
+	case err == nil:
+		if prev, err := DecodeTask([]byte(stored)); err == nil {
+			if err := checkSave(task, prev); err != nil {
+				return err
+			}
+		}
+	case !stderrors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("failed to read task: %w", err)
+	}
+
+	task.SchemaVersion = CurrentSchemaVersion
+	task.Revision++
+	committed := false
+	defer func() {
+		if !committed {
+			task.Revision--
+		}
+	}()
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO tasks (id, agent, state, grp, branch, created_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			agent = excluded.agent,
+			state = excluded.state,
+			grp = excluded.grp,
+			branch = excluded.branch,
+			created_at = excluded.created_at,
+			data = excluded.data`,
+		task.ID,
+		task.Agent,
+		string(task.State),
+		task.Group,
+		strings.TrimPrefix(task.Branch, "refs/heads/"),
+		task.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000000Z"),
+		string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit task: %w", err)
+	}
+	committed = true
+
+	return nil
+}
+
+// Load loads a task from the database
+func (s *SQLiteStore) Load(taskID string) (*Task, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM tasks WHERE id = ?`, taskID).Scan(&data)
+	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("task not found: %s", taskID)
+		}
+		return nil, fmt.Errorf("failed to read task: %w", err)
+	}
+
+	return DecodeTask([]byte(data))
+}
+
+// List returns all tasks, ordered by ID
+func (s *SQLiteStore) List() ([]*Task, error) {
+	return s.query(`SELECT data FROM tasks ORDER BY id`)
+}
+
+// Find returns the tasks matching the filter, using the column indexes
+func (s *SQLiteStore) Find(filter Filter) ([]*Task, error) {
+	var where []string
+	var args []any
+	if filter.Agent != "" {
+		where = append(where, "agent = ?")
+		args = append(args, filter.Agent)
+	}
+	if filter.State != "" {
+		where = append(where, "state = ?")
+		args = append(args, string(filter.State))
+	}
+	if filter.Group != "" {
+		where = append(where, "grp = ?")
+		args = append(args, filter.Group)
+	}
+
+	query := `SELECT data FROM tasks`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id"
+
+	return s.query(query, args...)
+}
+
+// FindByBranch returns the task that owns the given branch
+func (s *SQLiteStore) FindByBranch(branch string) (*Task, error) {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+
+	tasks, err := s.query(`SELECT data FROM tasks WHERE branch = ? ORDER BY id LIMIT 1`, branch)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no task found for branch: %s", branch)
+	}
+
+	return tasks[0], nil
+}
+
+// Delete removes a task from the database
+func (s *SQLiteStore) Delete(taskID string) error {
+	if _, err := s.db.Exec(`DELETE FROM tasks WHERE id = ?`, taskID); err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	return nil
+}
+
+// Inspect reports on every row, including those that fail to load
+func (s *SQLiteStore) Inspect() ([]RecordStatus, error) {
+	rows, err := s.db.Query(`SELECT id, data FROM tasks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	statuses := []RecordStatus{}
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to read task row: %w", err)
+		}
+		statuses = append(statuses, inspectRecord(id, "tasks.db:"+id, []byte(data)))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	return statuses, nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// query runs a query selecting the data column and decodes each row.
+// Rows that fail to decode are skipped, like unreadable files in the JSON store.
+func (s *SQLiteStore) query(query string, args ...any) ([]*Task, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tasks := []*Task{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read task row: %w", err)
+		}
+		task, err := DecodeTask([]byte(data))
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	return tasks, nil
+}
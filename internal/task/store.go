@@ -0,0 +1,116 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kernel-labs-ai/awt/internal/errors"
+)
+
+// Task store backends
+const (
+	// BackendJSON stores one JSON file per task under .git/awt/tasks (the default)
+	BackendJSON = "json"
+	// BackendSQLite stores tasks in an embedded SQLite database at .git/awt/tasks.db
+	BackendSQLite = "sqlite"
+	// BackendGit stores tasks as blobs under refs/awt/tasks/<id>, shareable through a remote
+	BackendGit = "git"
+)
+
+// Store persists task metadata. Commands depend on this interface rather than on a backend.
+type Store interface {
+	// Save creates or updates a task.
+	// If the task already exists, its stored revision must equal task.Revision
+	// (otherwise a TASK_CONFLICT error is returned) and a state change must be
+	// allowed by the transition table. On success task.Revision is incremented.
+	Save(task *Task) error
+
+	// Load loads a single task, migrating it to the current schema version
+	Load(taskID string) (*Task, error)
+
+	// List returns all tasks that load successfully (see Inspect for the others)
+	List() ([]*Task, error)
+
+	// Find returns the tasks matching the filter
+	Find(filter Filter) ([]*Task, error)
+
+	// FindByBranch returns the task that owns the given branch.
+	// A refs/heads/ prefix on either side is ignored.
+	FindByBranch(branch string) (*Task, error)
+
+	// Delete removes a task
+	Delete(taskID string) error
+
+	// Inspect reports on every stored record, including those that fail to load
+	Inspect() ([]RecordStatus, error)
+
+	// Close releases any resources held by the store
+	Close() error
+}
+
+// Filter selects tasks in Find. Empty fields match any value.
+type Filter struct {
+	Agent string
+	State State
+	Group string
+}
+
+// Matches reports whether the task satisfies the filter
+func (f Filter) Matches(t *Task) bool {
+	if f.Agent != "" && t.Agent != f.Agent {
+		return false
+	}
+	if f.State != "" && t.State != f.State {
+		return false
+	}
+	if f.Group != "" && t.Group != f.Group {
+		return false
+	}
+	return true
+}
+
+// Backends returns the names of the available store backends
+func Backends() []string {
+	return []string{BackendJSON, BackendSQLite, BackendGit}
+}
+
+// ValidateBackend returns an error unless backend names a store backend
+func ValidateBackend(backend string) error {
+	for _, b := range Backends() {
+		if backend == b {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown task store backend %q (valid: %s)", backend, strings.Join(Backends(), ", "))
+}
+
+// OpenStore opens the task store for the given backend.
+// An empty backend selects the JSON directory store.
+func OpenStore(gitCommonDir, backend string) (Store, error) {
+	switch backend {
+	case "", BackendJSON:
+		return NewTaskStore(gitCommonDir), nil
+	case BackendSQLite:
+		return NewSQLiteStore(gitCommonDir)
+	case BackendGit:
+		return NewGitRefStore(gitCommonDir), nil
+	default:
+		return nil, ValidateBackend(backend)
+	}
+}
+
+// checkSave validates saving task over the stored record (nil if there is none):
+// the stored revision must be the one the task was loaded at, and a state change
+// must be allowed by the transition table
+func checkSave(task, stored *Task) error {
+	if stored == nil {
+		return nil
+	}
+	if stored.Revision != task.Revision {
+		return errors.TaskConflict(task.ID, task.Revision, stored.Revision)
+	}
+	if stored.State != task.State && !CanTransition(stored.State, task.State) {
+		return errors.InvalidTransition(task.ID, string(stored.State), string(task.State))
+	}
+	return nil
+}
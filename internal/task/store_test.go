@@ -0,0 +1,209 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/lock"
+)
+
+// initBareRepo creates a bare repository to act as the git common dir
+func initBareRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "--bare", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+	return dir
+}
+
+// TestStoreBackends runs the same checks against every store backend
+func TestStoreBackends(t *testing.T) {
+	for _, backend := range Backends() {
+		t.Run(backend, func(t *testing.T) {
+			store, err := OpenStore(initBareRepo(t), backend)
+			if err != nil {
+				t.Fatalf("failed to open %s store: %v", backend, err)
+			}
+			defer func() {
+				_ = store.Close()
+			}()
+
+			newTask := func(id, agent, group string, state State) *Task {
+				return &Task{
+					ID:        id,
+					Agent:     agent,
+					Title:     "Task " + id,
+					Branch:    fmt.Sprintf("awt/%s/%s", agent, id),
+					Base:      "main",
+					CreatedAt: time.Now(),
+					State:     state,
+					Group:     group,
+				}
+			}
+
+			tasks := []*Task{
+				newTask("task-1", "claude", "g1", StateActive),
+				newTask("task-2", "codex", "g1", StateActive),
+				newTask("task-3", "claude", "", StateHandoffReady),
+			}
+			for _, tk := range tasks {
+				if err := store.Save(tk); err != nil {
+					t.Fatalf("failed to save %s: %v", tk.ID, err)
+				}
+			}
+
+			loaded, err := store.Load("task-2")
+			if err != nil {
+				t.Fatalf("failed to load task: %v", err)
+			}
+			if loaded.Agent != "codex" || loaded.Group != "g1" {
+				t.Errorf("loaded task = %+v", loaded)
+			}
+			if _, err := store.Load("nonexistent"); err == nil {
+				t.Error("expected error loading non-existent task")
+			}
+
+			all, err := store.List()
+			if err != nil || len(all) != 3 {
+				t.Fatalf("List() = %d tasks, %v, want 3", len(all), err)
+			}
+
+			filters := []struct {
+				filter Filter
+				want   []string
+			}{
+				{Filter{Agent: "claude"}, []string{"task-1", "task-3"}},
+				{Filter{State: StateActive}, []string{"task-1", "task-2"}},
+				{Filter{Group: "g1", Agent: "codex"}, []string{"task-2"}},
+				{Filter{State: StateMerged}, []string{}},
+			}
+			for _, f := range filters {
+				found, err := store.Find(f.filter)
+				if err != nil {
+					t.Fatalf("Find(%+v) failed: %v", f.filter, err)
+				}
+				var ids []string
+				for _, tk := range found {
+					ids = append(ids, tk.ID)
+				}
+				if fmt.Sprint(ids) != fmt.Sprint(f.want) {
+					t.Errorf("Find(%+v) = %v, want %v", f.filter, ids, f.want)
+				}
+			}
+
+			byBranch, err := store.FindByBranch("refs/heads/awt/claude/task-3")
+			if err != nil || byBranch.ID != "task-3" {
+				t.Errorf("FindByBranch() = %v, %v, want task-3", byBranch, err)
+			}
+
+			// Saves are compare-and-swap on the revision
+			if loaded.Revision != 1 {
+				t.Errorf("loaded revision = %d, want 1", loaded.Revision)
+			}
+			stale := newTask("task-1", "claude", "g1", StateActive)
+			if err := store.Save(stale); !IsConflict(err) {
+				t.Errorf("Save() with a stale revision = %v, want a conflict", err)
+			}
+
+			// Illegal state changes are rejected against the stored state
+			merged := newTask("task-1", "claude", "g1", StateMerged)
+			merged.Revision = 1
+			if err := store.Save(merged); err != nil {
+				t.Fatalf("failed to save ACTIVE -> MERGED: %v", err)
+			}
+			if merged.Revision != 2 {
+				t.Errorf("revision after save = %d, want 2", merged.Revision)
+			}
+			reactivated := newTask("task-1", "claude", "g1", StateActive)
+			reactivated.Revision = 2
+			if err := store.Save(reactivated); err == nil || IsConflict(err) {
+				t.Errorf("Save() MERGED -> ACTIVE = %v, want an invalid transition", err)
+			}
+
+			if err := store.Delete("task-1"); err != nil {
+				t.Fatalf("failed to delete task: %v", err)
+			}
+			if _, err := store.Load("task-1"); err == nil {
+				t.Error("deleted task can still be loaded")
+			}
+		})
+	}
+}
+
+// TestUpdateRetriesOnConflict checks that Update reapplies its change on top of a concurrent write
+func TestUpdateRetriesOnConflict(t *testing.T) {
+	dir := initBareRepo(t)
+	store := NewTaskStore(dir)
+	if err := store.Save(&Task{
+		ID:        "task-1",
+		Agent:     "claude",
+		Title:     "Task",
+		Branch:    "awt/claude/task-1",
+		Base:      "main",
+		CreatedAt: time.Now(),
+		State:     StateActive,
+	}); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+
+	// Update refuses to run without the task lock
+	noop := func(*Task) error { return nil }
+	if _, err := Update(store, nil, "task-1", noop); err == nil {
+		t.Error("Update() without the task lock succeeded")
+	}
+	otherLock, err := lock.NewLockManager(dir).AcquireTask(context.Background(), "task-2")
+	if err != nil {
+		t.Fatalf("failed to acquire task lock: %v", err)
+	}
+	if _, err := Update(store, otherLock, "task-1", noop); err == nil {
+		t.Error("Update() with another task's lock succeeded")
+	}
+	_ = otherLock.Release()
+
+	taskLock, err := lock.NewLockManager(dir).AcquireTask(context.Background(), "task-1")
+	if err != nil {
+		t.Fatalf("failed to acquire task lock: %v", err)
+	}
+	defer func() {
+		_ = taskLock.Release()
+	}()
+
+	calls := 0
+	updated, err := Update(store, taskLock, "task-1", func(tk *Task) error {
+		calls++
+		if calls == 1 {
+			// Another writer saves between our load and our save
+			other, err := store.Load("task-1")
+			if err != nil {
+				return err
+			}
+			other.PRURL = "https://example.com/pr/1"
+			if err := store.Save(other); err != nil {
+				return err
+			}
+		}
+		tk.LastCommit = "abc123"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("fn called %d times, want 2", calls)
+	}
+
+	loaded, err := store.Load("task-1")
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if loaded.LastCommit != "abc123" || loaded.PRURL != "https://example.com/pr/1" {
+		t.Errorf("lost write: last_commit=%q pr_url=%q", loaded.LastCommit, loaded.PRURL)
+	}
+	if loaded.Revision != updated.Revision || loaded.Revision != 3 {
+		t.Errorf("revision = %d (returned %d), want 3", loaded.Revision, updated.Revision)
+	}
+}
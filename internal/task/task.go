@@ -1,11 +1,15 @@
 package task
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/lock"
 )
 
 // State represents the task state in the state machine
@@ -26,6 +30,13 @@ const (
 
 // Task represents a single agent task
 type Task struct {
+	// SchemaVersion is the version of the task record format (see CurrentSchemaVersion)
+	SchemaVersion int `json:"schema_version"`
+
+	// Revision counts saves of the record. Save expects the stored record to still be at
+	// this revision (compare-and-swap) and increments it on success.
+	Revision int64 `json:"revision"`
+
 	// ID is the unique task identifier (YYYYmmdd-HHMMSS-<6random>)
 	ID string `json:"id"`
 
@@ -55,40 +66,114 @@ type Task struct {
 
 	// PRURL is the URL of the pull/merge request (optional)
 	PRURL string `json:"pr_url,omitempty"`
+
+	// PRNumber is the number of the pull/merge request created through the forge API (optional)
+	PRNumber int `json:"pr_number,omitempty"`
+
+	// PRStatus is the pull request status last seen by 'awt task pr' (optional)
+	PRStatus *PRStatus `json:"pr_status,omitempty"`
+
+	// MergeCommit is the commit on the base branch that integrated the task (set when MERGED)
+	MergeCommit string `json:"merge_commit,omitempty"`
+
+	// MergedAt is when the task was detected as merged (set when MERGED)
+	MergedAt *time.Time `json:"merged_at,omitempty"`
+
+	// Group is the ID shared by tasks fanned out to several agents (optional)
+	Group string `json:"group,omitempty"`
+
+	// History is the list of state transitions, oldest first
+	History []Transition `json:"history,omitempty"`
 }
 
-// TaskStore handles persistence of task metadata
+// PRStatus is a snapshot of a task's pull request as reported by the forge
+type PRStatus struct {
+	// State is open, closed or merged
+	State string `json:"state"`
+	Draft bool   `json:"draft,omitempty"`
+	// HeadSHA is the commit the checks ran on
+	HeadSHA string `json:"head_sha,omitempty"`
+	// Checks is the combined CI state (success, failure or pending), empty if there are no checks
+	Checks string `json:"checks,omitempty"`
+	// Review is the review decision (approved or changes_requested), empty if there is none yet
+	Review string `json:"review,omitempty"`
+	// Mergeable is nil while the forge has not computed it
+	Mergeable *bool `json:"mergeable,omitempty"`
+	// Comments is the number of comments on the pull request
+	Comments int `json:"comments"`
+	// CheckedAt is when the snapshot was taken
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// TaskStore is the default Store: one JSON file per task
 type TaskStore struct {
 	// tasksDir is the directory where task JSON files are stored
 	tasksDir string
+
+	// saveLocks serializes the read-compare-write in Save across processes.
+	// Its lock files (<id>.lock) live in a subdirectory of the locks directory,
+	// apart from the task locks that callers of Save may hold.
+	saveLocks *lock.LockManager
 }
 
 // NewTaskStore creates a new task store
 func NewTaskStore(gitCommonDir string) *TaskStore {
 	return &TaskStore{
-		tasksDir: filepath.Join(gitCommonDir, "awt", "tasks"),
+		tasksDir:  filepath.Join(gitCommonDir, "awt", "tasks"),
+		saveLocks: lock.NewLockManagerInDir(filepath.Join(gitCommonDir, "awt", "locks", "save"), saveLockTimeout),
 	}
 }
 
-// Save saves the task to disk atomically
+// saveLockTimeout bounds the wait for a concurrent Save of the same task
+const saveLockTimeout = 10 * time.Second
+
+// Save saves the task to disk atomically, at the current schema version.
+// If the task already exists on disk, it must still be at task.Revision and a
+// state change must be allowed by the transition table. On success task.Revision
+// is incremented.
 func (ts *TaskStore) Save(task *Task) error {
 	// Ensure tasks directory exists
 	if err := os.MkdirAll(ts.tasksDir, 0755); err != nil {
 		return fmt.Errorf("failed to create tasks directory: %w", err)
 	}
 
+	// Hold a short save lock so the compare and the write are atomic. It is separate
+	// from the task lock, which callers may already hold.
+	saveLock, err := ts.saveLocks.AcquireLock(context.Background(), task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to lock task %s for saving: %w", task.ID, err)
+	}
+	defer func() {
+		_ = saveLock.Release()
+	}()
+
+	taskPath := ts.taskPath(task.ID)
+
+	// Reject stale revisions and illegal state changes against the stored record
+	if existing, err := os.ReadFile(taskPath); err == nil {
+		if stored, err := DecodeTask(existing); err == nil {
+			if err := checkSave(task, stored); err != nil {
+				return err
+			}
+		}
+	}
+
+	task.SchemaVersion = CurrentSchemaVersion
+	task.Revision++
+
 	// Marshal task to JSON
 	data, err := json.MarshalIndent(task, "", "  ")
 	if err != nil {
+		task.Revision--
 		return fmt.Errorf("failed to marshal task: %w", err)
 	}
 
 	// Write atomically: write to temp file, then rename
-	taskPath := ts.taskPath(task.ID)
 	tempPath := taskPath + ".tmp"
 
 	// Write to temp file
 	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		task.Revision--
 		return fmt.Errorf("failed to write temp file: %w", err)
 	}
 
@@ -96,13 +181,14 @@ func (ts *TaskStore) Save(task *Task) error {
 	if err := os.Rename(tempPath, taskPath); err != nil {
 		// Clean up temp file on error
 		_ = os.Remove(tempPath)
+		task.Revision--
 		return fmt.Errorf("failed to rename temp file: %w", err)
 	}
 
 	return nil
 }
 
-// Load loads a task from disk
+// Load loads a task from disk, migrating it to the current schema version
 func (ts *TaskStore) Load(taskID string) (*Task, error) {
 	taskPath := ts.taskPath(taskID)
 
@@ -114,17 +200,7 @@ func (ts *TaskStore) Load(taskID string) (*Task, error) {
 		return nil, fmt.Errorf("failed to read task file: %w", err)
 	}
 
-	var task Task
-	if err := json.Unmarshal(data, &task); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal task (corrupted JSON?): %w", err)
-	}
-
-	// Validate task
-	if err := task.Validate(); err != nil {
-		return nil, fmt.Errorf("task validation failed: %w", err)
-	}
-
-	return &task, nil
+	return DecodeTask(data)
 }
 
 // List returns all tasks
@@ -155,6 +231,71 @@ func (ts *TaskStore) List() ([]*Task, error) {
 	return tasks, nil
 }
 
+// Inspect reports on every task file, including those List skips because they fail to load
+func (ts *TaskStore) Inspect() ([]RecordStatus, error) {
+	entries, err := os.ReadDir(ts.tasksDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []RecordStatus{}, nil
+		}
+		return nil, fmt.Errorf("failed to read tasks directory: %w", err)
+	}
+
+	statuses := []RecordStatus{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		taskID := strings.TrimSuffix(entry.Name(), ".json")
+		path := ts.taskPath(taskID)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			statuses = append(statuses, RecordStatus{ID: taskID, Source: path, Error: err.Error()})
+			continue
+		}
+		statuses = append(statuses, inspectRecord(taskID, path, data))
+	}
+
+	return statuses, nil
+}
+
+// Find returns the tasks matching the filter
+func (ts *TaskStore) Find(filter Filter) ([]*Task, error) {
+	tasks, err := ts.List()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := []*Task{}
+	for _, t := range tasks {
+		if filter.Matches(t) {
+			matched = append(matched, t)
+		}
+	}
+
+	return matched, nil
+}
+
+// FindByBranch returns the task that owns the given branch.
+// A refs/heads/ prefix on either side is ignored.
+func (ts *TaskStore) FindByBranch(branch string) (*Task, error) {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+
+	tasks, err := ts.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tasks {
+		if strings.TrimPrefix(t.Branch, "refs/heads/") == branch {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no task found for branch: %s", branch)
+}
+
 // Delete removes a task from disk
 func (ts *TaskStore) Delete(taskID string) error {
 	taskPath := ts.taskPath(taskID)
@@ -164,6 +305,11 @@ func (ts *TaskStore) Delete(taskID string) error {
 	return nil
 }
 
+// Close implements Store; the JSON store holds no resources
+func (ts *TaskStore) Close() error {
+	return nil
+}
+
 // taskPath returns the file path for a task
 func (ts *TaskStore) taskPath(taskID string) string {
 	return filepath.Join(ts.tasksDir, taskID+".json")
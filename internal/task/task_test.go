@@ -168,4 +168,106 @@ func TestAtomicWrite(t *testing.T) {
 			t.Errorf("temp file left behind: %s", entry.Name())
 		}
 	}
+
+	// Deleting the task leaves nothing behind in the tasks directory
+	if err := store.Delete(task.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	entries, err = os.ReadDir(tasksDir)
+	if err != nil {
+		t.Fatalf("failed to read tasks dir: %v", err)
+	}
+	for _, entry := range entries {
+		t.Errorf("file left behind after delete: %s", entry.Name())
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	tests := []struct {
+		from State
+		to   State
+		want bool
+	}{
+		{StateNew, StateActive, true},
+		{StateActive, StateHandoffReady, true},
+		{StateHandoffReady, StateMerged, true},
+		{StateHandoffReady, StateActive, true},
+		{StateActive, StateAbandoned, true},
+		{StateAbandoned, StateActive, true},
+		{StateNew, StateHandoffReady, false},
+		{StateAbandoned, StateHandoffReady, false},
+		{StateHandoffReady, StateHandoffReady, false},
+		{StateMerged, StateActive, false},
+		{StateMerged, StateAbandoned, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s->%s", tt.from, tt.to), func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransitionToRecordsHistory(t *testing.T) {
+	task := &Task{ID: "20250110-120000-abc123", State: StateNew}
+
+	if err := task.TransitionTo(StateActive, "claude", "task start"); err != nil {
+		t.Fatalf("TransitionTo(ACTIVE) failed: %v", err)
+	}
+	if err := task.TransitionTo(StateHandoffReady, "claude", "task handoff"); err != nil {
+		t.Fatalf("TransitionTo(HANDOFF_READY) failed: %v", err)
+	}
+
+	if task.State != StateHandoffReady {
+		t.Errorf("state = %s, want %s", task.State, StateHandoffReady)
+	}
+	if len(task.History) != 2 {
+		t.Fatalf("expected 2 history entries, got %d", len(task.History))
+	}
+	last := task.History[1]
+	if last.From != StateActive || last.To != StateHandoffReady || last.Actor != "claude" || last.Command != "task handoff" {
+		t.Errorf("unexpected history entry: %+v", last)
+	}
+
+	// Illegal transition must fail and leave the task untouched
+	if err := task.TransitionTo(StateNew, "claude", "test"); err == nil {
+		t.Error("expected error for HANDOFF_READY -> NEW")
+	}
+	if task.State != StateHandoffReady || len(task.History) != 2 {
+		t.Error("failed transition modified the task")
+	}
+}
+
+func TestSaveRejectsIllegalTransition(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewTaskStore(tempDir)
+
+	task := &Task{
+		ID:        "20250110-120000-abc123",
+		Agent:     "claude",
+		Title:     "Test task",
+		Branch:    "awt/claude/20250110-120000-abc123",
+		Base:      "main",
+		CreatedAt: time.Now(),
+		State:     StateMerged,
+	}
+	if err := store.Save(task); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+
+	// Bypass TransitionTo: the store must still refuse MERGED -> ACTIVE
+	task.State = StateActive
+	if err := store.Save(task); err == nil {
+		t.Error("expected error saving MERGED -> ACTIVE")
+	}
+
+	loaded, err := store.Load(task.ID)
+	if err != nil {
+		t.Fatalf("failed to load task: %v", err)
+	}
+	if loaded.State != StateMerged {
+		t.Errorf("stored state = %s, want %s", loaded.State, StateMerged)
+	}
 }
@@ -0,0 +1,89 @@
+package task
+
+import (
+	"time"
+
+	"github.com/kernel-labs-ai/awt/internal/errors"
+)
+
+// Transition records a single state change in a task's history
+type Transition struct {
+	// From is the state before the transition
+	From State `json:"from"`
+
+	// To is the state after the transition
+	To State `json:"to"`
+
+	// At is when the transition happened
+	At time.Time `json:"at"`
+
+	// Actor is who performed the transition (user or agent name)
+	Actor string `json:"actor,omitempty"`
+
+	// Command is the AWT command that performed the transition (e.g. "task handoff")
+	Command string `json:"command,omitempty"`
+
+	// Reason is an optional explanation (e.g. why a task was abandoned)
+	Reason string `json:"reason,omitempty"`
+}
+
+// transitions is the table of legal state changes.
+//
+//	NEW → ACTIVE → HANDOFF_READY → MERGED
+//	  ↓      ↓           ↓
+//	  └──→ ABANDONED ←───┘
+//
+// HANDOFF_READY may return to ACTIVE (rework after review), and ABANDONED may be
+// reopened. MERGED is terminal.
+var transitions = map[State][]State{
+	StateNew:          {StateActive, StateAbandoned},
+	StateActive:       {StateHandoffReady, StateMerged, StateAbandoned},
+	StateHandoffReady: {StateActive, StateMerged, StateAbandoned},
+	StateMerged:       {},
+	StateAbandoned:    {StateActive},
+}
+
+// CanTransition reports whether a task may move from one state to another
+func CanTransition(from, to State) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo moves the task to a new state and appends the change to its history.
+// Returns an INVALID_TRANSITION error if the move is not allowed by the transition table.
+func (t *Task) TransitionTo(to State, actor, command string) error {
+	return t.TransitionWithReason(to, actor, command, "")
+}
+
+// TransitionWithReason is like TransitionTo but also records why the transition happened
+func (t *Task) TransitionWithReason(to State, actor, command, reason string) error {
+	if !CanTransition(t.State, to) {
+		return errors.InvalidTransition(t.ID, string(t.State), string(to))
+	}
+
+	t.History = append(t.History, Transition{
+		From:    t.State,
+		To:      to,
+		At:      time.Now(),
+		Actor:   actor,
+		Command: command,
+		Reason:  reason,
+	})
+	t.State = to
+
+	return nil
+}
+
+// RequireState returns an INVALID_TASK_STATE error unless the task is in one of the given states
+func (t *Task) RequireState(operation string, states ...State) error {
+	for _, s := range states {
+		if t.State == s {
+			return nil
+		}
+	}
+	return errors.InvalidTaskState(t.ID, string(t.State), operation)
+}
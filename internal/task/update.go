@@ -0,0 +1,48 @@
+package task
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"github.com/kernel-labs-ai/awt/internal/errors"
+	"github.com/kernel-labs-ai/awt/internal/lock"
+)
+
+// updateRetries is how many times Update reloads and reapplies a change that conflicted
+const updateRetries = 5
+
+// Update loads a task, applies fn to it and saves it. If the save conflicts with a
+// concurrent write, the task is reloaded and fn applied again, so fn must only derive
+// its changes from the task it is given. An error from fn aborts the update.
+// taskLock must be the held lock of the task, so conflicts only come from writers
+// that do not take it.
+func Update(store Store, taskLock *lock.Lock, taskID string, fn func(*Task) error) (*Task, error) {
+	if !taskLock.Held() || taskLock.Name() != taskID {
+		return nil, fmt.Errorf("cannot update task %s without holding its lock", taskID)
+	}
+
+	var err error
+	for attempt := 0; attempt < updateRetries; attempt++ {
+		var t *Task
+		t, err = store.Load(taskID)
+		if err != nil {
+			return nil, err
+		}
+		if err := fn(t); err != nil {
+			return nil, err
+		}
+		if err = store.Save(t); err == nil {
+			return t, nil
+		}
+		if !IsConflict(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// IsConflict reports whether err is a TASK_CONFLICT error from Save
+func IsConflict(err error) bool {
+	var awtErr *errors.AWTError
+	return stderrors.As(err, &awtErr) && awtErr.Code == errors.ExitTaskConflict
+}
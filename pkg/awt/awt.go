@@ -0,0 +1,319 @@
+// Package awt embeds AWT in Go programs. A Client runs the same operations as the
+// awt command line (the commands are thin wrappers over it) and returns their
+// results as structs instead of printing them. Failures are returned as *Error
+// values carrying the exit code the CLI would use.
+package awt
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kernel-labs-ai/awt/internal/commands"
+	"github.com/kernel-labs-ai/awt/internal/errors"
+)
+
+// Option and result types shared with the awt commands. The OutputJSON option fields
+// only affect the command line and are ignored by the Client.
+type (
+	StartOptions       = commands.StartOptions
+	StartResult        = commands.StartResult
+	GroupStartResult   = commands.GroupStartResult
+	StatusOptions      = commands.StatusOptions
+	StatusResult       = commands.StatusResult
+	CommitOptions      = commands.CommitOptions
+	CommitResult       = commands.CommitResult
+	SyncOptions        = commands.SyncOptions
+	SyncResult         = commands.SyncResult
+	HandoffOptions     = commands.HandoffOptions
+	HandoffResult      = commands.HandoffResult
+	PROptions          = commands.PROptions
+	PRResult           = commands.PRResult
+	FeedbackOptions    = commands.FeedbackOptions
+	FeedbackResult     = commands.FeedbackResult
+	ListOptions        = commands.ListOptions
+	TaskListItem       = commands.TaskListItem
+	AbandonOptions     = commands.AbandonOptions
+	AbandonResult      = commands.AbandonResult
+	ReopenOptions      = commands.ReopenOptions
+	ReopenResult       = commands.ReopenResult
+	AdoptOptions       = commands.AdoptOptions
+	AdoptResult        = commands.AdoptResult
+	CheckoutOptions    = commands.CheckoutOptions
+	CheckoutResult     = commands.CheckoutResult
+	UnlockOptions      = commands.UnlockOptions
+	UnlockResult       = commands.UnlockResult
+	ReconcileOptions   = commands.ReconcileOptions
+	ReconcileResult    = commands.ReconcileResult
+	PruneOptions       = commands.PruneOptions
+	PruneResult        = commands.PruneResult
+	TaskLogOptions     = commands.TaskLogOptions
+	TaskLogResult      = commands.TaskLogResult
+	CheckpointOptions  = commands.CheckpointOptions
+	Checkpoint         = commands.Checkpoint
+	CheckpointsOptions = commands.CheckpointsOptions
+	CheckpointsResult  = commands.CheckpointsResult
+	RestoreOptions     = commands.RestoreOptions
+	RestoreResult      = commands.RestoreResult
+	UndoOptions        = commands.UndoOptions
+	UndoResult         = commands.UndoResult
+	OperationListItem  = commands.OperationListItem
+	GroupStatusOptions = commands.GroupStatusOptions
+	GroupStatusResult  = commands.GroupStatusResult
+	GroupPickOptions   = commands.GroupPickOptions
+	GroupPickResult    = commands.GroupPickResult
+	CompareOptions     = commands.CompareOptions
+	CompareResult      = commands.CompareResult
+	CompareAttempt     = commands.CompareAttempt
+	CompareFile        = commands.CompareFile
+	CompareFileChange  = commands.CompareFileChange
+	CompareRangeDiff   = commands.CompareRangeDiff
+	ExecOptions        = commands.ExecOptions
+	ExecResult         = commands.ExecResult
+)
+
+// Test statuses reported in CompareAttempt.TestStatus
+const (
+	TestStatusPass    = commands.TestStatusPass
+	TestStatusFail    = commands.TestStatusFail
+	TestStatusSkipped = commands.TestStatusSkipped
+)
+
+// Error is the typed error returned by Client operations
+type Error = errors.AWTError
+
+// ExitCode identifies the kind of an Error; it is the awt process exit code
+type ExitCode = errors.ExitCode
+
+// Exit codes, see USAGE.md for their meaning
+const (
+	ExitSuccess                   = errors.ExitSuccess
+	ExitGeneric                   = errors.ExitGeneric
+	ExitUsage                     = errors.ExitUsage
+	ExitRepoNotFound              = errors.ExitRepoNotFound
+	ExitGitTooOld                 = errors.ExitGitTooOld
+	ExitBranchExists              = errors.ExitBranchExists
+	ExitBranchCheckedOutElsewhere = errors.ExitBranchCheckedOutElsewhere
+	ExitWorktreeExists            = errors.ExitWorktreeExists
+	ExitWorktreeNotFound          = errors.ExitWorktreeNotFound
+	ExitDetachFailed              = errors.ExitDetachFailed
+	ExitRemoveFailed              = errors.ExitRemoveFailed
+	ExitWorktreeAddFailed         = errors.ExitWorktreeAddFailed
+	ExitSubmoduleFailed           = errors.ExitSubmoduleFailed
+	ExitSyncConflicts             = errors.ExitSyncConflicts
+	ExitPushRejected              = errors.ExitPushRejected
+	ExitSyncFailed                = errors.ExitSyncFailed
+	ExitGitTimeout                = errors.ExitGitTimeout
+	ExitLockTimeout               = errors.ExitLockTimeout
+	ExitLockHeld                  = errors.ExitLockHeld
+	ExitToolMissing               = errors.ExitToolMissing
+	ExitInvalidTaskID             = errors.ExitInvalidTaskID
+	ExitCaseOnlyCollision         = errors.ExitCaseOnlyCollision
+	ExitInvalidTransition         = errors.ExitInvalidTransition
+	ExitInvalidTaskState          = errors.ExitInvalidTaskState
+	ExitTaskConflict              = errors.ExitTaskConflict
+	ExitUndoRefused               = errors.ExitUndoRefused
+	ExitNothingToCommit           = errors.ExitNothingToCommit
+	ExitCommitFailed              = errors.ExitCommitFailed
+	ExitInvalidCommitMessage      = errors.ExitInvalidCommitMessage
+)
+
+// ExitCodeOf returns the code of the first *Error in err's chain, ExitGeneric for
+// other errors and ExitSuccess for nil
+func ExitCodeOf(err error) ExitCode {
+	return errors.ExitCodeOf(err)
+}
+
+// Client runs AWT operations against a repository
+type Client struct {
+	// RepoPath is used by operations whose options leave RepoPath empty
+	// (empty means the repository containing the current directory)
+	RepoPath string
+	// Progress receives the human-readable progress messages the CLI prints
+	// (nil discards them)
+	Progress io.Writer
+}
+
+// New creates a client for the repository at repoPath
+func New(repoPath string) *Client {
+	return &Client{RepoPath: repoPath}
+}
+
+func (c *Client) engine() *commands.Client {
+	return &commands.Client{Progress: c.Progress}
+}
+
+func (c *Client) repoPath(path string) string {
+	if path == "" {
+		return c.RepoPath
+	}
+	return path
+}
+
+// StartTask creates a task for opts.Agent: its branch, worktree and metadata
+func (c *Client) StartTask(ctx context.Context, opts StartOptions) (*StartResult, error) {
+	if len(opts.Agents) > 0 {
+		return nil, fmt.Errorf("StartTask starts a single task, use StartGroup for several agents")
+	}
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	result, err := c.engine().StartTask(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+	return &result.Tasks[0], nil
+}
+
+// StartGroup creates one task per agent in opts.Agents under a shared group ID
+func (c *Client) StartGroup(ctx context.Context, opts StartOptions) (*GroupStartResult, error) {
+	if len(opts.Agents) == 0 {
+		return nil, fmt.Errorf("StartGroup requires at least one agent in Agents")
+	}
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().StartTask(ctx, &opts)
+}
+
+// Status returns a task's metadata and state history
+func (c *Client) Status(ctx context.Context, opts StatusOptions) (*StatusResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Status(ctx, &opts)
+}
+
+// Commit commits changes in a task's worktree
+func (c *Client) Commit(ctx context.Context, opts CommitOptions) (*CommitResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Commit(ctx, &opts)
+}
+
+// Sync rebases or merges a task's branch with its base branch
+func (c *Client) Sync(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Sync(ctx, &opts)
+}
+
+// Handoff pushes a task's branch, optionally opens a PR, removes its worktree and
+// moves it to HANDOFF_READY
+func (c *Client) Handoff(ctx context.Context, opts HandoffOptions) (*HandoffResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Handoff(ctx, &opts)
+}
+
+// PR queries the forge for the status of a task's pull request and saves it on the task
+func (c *Client) PR(ctx context.Context, opts PROptions) (*PRResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().PR(ctx, &opts)
+}
+
+// Feedback writes the review feedback of a task's pull request into its worktree,
+// moving a handed-off task back to ACTIVE
+func (c *Client) Feedback(ctx context.Context, opts FeedbackOptions) (*FeedbackResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Feedback(ctx, &opts)
+}
+
+// List returns the tasks matching the filters in opts
+func (c *Client) List(ctx context.Context, opts ListOptions) ([]TaskListItem, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().List(ctx, &opts)
+}
+
+// Abandon removes a task's worktree, optionally deletes its branches, and moves it
+// to ABANDONED
+func (c *Client) Abandon(ctx context.Context, opts AbandonOptions) (*AbandonResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Abandon(ctx, &opts)
+}
+
+// Reopen recreates an abandoned or handed-off task's worktree and makes it ACTIVE again
+func (c *Client) Reopen(ctx context.Context, opts ReopenOptions) (*ReopenResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Reopen(ctx, &opts)
+}
+
+// Adopt creates a task for an existing branch
+func (c *Client) Adopt(ctx context.Context, opts AdoptOptions) (*AdoptResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Adopt(ctx, &opts)
+}
+
+// Checkout creates a worktree for an existing task's branch
+func (c *Client) Checkout(ctx context.Context, opts CheckoutOptions) (*CheckoutResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Checkout(ctx, &opts)
+}
+
+// Unlock detaches a task's branch from the worktrees that have it checked out
+func (c *Client) Unlock(ctx context.Context, opts UnlockOptions) (*UnlockResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Unlock(ctx, &opts)
+}
+
+// Reconcile marks tasks whose branches were merged as MERGED
+func (c *Client) Reconcile(ctx context.Context, opts ReconcileOptions) (*ReconcileResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Reconcile(ctx, &opts)
+}
+
+// Prune removes stale worktrees, orphaned task metadata and stale locks
+func (c *Client) Prune(ctx context.Context, opts PruneOptions) (*PruneResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Prune(ctx, &opts)
+}
+
+// Log returns a task's recorded events, oldest first
+func (c *Client) Log(ctx context.Context, opts TaskLogOptions) (*TaskLogResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Log(ctx, &opts)
+}
+
+// Checkpoint snapshots a task's worktree without touching HEAD or the index
+func (c *Client) Checkpoint(ctx context.Context, opts CheckpointOptions) (*Checkpoint, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Checkpoint(ctx, &opts)
+}
+
+// Checkpoints lists a task's checkpoints
+func (c *Client) Checkpoints(ctx context.Context, opts CheckpointsOptions) (*CheckpointsResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Checkpoints(ctx, &opts)
+}
+
+// Restore resets a task's worktree to a checkpoint
+func (c *Client) Restore(ctx context.Context, opts RestoreOptions) (*RestoreResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Restore(ctx, &opts)
+}
+
+// Undo reverts a recorded operation, the most recent undoable one unless opts.OpID is set
+func (c *Client) Undo(ctx context.Context, opts UndoOptions) (*UndoResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Undo(ctx, &opts)
+}
+
+// Operations returns the recorded operations, newest first
+func (c *Client) Operations(ctx context.Context) ([]OperationListItem, error) {
+	return c.engine().Operations(ctx, c.RepoPath)
+}
+
+// GroupStatus compares the attempts of a group started with StartGroup
+func (c *Client) GroupStatus(ctx context.Context, opts GroupStatusOptions) (*GroupStatusResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().GroupStatus(ctx, &opts)
+}
+
+// GroupPick hands off the winning task of a group and abandons the others
+func (c *Client) GroupPick(ctx context.Context, opts GroupPickOptions) (*GroupPickResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().GroupPick(ctx, &opts)
+}
+
+// Compare compares the branches of several tasks against their shared merge-base
+func (c *Client) Compare(ctx context.Context, opts CompareOptions) (*CompareResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Compare(ctx, &opts)
+}
+
+// Exec runs opts.Command in a task's worktree; its exit code is returned in the result
+func (c *Client) Exec(ctx context.Context, opts ExecOptions) (*ExecResult, error) {
+	opts.RepoPath = c.repoPath(opts.RepoPath)
+	return c.engine().Exec(ctx, &opts)
+}
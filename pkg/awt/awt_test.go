@@ -0,0 +1,150 @@
+package awt
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func setupTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test Repo\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	_ = exec.Command("git", "-C", dir, "add", "README.md").Run()
+	_ = exec.Command("git", "-C", dir, "commit", "-m", "Initial commit").Run()
+	return dir
+}
+
+func TestClientStartCommitList(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+	ctx := context.Background()
+	client := New(repoPath)
+
+	started, err := client.StartTask(ctx, StartOptions{
+		Agent:        "lib-agent",
+		Title:        "Library task",
+		Base:         "HEAD",
+		ID:           "lib-task",
+		NoFetch:      true,
+		BranchPrefix: "awt",
+	})
+	if err != nil {
+		t.Fatalf("StartTask() failed: %v", err)
+	}
+	if started.ID != "lib-task" || started.WorktreePath == "" {
+		t.Fatalf("unexpected start result: %+v", started)
+	}
+
+	if err := os.WriteFile(filepath.Join(started.WorktreePath, "lib.txt"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	committed, err := client.Commit(ctx, CommitOptions{TaskID: "lib-task", Message: "Add lib.txt", All: true})
+	if err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	if committed.CommitSHA == "" {
+		t.Error("expected a commit SHA")
+	}
+
+	tasks, err := client.List(ctx, ListOptions{})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "lib-task" || tasks[0].State != "ACTIVE" {
+		t.Errorf("unexpected tasks: %+v", tasks)
+	}
+}
+
+func TestClientTypedErrors(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	ctx := context.Background()
+	client := New(repoPath)
+
+	_, err := client.Status(ctx, StatusOptions{TaskID: "missing"})
+	if code := ExitCodeOf(err); code != ExitInvalidTaskID {
+		t.Errorf("Status() of a missing task: exit code %d (%v), want %d", code, err, ExitInvalidTaskID)
+	}
+
+	_, err = New(t.TempDir()).List(ctx, ListOptions{})
+	if code := ExitCodeOf(err); code != ExitRepoNotFound {
+		t.Errorf("List() outside a repository: exit code %d (%v), want %d", code, err, ExitRepoNotFound)
+	}
+}
+
+func TestClientCompareAndExec(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	t.Setenv("AWT_WORKTREE_DIR", t.TempDir())
+	ctx := context.Background()
+	client := New(repoPath)
+
+	for _, id := range []string{"attempt-a", "attempt-b"} {
+		started, err := client.StartTask(ctx, StartOptions{
+			Agent:        "lib-agent",
+			Title:        "Attempt " + id,
+			Base:         "HEAD",
+			ID:           id,
+			NoFetch:      true,
+			BranchPrefix: "awt",
+		})
+		if err != nil {
+			t.Fatalf("StartTask() failed: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(started.WorktreePath, id+".txt"), []byte(id+"\n"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := client.Commit(ctx, CommitOptions{TaskID: id, Message: "Add " + id, All: true}); err != nil {
+			t.Fatalf("Commit() failed: %v", err)
+		}
+	}
+
+	compared, err := client.Compare(ctx, CompareOptions{
+		TaskIDs:     []string{"attempt-a", "attempt-b"},
+		TestCommand: "test -f attempt-a.txt",
+		NoRangeDiff: true,
+	})
+	if err != nil {
+		t.Fatalf("Compare() failed: %v", err)
+	}
+	if len(compared.Attempts) != 2 || len(compared.Files) != 2 {
+		t.Fatalf("unexpected comparison: %+v", compared)
+	}
+	if compared.Attempts[0].TestStatus != TestStatusPass || compared.Attempts[1].TestStatus != TestStatusFail {
+		t.Errorf("test statuses = %s, %s, want pass, fail", compared.Attempts[0].TestStatus, compared.Attempts[1].TestStatus)
+	}
+
+	var stdout bytes.Buffer
+	executed, err := client.Exec(ctx, ExecOptions{
+		TaskID:  "attempt-a",
+		Command: []string{"sh", "-c", "cat attempt-a.txt; exit 3"},
+		Stdout:  &stdout,
+	})
+	if err != nil {
+		t.Fatalf("Exec() failed: %v", err)
+	}
+	if executed.ExitCode != 3 {
+		t.Errorf("exit code = %d, want 3", executed.ExitCode)
+	}
+	if stdout.String() != "attempt-a\n" {
+		t.Errorf("stdout = %q, want %q", stdout.String(), "attempt-a\n")
+	}
+
+	_, err = client.Compare(ctx, CompareOptions{TaskIDs: []string{"attempt-a", "missing"}})
+	if code := ExitCodeOf(err); code != ExitInvalidTaskID {
+		t.Errorf("Compare() with a missing task: exit code %d (%v), want %d", code, err, ExitInvalidTaskID)
+	}
+}